@@ -0,0 +1,183 @@
+package agentskills
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+// ActivateSkills activates skills in a session on behalf of the host, like the skills-load tool does for
+// the LLM. Mode replace (default) replaces the active set except pinned skills; mode add appends to it
+// (re-adding an active skill moves it to the end). It returns the session's active skills in activation order.
+//
+// Skills that conflict with each other or with a remaining active skill fail with a *spec.SkillConflictError
+// (which names skill handles); mode replace-conflicting adds like mode add but unloads the conflicting
+// skills (and their implicit dependencies) instead, unless they are pinned or a remaining skill requires them.
+//
+// Concurrent changes to the same session (host or LLM) are retried internally; if the session keeps
+// changing, a retryable spec.ErrInvalidArgument is returned.
+//
+// IMPORTANT CONTRACT:
+//   - This is a HOST/LIFECYCLE API; it accepts and returns only user-provided skill definitions.
+func (r *Runtime) ActivateSkills(
+	ctx context.Context,
+	sid spec.SessionID,
+	defs []spec.SkillDef,
+	mode spec.LoadMode,
+) ([]spec.SkillDef, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("%w: nil context", spec.ErrInvalidArgument)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, fmt.Errorf("%w: nil runtime receiver", spec.ErrInvalidArgument)
+	}
+	if len(defs) == 0 {
+		return nil, fmt.Errorf("%w: defs is required", spec.ErrInvalidArgument)
+	}
+
+	keys, err := r.resolveSessionDefs(defs)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := r.sessions.Get(string(sid))
+	if !ok {
+		return nil, spec.ErrSessionNotFound
+	}
+	handles, err := s.ActivateKeys(ctx, keys, mode)
+	if err != nil {
+		return nil, err
+	}
+	return r.defsForHandles(handles), nil
+}
+
+// PinSkills activates skills like ActivateSkills and pins them. Pinned skills stay active for the rest of
+// the session: the LLM cannot remove them with skills-unload (even with all=true) or skills-load with
+// mode=replace. Only DeactivateSkills removes pinned skills.
+//
+// Pinned skills count against the session's max active skills limit like any other active skill.
+//
+// IMPORTANT CONTRACT:
+//   - This is a HOST/LIFECYCLE API; it accepts and returns only user-provided skill definitions.
+func (r *Runtime) PinSkills(
+	ctx context.Context,
+	sid spec.SessionID,
+	defs []spec.SkillDef,
+	mode spec.LoadMode,
+) ([]spec.SkillDef, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("%w: nil context", spec.ErrInvalidArgument)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, fmt.Errorf("%w: nil runtime receiver", spec.ErrInvalidArgument)
+	}
+	if len(defs) == 0 {
+		return nil, fmt.Errorf("%w: defs is required", spec.ErrInvalidArgument)
+	}
+
+	keys, err := r.resolveSessionDefs(defs)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := r.sessions.Get(string(sid))
+	if !ok {
+		return nil, spec.ErrSessionNotFound
+	}
+	handles, err := s.PinKeys(ctx, keys, mode)
+	if err != nil {
+		return nil, err
+	}
+	return r.defsForHandles(handles), nil
+}
+
+// DeactivateSkills deactivates skills in a session on behalf of the host, like the skills-unload tool does
+// for the LLM, except that pinned skills are deactivated too. If all is true, defs is ignored and every skill
+// is deactivated. Defs that are registered but not active are ignored. It returns the session's remaining
+// active skills in activation order.
+//
+// IMPORTANT CONTRACT:
+//   - This is a HOST/LIFECYCLE API; it accepts and returns only user-provided skill definitions.
+func (r *Runtime) DeactivateSkills(
+	ctx context.Context,
+	sid spec.SessionID,
+	defs []spec.SkillDef,
+	all bool,
+) ([]spec.SkillDef, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("%w: nil context", spec.ErrInvalidArgument)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, fmt.Errorf("%w: nil runtime receiver", spec.ErrInvalidArgument)
+	}
+	if !all && len(defs) == 0 {
+		return nil, fmt.Errorf("%w: defs is required unless all=true", spec.ErrInvalidArgument)
+	}
+
+	var keys []spec.ProviderSkillKey
+	if !all {
+		var err error
+		if keys, err = r.resolveSessionDefs(defs); err != nil {
+			return nil, err
+		}
+	}
+	s, ok := r.sessions.Get(string(sid))
+	if !ok {
+		return nil, spec.ErrSessionNotFound
+	}
+	handles, err := s.DeactivateKeys(ctx, keys, all)
+	if err != nil {
+		return nil, err
+	}
+	return r.defsForHandles(handles), nil
+}
+
+// resolveSessionDefs maps host defs to internal keys (in order, deduped).
+func (r *Runtime) resolveSessionDefs(defs []spec.SkillDef) ([]spec.ProviderSkillKey, error) {
+	keys := make([]spec.ProviderSkillKey, 0, len(defs))
+	seen := map[spec.ProviderSkillKey]struct{}{}
+	for _, d := range defs {
+		if strings.TrimSpace(d.Type) != d.Type ||
+			strings.TrimSpace(d.Name) != d.Name ||
+			strings.TrimSpace(d.Location) != d.Location {
+			return nil, fmt.Errorf(
+				"%w: def fields must not contain leading/trailing whitespace",
+				spec.ErrInvalidArgument,
+			)
+		}
+		k, ok := r.catalog.ResolveDef(d)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown skill def: %+v", spec.ErrSkillNotFound, d)
+		}
+		if _, dup := seen[k]; dup {
+			continue
+		}
+		seen[k] = struct{}{}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// defsForHandles maps session handles back to host defs; skills removed concurrently are dropped.
+func (r *Runtime) defsForHandles(handles []spec.SkillHandle) []spec.SkillDef {
+	out := make([]spec.SkillDef, 0, len(handles))
+	for _, h := range handles {
+		k, ok := r.catalog.ResolveHandle(h)
+		if !ok {
+			continue
+		}
+		if d, ok := r.catalog.DefForKey(k); ok {
+			out = append(out, d)
+		}
+	}
+	return out
+}
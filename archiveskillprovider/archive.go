@@ -0,0 +1,253 @@
+package archiveskillprovider
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/flexigpt/agentskills-go/internal/providerutil"
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+type archiveFormat int
+
+const (
+	formatZip archiveFormat = iota
+	formatTar
+	formatTarGz
+)
+
+type entryKind int
+
+const (
+	entryFile entryKind = iota
+	entryDir
+	// entryOther covers symlinks, hard links and special files. They are never read.
+	entryOther
+)
+
+type archiveEntry struct {
+	name string
+	kind entryKind
+}
+
+// readEntryFunc reads the current entry, failing if it is larger than limit bytes.
+type readEntryFunc func(limit int) ([]byte, error)
+
+// visitFunc is called for each archive entry. Returning stop=true ends the scan early.
+type visitFunc func(e archiveEntry, read readEntryFunc) (stop bool, err error)
+
+func detectFormat(name string) (archiveFormat, error) {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return formatZip, nil
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return formatTarGz, nil
+	case strings.HasSuffix(lower, ".tar"):
+		return formatTar, nil
+	default:
+		return 0, fmt.Errorf(
+			"%w: unsupported archive extension (want .zip, .tar, .tar.gz or .tgz): %q",
+			spec.ErrInvalidArgument,
+			name,
+		)
+	}
+}
+
+// archiveDigest hashes the full archive file, so any entry change yields a new digest.
+func archiveDigest(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	digest, err := providerutil.DigestReader(f)
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", file, err)
+	}
+	return digest, nil
+}
+
+// scanArchive streams the archive entries to visit.
+//
+// Every entry name is validated (zip-slip protection) even when the visitor is not interested in it,
+// and the declared uncompressed sizes and entry count are capped before any content is read.
+func (p *Provider) scanArchive(ctx context.Context, file string, visit visitFunc) error {
+	format, err := detectFormat(file)
+	if err != nil {
+		return err
+	}
+	if format == formatZip {
+		return p.scanZip(ctx, file, visit)
+	}
+	return p.scanTar(ctx, file, format == formatTarGz, visit)
+}
+
+func (p *Provider) scanZip(ctx context.Context, file string, visit visitFunc) error {
+	zr, err := zip.OpenReader(file)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return fmt.Errorf("%w: open zip %s: %w", spec.ErrInvalidArgument, file, err)
+	}
+	defer zr.Close()
+
+	if len(zr.File) > p.maxEntries {
+		return fmt.Errorf("%w: archive has more than %d entries", spec.ErrInvalidArgument, p.maxEntries)
+	}
+	var total uint64
+	for _, f := range zr.File {
+		total += f.UncompressedSize64
+		if total > uint64(p.maxUncompressedBytes) {
+			return fmt.Errorf(
+				"%w: archive expands beyond %d bytes",
+				spec.ErrInvalidArgument,
+				p.maxUncompressedBytes,
+			)
+		}
+	}
+
+	for _, f := range zr.File {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		e, err := newArchiveEntry(f.Name, zipEntryKind(f.Mode()))
+		if err != nil {
+			return err
+		}
+		stop, err := visit(e, func(limit int) ([]byte, error) {
+			if f.UncompressedSize64 > uint64(limit) {
+				return nil, fmt.Errorf("%s too large (max %d bytes)", e.name, limit)
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return nil, fmt.Errorf("open %s: %w", e.name, err)
+			}
+			defer rc.Close()
+			return readLimited(rc, e.name, limit)
+		})
+		if err != nil || stop {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Provider) scanTar(ctx context.Context, file string, gzipped bool, visit visitFunc) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if gzipped {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("%w: open gzip %s: %w", spec.ErrInvalidArgument, file, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	tr := tar.NewReader(r)
+	var (
+		entries int
+		total   int64
+	)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: read tar %s: %w", spec.ErrInvalidArgument, file, err)
+		}
+		entries++
+		if entries > p.maxEntries {
+			return fmt.Errorf("%w: archive has more than %d entries", spec.ErrInvalidArgument, p.maxEntries)
+		}
+		total += max(hdr.Size, 0)
+		if total > p.maxUncompressedBytes {
+			return fmt.Errorf(
+				"%w: archive expands beyond %d bytes",
+				spec.ErrInvalidArgument,
+				p.maxUncompressedBytes,
+			)
+		}
+
+		kind := entryOther
+		switch hdr.Typeflag {
+		case tar.TypeReg:
+			kind = entryFile
+		case tar.TypeDir:
+			kind = entryDir
+		case tar.TypeXGlobalHeader:
+			continue
+		}
+		e, err := newArchiveEntry(hdr.Name, kind)
+		if err != nil {
+			return err
+		}
+		size := hdr.Size
+		stop, err := visit(e, func(limit int) ([]byte, error) {
+			if size > int64(limit) {
+				return nil, fmt.Errorf("%s too large (max %d bytes)", e.name, limit)
+			}
+			return readLimited(tr, e.name, limit)
+		})
+		if err != nil || stop {
+			return err
+		}
+	}
+}
+
+func newArchiveEntry(rawName string, kind entryKind) (archiveEntry, error) {
+	name := strings.TrimSuffix(rawName, "/")
+	for strings.HasPrefix(name, "./") {
+		name = strings.TrimPrefix(name, "./")
+	}
+	if name == "" || name == "." {
+		return archiveEntry{name: ".", kind: entryDir}, nil
+	}
+	clean, err := providerutil.CleanLocation(name)
+	if err != nil {
+		return archiveEntry{}, fmt.Errorf("%w: unsafe archive entry %q", spec.ErrInvalidArgument, rawName)
+	}
+	return archiveEntry{name: clean, kind: kind}, nil
+}
+
+func zipEntryKind(mode fs.FileMode) entryKind {
+	switch {
+	case mode.IsDir():
+		return entryDir
+	case mode.IsRegular():
+		return entryFile
+	default:
+		return entryOther
+	}
+}
+
+// readLimited reads at most limit bytes and fails if the entry holds more, regardless of its header.
+func readLimited(r io.Reader, name string, limit int) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", name, err)
+	}
+	if len(data) > limit {
+		return nil, fmt.Errorf("%s too large (max %d bytes)", name, limit)
+	}
+	return data, nil
+}
@@ -0,0 +1,382 @@
+package archiveskillprovider
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/flexigpt/agentskills-go"
+	llmtoolsgoSpec "github.com/flexigpt/llmtools-go/spec"
+
+	"github.com/flexigpt/agentskills-go/internal/providerutil"
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+const Type = "archive"
+
+const (
+	skillFileName   = agentskills.SkillDocumentFileName
+	maxSkillMDBytes = agentskills.MaxSkillDocumentBytes
+
+	// subPathSeparator separates the archive file from the optional skill directory inside it.
+	subPathSeparator = "#"
+
+	defaultMaxArchiveBytes      = 64 << 20
+	defaultMaxUncompressedBytes = 256 << 20
+	defaultMaxEntries           = 10000
+)
+
+// Provider serves skills straight from .zip, .tar, .tar.gz and .tgz bundles without extracting them.
+//
+// A skill location is "<archive-file>" or "<archive-file>#<sub-path>", where sub-path is the
+// slash-separated skill directory inside the archive. When a sub-path is given, its basename must
+// match the SKILL.md frontmatter name. Scripts are never executed.
+//
+// Safety:
+//   - entries with absolute names, backslashes or ".." elements make the whole archive invalid (zip-slip).
+//   - symlinks, hard links and special entries are never advertised or read.
+//   - archive size, declared uncompressed size, entry count and per-entry reads are capped.
+type Provider struct {
+	maxArchiveBytes      int64
+	maxUncompressedBytes int64
+	maxEntries           int
+}
+
+type Option func(*Provider) error
+
+// WithMaxArchiveBytes caps the size of the archive file itself. Default is 64 MiB.
+func WithMaxArchiveBytes(n int64) Option {
+	return func(p *Provider) error {
+		if n <= 0 {
+			return fmt.Errorf("%w: max archive bytes must be > 0", spec.ErrInvalidArgument)
+		}
+		p.maxArchiveBytes = n
+		return nil
+	}
+}
+
+// WithMaxUncompressedBytes caps the total declared uncompressed size of all entries. Default is 256 MiB.
+func WithMaxUncompressedBytes(n int64) Option {
+	return func(p *Provider) error {
+		if n <= 0 {
+			return fmt.Errorf("%w: max uncompressed bytes must be > 0", spec.ErrInvalidArgument)
+		}
+		p.maxUncompressedBytes = n
+		return nil
+	}
+}
+
+// WithMaxEntries caps the number of entries in an archive. Default is 10000.
+func WithMaxEntries(n int) Option {
+	return func(p *Provider) error {
+		if n <= 0 {
+			return fmt.Errorf("%w: max entries must be > 0", spec.ErrInvalidArgument)
+		}
+		p.maxEntries = n
+		return nil
+	}
+}
+
+func New(opts ...Option) (*Provider, error) {
+	p := &Provider{
+		maxArchiveBytes:      defaultMaxArchiveBytes,
+		maxUncompressedBytes: defaultMaxUncompressedBytes,
+		maxEntries:           defaultMaxEntries,
+	}
+	for _, o := range opts {
+		if o == nil {
+			continue
+		}
+		if err := o(p); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+func (p *Provider) Type() string { return Type }
+
+func (p *Provider) Index(ctx context.Context, def spec.SkillDef) (spec.ProviderSkillIndexRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return spec.ProviderSkillIndexRecord{}, err
+	}
+	if def.Type != Type {
+		return spec.ProviderSkillIndexRecord{}, fmt.Errorf(
+			"%w: wrong provider type: %q",
+			spec.ErrInvalidArgument,
+			def.Type,
+		)
+	}
+	if strings.TrimSpace(def.Name) == "" {
+		return spec.ProviderSkillIndexRecord{}, fmt.Errorf("%w: def.name is required", spec.ErrInvalidArgument)
+	}
+
+	loc, err := p.parseLocation(def.Location)
+	if err != nil {
+		return spec.ProviderSkillIndexRecord{}, err
+	}
+	digest, err := archiveDigest(loc.file)
+	if err != nil {
+		return spec.ProviderSkillIndexRecord{}, err
+	}
+
+	var (
+		raw       []byte
+		resources []string
+		warnings  []string
+	)
+	err = p.scanArchive(ctx, loc.file, func(e archiveEntry, read readEntryFunc) (bool, error) {
+		rel, ok := loc.relative(e.name)
+		if !ok || rel == "." {
+			return false, nil
+		}
+		switch {
+		case rel == skillFileName:
+			if e.kind != entryFile {
+				return false, fmt.Errorf("%w: %s must be a regular file", spec.ErrInvalidArgument, skillFileName)
+			}
+			data, err := read(maxSkillMDBytes)
+			if err != nil {
+				return false, err
+			}
+			raw = data
+		case e.kind == entryFile:
+			resources = append(resources, rel)
+		case e.kind == entryOther:
+			warnings = append(warnings, fmt.Sprintf("resource %q skipped: not a regular file", rel))
+		}
+		return false, nil
+	})
+	if err != nil {
+		return spec.ProviderSkillIndexRecord{}, err
+	}
+	if raw == nil {
+		return spec.ProviderSkillIndexRecord{}, fmt.Errorf(
+			"%s not found in archive %q: %w",
+			path.Join(loc.sub, skillFileName),
+			def.Location,
+			fs.ErrNotExist,
+		)
+	}
+
+	document, parseWarnings, err := agentskills.ParseSkillDocument(
+		raw,
+		spec.ParseSkillDocumentOptions{ExpectedName: loc.expectedName()},
+	)
+	if err != nil {
+		return spec.ProviderSkillIndexRecord{}, fmt.Errorf("index %s: %w", def.Location, err)
+	}
+	if document.Name != def.Name {
+		return spec.ProviderSkillIndexRecord{}, fmt.Errorf(
+			"%w: key.name=%q does not match SKILL.md frontmatter.name=%q",
+			spec.ErrInvalidArgument,
+			def.Name,
+			document.Name,
+		)
+	}
+
+	return spec.ProviderSkillIndexRecord{
+		Key:            spec.ProviderSkillKey{Type: def.Type, Name: def.Name, Location: loc.String()},
+		Name:           document.Name,
+		Description:    document.Description,
+		DisplayName:    document.DisplayName,
+		Insert:         document.Insert,
+		Version:        document.Version,
+		Arguments:      document.Arguments,
+		Tags:           document.Tags,
+		Requires:       document.Requires,
+		Conflicts:      document.Conflicts,
+		Resources:      providerutil.ResourceInfo(resources),
+		RawFrontmatter: document.RawFrontmatter,
+		Warnings:       append(parseWarnings, warnings...),
+		Digest:         digest,
+	}, nil
+}
+
+func (p *Provider) LoadBody(ctx context.Context, key spec.ProviderSkillKey) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if key.Type != Type {
+		return "", fmt.Errorf("%w: wrong provider type: %q", spec.ErrInvalidArgument, key.Type)
+	}
+	loc, err := p.parseLocation(key.Location)
+	if err != nil {
+		return "", err
+	}
+	raw, err := p.readEntry(ctx, loc, skillFileName, maxSkillMDBytes)
+	if err != nil {
+		return "", err
+	}
+	document, _, err := agentskills.ParseSkillDocument(
+		raw,
+		spec.ParseSkillDocumentOptions{ExpectedName: loc.expectedName()},
+	)
+	if err != nil {
+		return "", fmt.Errorf("load body %s: %w", key.Location, err)
+	}
+	return document.MarkdownBody, nil
+}
+
+func (p *Provider) ReadResource(
+	ctx context.Context,
+	key spec.ProviderSkillKey,
+	resourceLocation string,
+	encoding spec.ReadResourceEncoding,
+) ([]llmtoolsgoSpec.ToolOutputUnion, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if key.Type != Type {
+		return nil, fmt.Errorf("%w: wrong provider type: %q", spec.ErrInvalidArgument, key.Type)
+	}
+	enc, err := providerutil.NormalizeEncoding(encoding)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := providerutil.CleanLocation(resourceLocation)
+	if err != nil {
+		return nil, err
+	}
+	if rel == "." {
+		return nil, fmt.Errorf("%w: resource location must name a file", spec.ErrInvalidArgument)
+	}
+	loc, err := p.parseLocation(key.Location)
+	if err != nil {
+		return nil, err
+	}
+	data, err := p.readEntry(ctx, loc, rel, providerutil.MaxResourceBytes)
+	if err != nil {
+		return nil, err
+	}
+	return providerutil.ResourceOutput(rel, data, enc)
+}
+
+func (p *Provider) RunScript(
+	ctx context.Context,
+	key spec.ProviderSkillKey,
+	scriptLocation string,
+	args []string,
+	env map[string]string,
+	workdir string,
+) (spec.RunScriptOut, error) {
+	if err := ctx.Err(); err != nil {
+		return spec.RunScriptOut{}, err
+	}
+	if key.Type != Type {
+		return spec.RunScriptOut{}, fmt.Errorf("%w: wrong provider type: %q", spec.ErrInvalidArgument, key.Type)
+	}
+	return spec.RunScriptOut{}, spec.ErrRunScriptUnsupported
+}
+
+// readEntry reads the regular file rel (relative to the skill directory) from the archive.
+func (p *Provider) readEntry(ctx context.Context, loc archiveLocation, rel string, limit int) ([]byte, error) {
+	var (
+		data  []byte
+		found bool
+	)
+	err := p.scanArchive(ctx, loc.file, func(e archiveEntry, read readEntryFunc) (bool, error) {
+		if r, ok := loc.relative(e.name); !ok || r != rel {
+			return false, nil
+		}
+		if e.kind != entryFile {
+			return true, fmt.Errorf("%w: %q is not a regular file", spec.ErrInvalidArgument, rel)
+		}
+		var err error
+		data, err = read(limit)
+		found = err == nil
+		return true, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("%q not found in archive %q: %w", rel, loc.String(), fs.ErrNotExist)
+	}
+	return data, nil
+}
+
+type archiveLocation struct {
+	// file is the absolute archive file path.
+	file string
+	// sub is the clean skill directory inside the archive ("." for the archive root).
+	sub string
+}
+
+func (l archiveLocation) String() string {
+	if l.sub == "." {
+		return l.file
+	}
+	return l.file + subPathSeparator + l.sub
+}
+
+func (l archiveLocation) expectedName() string {
+	if l.sub == "." {
+		return ""
+	}
+	return path.Base(l.sub)
+}
+
+// relative maps an archive entry name to a location relative to the skill directory.
+func (l archiveLocation) relative(name string) (string, bool) {
+	if l.sub == "." {
+		return name, true
+	}
+	if name == l.sub {
+		return ".", true
+	}
+	rest, ok := strings.CutPrefix(name, l.sub+"/")
+	return rest, ok
+}
+
+// parseLocation splits "<archive-file>[#<sub-path>]" and validates the archive file.
+func (p *Provider) parseLocation(location string) (archiveLocation, error) {
+	raw := strings.TrimSpace(location)
+	if raw == "" {
+		return archiveLocation{}, fmt.Errorf("%w: empty location", spec.ErrInvalidArgument)
+	}
+	if strings.ContainsRune(raw, '\x00') {
+		return archiveLocation{}, fmt.Errorf("%w: location contains NUL byte", spec.ErrInvalidArgument)
+	}
+
+	file, sub := raw, "."
+	if i := strings.LastIndex(raw, subPathSeparator); i >= 0 {
+		file = raw[:i]
+		s, err := providerutil.CleanLocation(raw[i+1:])
+		if err != nil {
+			return archiveLocation{}, err
+		}
+		sub = s
+	}
+	if _, err := detectFormat(file); err != nil {
+		return archiveLocation{}, err
+	}
+
+	abs, err := filepath.Abs(filepath.Clean(file))
+	if err != nil {
+		return archiveLocation{}, fmt.Errorf("%w: invalid archive path %q: %w", spec.ErrInvalidArgument, file, err)
+	}
+	if resolved, rerr := filepath.EvalSymlinks(abs); rerr == nil && strings.TrimSpace(resolved) != "" {
+		abs = resolved
+	}
+	st, err := os.Stat(abs)
+	if err != nil {
+		return archiveLocation{}, fmt.Errorf("%w: archive %q: %w", spec.ErrInvalidArgument, file, err)
+	}
+	if !st.Mode().IsRegular() {
+		return archiveLocation{}, fmt.Errorf("%w: archive is not a regular file: %q", spec.ErrInvalidArgument, file)
+	}
+	if st.Size() > p.maxArchiveBytes {
+		return archiveLocation{}, fmt.Errorf(
+			"%w: archive %q too large (max %d bytes)",
+			spec.ErrInvalidArgument,
+			file,
+			p.maxArchiveBytes,
+		)
+	}
+	return archiveLocation{file: abs, sub: sub}, nil
+}
@@ -0,0 +1,267 @@
+package archiveskillprovider
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/flexigpt/agentskills-go"
+	llmtoolsgoSpec "github.com/flexigpt/llmtools-go/spec"
+
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+const (
+	helloSkillName = "hello-skill"
+	helloSkillDoc  = "---\nname: hello-skill\ndescription: bundled hello\n---\n\nSay hello.\n"
+)
+
+type testEntry struct {
+	name    string
+	data    string
+	symlink bool
+}
+
+func helloEntries(prefix, guide string) []testEntry {
+	return []testEntry{
+		{name: prefix + "SKILL.md", data: helloSkillDoc},
+		{name: prefix + "references/guide.md", data: guide},
+		{name: prefix + "link.md", data: "references/guide.md", symlink: true},
+		{name: "README.md", data: "outside the skill"},
+	}
+}
+
+func writeZip(t *testing.T, file string, entries []testEntry) {
+	t.Helper()
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for _, e := range entries {
+		hdr := &zip.FileHeader{Name: e.name, Method: zip.Deflate}
+		hdr.SetMode(0o644)
+		if e.symlink {
+			hdr.SetMode(fs.ModeSymlink | 0o777)
+		}
+		w, err := zw.CreateHeader(hdr)
+		if err != nil {
+			t.Fatalf("zip create: %v", err)
+		}
+		if _, err := w.Write([]byte(e.data)); err != nil {
+			t.Fatalf("zip write: %v", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip close: %v", err)
+	}
+}
+
+func writeTarGz(t *testing.T, file string, entries []testEntry) {
+	t.Helper()
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0o644, Size: int64(len(e.data)), Typeflag: tar.TypeReg}
+		if e.symlink {
+			hdr = &tar.Header{Name: e.name, Mode: 0o777, Linkname: e.data, Typeflag: tar.TypeSymlink}
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatalf("tar header: %v", err)
+		}
+		if !e.symlink {
+			if _, err := tw.Write([]byte(e.data)); err != nil {
+				t.Fatalf("tar write: %v", err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("tar close: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("gzip close: %v", err)
+	}
+}
+
+func mustNew(t *testing.T, opts ...Option) *Provider {
+	t.Helper()
+	p, err := New(opts...)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return p
+}
+
+func TestProvider_ZipWithSubPath(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	file := filepath.Join(t.TempDir(), "bundle.zip")
+	writeZip(t, file, helloEntries("skills/hello-skill/", "guide"))
+	p := mustNew(t)
+
+	rec, err := p.Index(ctx, spec.SkillDef{Type: Type, Name: helloSkillName, Location: file + "#skills/hello-skill"})
+	if err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+	if !strings.HasSuffix(rec.Key.Location, "bundle.zip#skills/hello-skill") {
+		t.Fatalf("unexpected canonical location: %q", rec.Key.Location)
+	}
+	if rec.Resources.TotalCount != 1 || rec.Resources.Locations[0] != "references/guide.md" {
+		t.Fatalf("expected only the regular resource (symlink and outside files excluded), got %+v", rec.Resources)
+	}
+	if len(rec.Warnings) != 1 || !strings.Contains(rec.Warnings[0], "link.md") {
+		t.Fatalf("expected symlink warning, got %v", rec.Warnings)
+	}
+
+	body, err := p.LoadBody(ctx, rec.Key)
+	if err != nil || strings.TrimSpace(body) != "Say hello." {
+		t.Fatalf("LoadBody = %q, %v", body, err)
+	}
+
+	out, err := p.ReadResource(ctx, rec.Key, "references/guide.md", "")
+	if err != nil || len(out) != 1 || out[0].TextItem == nil || out[0].TextItem.Text != "guide" {
+		t.Fatalf("ReadResource = %+v, %v", out, err)
+	}
+	out, err = p.ReadResource(ctx, rec.Key, "references/guide.md", spec.ReadResourceEncodingBinary)
+	if err != nil || len(out) != 1 || out[0].Kind != llmtoolsgoSpec.ToolOutputKindFile {
+		t.Fatalf("ReadResource binary = %+v, %v", out, err)
+	}
+	for _, loc := range []string{"link.md", "../../README.md", "missing.md", "/etc/passwd"} {
+		if _, err := p.ReadResource(ctx, rec.Key, loc, ""); err == nil {
+			t.Fatalf("expected error for resource %q", loc)
+		}
+	}
+	if _, err := p.RunScript(ctx, rec.Key, "x.sh", nil, nil, ""); !errors.Is(err, spec.ErrRunScriptUnsupported) {
+		t.Fatalf("expected ErrRunScriptUnsupported, got %v", err)
+	}
+}
+
+func TestProvider_TarGzAtRoot(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	file := filepath.Join(t.TempDir(), "hello-1.0.tgz")
+	writeTarGz(t, file, helloEntries("./", "guide"))
+	p := mustNew(t)
+
+	rec, err := p.Index(ctx, spec.SkillDef{Type: Type, Name: helloSkillName, Location: file})
+	if err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+	want := []string{"README.md", "references/guide.md"}
+	if strings.Join(rec.Resources.Locations, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected resources %v, got %+v", want, rec.Resources)
+	}
+	out, err := p.ReadResource(ctx, rec.Key, "README.md", "")
+	if err != nil || out[0].TextItem.Text != "outside the skill" {
+		t.Fatalf("ReadResource = %+v, %v", out, err)
+	}
+	if _, err := p.ReadResource(ctx, rec.Key, "link.md", ""); err == nil {
+		t.Fatalf("expected symlink entry to be unreadable")
+	}
+}
+
+func TestProvider_RejectsUnsafeArchives(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	dir := t.TempDir()
+
+	slip := filepath.Join(dir, "slip.zip")
+	writeZip(t, slip, append(helloEntries("", "guide"), testEntry{name: "../evil.sh", data: "x"}))
+	slipTar := filepath.Join(dir, "slip.tar.gz")
+	writeTarGz(t, slipTar, append(helloEntries("", "guide"), testEntry{name: "/etc/evil", data: "x"}))
+	ok := filepath.Join(dir, "ok.zip")
+	writeZip(t, ok, helloEntries("", strings.Repeat("x", 1024)))
+	noSkill := filepath.Join(dir, "noskill.zip")
+	writeZip(t, noSkill, []testEntry{{name: "README.md", data: "x"}})
+	unsupported := filepath.Join(dir, "bundle.rar")
+	if err := os.WriteFile(unsupported, []byte("x"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	def := func(loc string) spec.SkillDef { return spec.SkillDef{Type: Type, Name: helloSkillName, Location: loc} }
+	tests := []struct {
+		name   string
+		p      *Provider
+		def    spec.SkillDef
+		wantIs error
+	}{
+		{"zip slip", mustNew(t), def(slip), spec.ErrInvalidArgument},
+		{"tar absolute entry", mustNew(t), def(slipTar), spec.ErrInvalidArgument},
+		{"uncompressed bomb", mustNew(t, WithMaxUncompressedBytes(512)), def(ok), spec.ErrInvalidArgument},
+		{"entry count", mustNew(t, WithMaxEntries(2)), def(ok), spec.ErrInvalidArgument},
+		{"archive size", mustNew(t, WithMaxArchiveBytes(16)), def(ok), spec.ErrInvalidArgument},
+		{"sub-path traversal", mustNew(t), def(ok + "#../x"), spec.ErrInvalidArgument},
+		{"unsupported extension", mustNew(t), def(unsupported), spec.ErrInvalidArgument},
+		{"missing SKILL.md", mustNew(t), def(noSkill), fs.ErrNotExist},
+		{"missing sub-path", mustNew(t), def(ok + "#nope/hello-skill"), fs.ErrNotExist},
+		{"name mismatch", mustNew(t), spec.SkillDef{Type: Type, Name: "other", Location: ok}, spec.ErrInvalidArgument},
+		{"missing archive", mustNew(t), def(filepath.Join(dir, "missing.zip")), fs.ErrNotExist},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := tt.p.Index(ctx, tt.def)
+			if !errors.Is(err, tt.wantIs) {
+				t.Fatalf("expected errors.Is(err, %v), got %v", tt.wantIs, err)
+			}
+		})
+	}
+
+	for _, opt := range []Option{WithMaxArchiveBytes(0), WithMaxUncompressedBytes(-1), WithMaxEntries(0)} {
+		if _, err := New(opt); !errors.Is(err, spec.ErrInvalidArgument) {
+			t.Fatalf("expected invalid option error, got %v", err)
+		}
+	}
+}
+
+func TestProvider_DigestCoversArchiveAndRefreshes(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	file := filepath.Join(t.TempDir(), "bundle.zip")
+	writeZip(t, file, helloEntries("", "guide v1"))
+
+	rt, err := agentskills.New(agentskills.WithProvider(mustNew(t)))
+	if err != nil {
+		t.Fatalf("agentskills.New: %v", err)
+	}
+	def := spec.SkillDef{Type: Type, Name: helloSkillName, Location: file}
+	rec, err := rt.AddSkill(ctx, def)
+	if err != nil {
+		t.Fatalf("AddSkill: %v", err)
+	}
+	if _, _, err := rt.NewSession(ctx, agentskills.WithSessionActiveSkills([]spec.SkillDef{def})); err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+
+	res, err := rt.RefreshSkill(ctx, def)
+	if err != nil || res.Status != spec.SkillRefreshStatusUnchanged {
+		t.Fatalf("expected unchanged refresh, got %+v, %v", res, err)
+	}
+
+	// Only a resource changes; the digest must still change.
+	writeZip(t, file, helloEntries("", "guide v2"))
+	res, err = rt.RefreshSkill(ctx, def)
+	if err != nil {
+		t.Fatalf("RefreshSkill: %v", err)
+	}
+	if res.Status != spec.SkillRefreshStatusUpdated || res.PreviousDigest != rec.Digest ||
+		res.Record.Digest == rec.Digest {
+		t.Fatalf("expected updated refresh with new digest, got %+v", res)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/goccy/go-yaml"
 
 	"github.com/flexigpt/agentskills-go/internal/catalog"
+	"github.com/flexigpt/agentskills-go/internal/semver"
 	"github.com/flexigpt/agentskills-go/spec"
 )
 
@@ -28,6 +29,8 @@ const (
 	maxSkillArgumentBytes    = 4096
 	maxSkillTags             = 64
 	maxSkillTagBytes         = 128
+	maxSkillRequires         = 32
+	maxSkillConflicts        = 32
 
 	propKeyName = "name"
 )
@@ -116,6 +119,9 @@ func ParseSkillDocument(
 	)
 	warnings = append(warnings, insertWarnings...)
 
+	version, versionWarnings := parseSkillDocumentVersion(properties["version"])
+	warnings = append(warnings, versionWarnings...)
+
 	arguments, argumentWarnings := parseSkillDocumentArguments(
 		properties["arguments"],
 	)
@@ -124,6 +130,22 @@ func ParseSkillDocument(
 	tags, tagWarnings := parseSkillDocumentTags(properties["tags"])
 	warnings = append(warnings, tagWarnings...)
 
+	requires, requireWarnings := parseSkillDocumentSkillNames(
+		properties["requires"],
+		"requires",
+		name,
+		maxSkillRequires,
+	)
+	warnings = append(warnings, requireWarnings...)
+
+	conflicts, conflictWarnings := parseSkillDocumentSkillNames(
+		properties["conflicts"],
+		"conflicts",
+		name,
+		maxSkillConflicts,
+	)
+	warnings = append(warnings, conflictWarnings...)
+
 	body = strings.ReplaceAll(body, "\r\n", "\n")
 	body = strings.ReplaceAll(body, "\r", "\n")
 	body = strings.TrimLeft(body, "\n")
@@ -157,8 +179,11 @@ func ParseSkillDocument(
 		DisplayName:    displayName,
 		Description:    description,
 		Insert:         insert,
+		Version:        version,
 		Arguments:      arguments,
 		Tags:           tags,
+		Requires:       requires,
+		Conflicts:      conflicts,
 		MarkdownBody:   body,
 		RawFrontmatter: cloneSkillDocumentMap(properties),
 	}, uniqueSkillDocumentWarnings(warnings), nil
@@ -240,6 +265,11 @@ func MarshalSkillDocument(
 	properties[propKeyName] = document.Name
 	properties["description"] = document.Description
 	properties["insert"] = string(insert)
+	if document.Version == "" {
+		delete(properties, "version")
+	} else {
+		properties["version"] = document.Version
+	}
 
 	if len(document.Arguments) == 0 {
 		delete(properties, "arguments")
@@ -264,6 +294,18 @@ func MarshalSkillDocument(
 		properties["tags"] = append([]string(nil), document.Tags...)
 	}
 
+	if len(document.Requires) == 0 {
+		delete(properties, "requires")
+	} else {
+		properties["requires"] = append([]string(nil), document.Requires...)
+	}
+
+	if len(document.Conflicts) == 0 {
+		delete(properties, "conflicts")
+	} else {
+		properties["conflicts"] = append([]string(nil), document.Conflicts...)
+	}
+
 	frontmatter, err := yaml.Marshal(properties)
 	if err != nil {
 		return nil, fmt.Errorf("encode SKILL.md frontmatter: %w", err)
@@ -408,6 +450,30 @@ func parseSkillDocumentInsert(
 	}
 }
 
+// parseSkillDocumentVersion parses the optional "version" field. Values that are not semantic versions are
+// ignored with a warning.
+func parseSkillDocumentVersion(raw any) (version string, versionWarnings []string) {
+	if raw == nil {
+		return "", nil
+	}
+	value, ok := raw.(string)
+	if !ok {
+		return "", []string{
+			"frontmatter.version was ignored because it is not a string; quote versions such as \"1.0.0\"",
+		}
+	}
+	value = strings.TrimSpace(value)
+	if !semver.IsValid(value) {
+		return "", []string{
+			fmt.Sprintf(
+				"frontmatter.version %q was ignored because it is not a semantic version (MAJOR.MINOR.PATCH)",
+				value,
+			),
+		}
+	}
+	return value, nil
+}
+
 func parseSkillDocumentArguments(
 	raw any,
 ) (args []spec.SkillArgument, warnings []string) {
@@ -647,6 +713,120 @@ func parseSkillDocumentTags(raw any) (tags, tagWarnings []string) {
 	return output, warnings
 }
 
+// parseSkillDocumentSkillNames parses a list of skill names such as "requires" or "conflicts". Invalid,
+// duplicate, and self-referencing entries are ignored with warnings.
+func parseSkillDocumentSkillNames(raw any, field, self string, limit int) (names, nameWarnings []string) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	var (
+		items    []any
+		warnings []string
+	)
+	switch value := raw.(type) {
+	case []any:
+		items = value
+	case []string:
+		items = make([]any, 0, len(value))
+		for _, item := range value {
+			items = append(items, item)
+		}
+	case string:
+		items = []any{value}
+		warnings = append(
+			warnings,
+			"frontmatter."+field+" string was treated as a one-item list",
+		)
+	default:
+		return nil, []string{
+			"frontmatter." + field + " was ignored because it is not a list or string",
+		}
+	}
+
+	output := make([]string, 0, min(len(items), limit))
+	seen := make(map[string]struct{}, len(items))
+	for index, item := range items {
+		if len(output) >= limit {
+			warnings = append(
+				warnings,
+				fmt.Sprintf(
+					"frontmatter.%s was truncated to %d entries",
+					field,
+					limit,
+				),
+			)
+			break
+		}
+
+		value, ok := item.(string)
+		if !ok {
+			warnings = append(
+				warnings,
+				fmt.Sprintf(
+					"frontmatter.%s[%d] was ignored because it is not a string",
+					field,
+					index,
+				),
+			)
+			continue
+		}
+
+		value = strings.TrimSpace(value)
+		if validateSkillDocumentName(value) != nil {
+			warnings = append(
+				warnings,
+				fmt.Sprintf(
+					"frontmatter.%s[%d] was ignored because it is not a valid skill name",
+					field,
+					index,
+				),
+			)
+			continue
+		}
+		if value == self {
+			warnings = append(
+				warnings,
+				fmt.Sprintf(
+					"frontmatter.%s[%d] was ignored because it names the skill itself",
+					field,
+					index,
+				),
+			)
+			continue
+		}
+		if _, duplicate := seen[value]; duplicate {
+			continue
+		}
+
+		seen[value] = struct{}{}
+		output = append(output, value)
+	}
+
+	return output, warnings
+}
+
+// validateSkillDocumentSkillNames validates a list of skill names such as "requires" or "conflicts".
+func validateSkillDocumentSkillNames(names []string, field, self string, limit int) error {
+	if len(names) > limit {
+		return fmt.Errorf("%s exceeds %d entries", field, limit)
+	}
+	seen := make(map[string]struct{}, len(names))
+	for index, name := range names {
+		if validateSkillDocumentName(name) != nil {
+			return fmt.Errorf("%s[%d] is not a valid skill name", field, index)
+		}
+		if name == self {
+			return fmt.Errorf("%s[%d] must not reference the skill itself", field, index)
+		}
+		if _, duplicate := seen[name]; duplicate {
+			return fmt.Errorf("duplicate %s entry %q", field, name)
+		}
+		seen[name] = struct{}{}
+	}
+	return nil
+}
+
 func ValidateSkillDocument(document spec.SkillDocument) error {
 	if err := validateSkillDocumentName(document.Name); err != nil {
 		return err
@@ -668,6 +848,9 @@ func ValidateSkillDocument(document spec.SkillDocument) error {
 	if _, ok := catalog.NormalizeSkillInsert(document.Insert); !ok {
 		return fmt.Errorf("unsupported insert value %q", document.Insert)
 	}
+	if document.Version != "" && !semver.IsValid(document.Version) {
+		return fmt.Errorf("version %q is not a semantic version", document.Version)
+	}
 	if len(document.Arguments) > maxSkillArguments {
 		return fmt.Errorf(
 			"arguments exceeds %d entries",
@@ -713,6 +896,23 @@ func ValidateSkillDocument(document spec.SkillDocument) error {
 		seenTags[tag] = struct{}{}
 	}
 
+	if err := validateSkillDocumentSkillNames(
+		document.Requires,
+		"requires",
+		document.Name,
+		maxSkillRequires,
+	); err != nil {
+		return err
+	}
+	if err := validateSkillDocumentSkillNames(
+		document.Conflicts,
+		"conflicts",
+		document.Name,
+		maxSkillConflicts,
+	); err != nil {
+		return err
+	}
+
 	if !utf8.ValidString(document.MarkdownBody) {
 		return errors.New("markdownBody must contain valid UTF-8")
 	}
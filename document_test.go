@@ -155,6 +155,138 @@ func TestMarshalSkillDocumentRoundTrip(t *testing.T) {
 	}
 }
 
+func TestParseSkillDocument_RequiresRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	document, warnings, err := ParseSkillDocument([]byte(`---
+name: deploy
+description: Deploys services.
+requires:
+  - k8s-conventions
+  - " k8s-conventions "
+  - deploy
+  - Not A Name
+  - 7
+  - release-notes
+---
+Deploy.
+`), spec.ParseSkillDocumentOptions{})
+	if err != nil {
+		t.Fatalf("ParseSkillDocument() error = %v", err)
+	}
+	if strings.Join(document.Requires, ",") != "k8s-conventions,release-notes" {
+		t.Fatalf("Requires = %v", document.Requires)
+	}
+	allWarnings := strings.Join(warnings, "\n")
+	for _, expected := range []string{
+		"requires[2] was ignored because it names the skill itself",
+		"requires[3] was ignored because it is not a valid skill name",
+		"requires[4] was ignored because it is not a string",
+	} {
+		if !strings.Contains(allWarnings, expected) {
+			t.Fatalf("warnings %q do not contain %q", allWarnings, expected)
+		}
+	}
+
+	raw, err := MarshalSkillDocument(document)
+	if err != nil {
+		t.Fatalf("MarshalSkillDocument() error = %v", err)
+	}
+	output, _, err := ParseSkillDocument(raw, spec.ParseSkillDocumentOptions{})
+	if err != nil {
+		t.Fatalf("ParseSkillDocument() round-trip error = %v", err)
+	}
+	if strings.Join(output.Requires, ",") != "k8s-conventions,release-notes" {
+		t.Fatalf("round-trip Requires = %v", output.Requires)
+	}
+}
+
+func TestParseSkillDocument_ConflictsRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	document, warnings, err := ParseSkillDocument([]byte(`---
+name: google-style
+description: Google code style.
+conflicts: airbnb-style
+---
+Style.
+`), spec.ParseSkillDocumentOptions{})
+	if err != nil {
+		t.Fatalf("ParseSkillDocument() error = %v", err)
+	}
+	if strings.Join(document.Conflicts, ",") != "airbnb-style" {
+		t.Fatalf("Conflicts = %v", document.Conflicts)
+	}
+	if strings.Join(warnings, "\n") != "frontmatter.conflicts string was treated as a one-item list" {
+		t.Fatalf("warnings = %q", warnings)
+	}
+
+	document.Conflicts = append(document.Conflicts, "standard-style")
+	raw, err := MarshalSkillDocument(document)
+	if err != nil {
+		t.Fatalf("MarshalSkillDocument() error = %v", err)
+	}
+	output, _, err := ParseSkillDocument(raw, spec.ParseSkillDocumentOptions{})
+	if err != nil {
+		t.Fatalf("ParseSkillDocument() round-trip error = %v", err)
+	}
+	if strings.Join(output.Conflicts, ",") != "airbnb-style,standard-style" {
+		t.Fatalf("round-trip Conflicts = %v", output.Conflicts)
+	}
+}
+
+func TestParseSkillDocument_Version(t *testing.T) {
+	t.Parallel()
+
+	document, warnings, err := ParseSkillDocument([]byte(`---
+name: versioned
+description: A versioned skill.
+version: 1.2.0-rc.1
+---
+Body.
+`), spec.ParseSkillDocumentOptions{})
+	if err != nil {
+		t.Fatalf("ParseSkillDocument() error = %v", err)
+	}
+	if document.Version != "1.2.0-rc.1" || len(warnings) != 0 {
+		t.Fatalf("Version = %q, warnings = %q", document.Version, warnings)
+	}
+
+	document.Version = "2.0.0"
+	raw, err := MarshalSkillDocument(document)
+	if err != nil {
+		t.Fatalf("MarshalSkillDocument() error = %v", err)
+	}
+	output, _, err := ParseSkillDocument(raw, spec.ParseSkillDocumentOptions{})
+	if err != nil {
+		t.Fatalf("ParseSkillDocument() round-trip error = %v", err)
+	}
+	if output.Version != "2.0.0" {
+		t.Fatalf("round-trip Version = %q", output.Version)
+	}
+
+	document.Version = "v2"
+	if err := ValidateSkillDocument(document); err == nil {
+		t.Fatalf("ValidateSkillDocument() accepted version %q", document.Version)
+	}
+
+	for _, version := range []string{"1.0", "v1.0.0", "1"} {
+		document, warnings, err := ParseSkillDocument([]byte(`---
+name: versioned
+description: A versioned skill.
+version: `+version+`
+---
+Body.
+`), spec.ParseSkillDocumentOptions{})
+		if err != nil {
+			t.Fatalf("ParseSkillDocument(%q) error = %v", version, err)
+		}
+		if document.Version != "" || len(warnings) != 1 || !strings.Contains(warnings[0], "frontmatter.version") {
+			t.Fatalf("version %q: Version = %q, warnings = %q", version, document.Version, warnings)
+		}
+	}
+}
+
 func TestParseSkillDocument_ToleratesAndNormalizesOptionalMetadata(t *testing.T) {
 	t.Parallel()
 
@@ -313,6 +445,24 @@ func TestRenderAndMarshalSkillDocument_RejectInvalidDocuments(t *testing.T) {
 				Tags:        []string{" writing "},
 			},
 		},
+		{
+			name: "self requirement",
+			document: spec.SkillDocument{
+				Name:        "valid-skill",
+				Description: "x",
+				Insert:      spec.SkillInsertInstructions,
+				Requires:    []string{"valid-skill"},
+			},
+		},
+		{
+			name: "duplicate conflict",
+			document: spec.SkillDocument{
+				Name:        "valid-skill",
+				Description: "x",
+				Insert:      spec.SkillInsertInstructions,
+				Conflicts:   []string{"other", "other"},
+			},
+		},
 		{
 			name: "invalid body utf8",
 			document: spec.SkillDocument{
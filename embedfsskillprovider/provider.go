@@ -0,0 +1,264 @@
+package embedfsskillprovider
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/flexigpt/agentskills-go"
+	llmtoolsgoSpec "github.com/flexigpt/llmtools-go/spec"
+
+	"github.com/flexigpt/agentskills-go/internal/providerutil"
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+const Type = "embedfs"
+
+const (
+	skillFileName   = agentskills.SkillDocumentFileName
+	maxSkillMDBytes = agentskills.MaxSkillDocumentBytes
+)
+
+// Provider serves skills from any fs.FS (including embed.FS).
+//
+// A skill location is a slash-separated directory path inside the FS (e.g. "skills/hello-skill").
+// The directory basename must match the SKILL.md frontmatter name, exactly like the fs provider.
+// Scripts are never executed.
+type Provider struct {
+	fsys fs.FS
+	typ  string
+}
+
+type Option func(*Provider) error
+
+// WithType overrides the provider type key (default "embedfs").
+// Use it to register several embedded file systems in one runtime.
+func WithType(t string) Option {
+	return func(p *Provider) error {
+		if strings.TrimSpace(t) == "" || strings.TrimSpace(t) != t {
+			return fmt.Errorf("%w: invalid provider type %q", spec.ErrInvalidArgument, t)
+		}
+		p.typ = t
+		return nil
+	}
+}
+
+func New(fsys fs.FS, opts ...Option) (*Provider, error) {
+	if fsys == nil {
+		return nil, fmt.Errorf("%w: nil fs.FS", spec.ErrInvalidArgument)
+	}
+	p := &Provider{fsys: fsys, typ: Type}
+	for _, o := range opts {
+		if o == nil {
+			continue
+		}
+		if err := o(p); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+func (p *Provider) Type() string { return p.typ }
+
+func (p *Provider) Index(ctx context.Context, def spec.SkillDef) (spec.ProviderSkillIndexRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return spec.ProviderSkillIndexRecord{}, err
+	}
+	if def.Type != p.typ {
+		return spec.ProviderSkillIndexRecord{}, fmt.Errorf(
+			"%w: wrong provider type: %q",
+			spec.ErrInvalidArgument,
+			def.Type,
+		)
+	}
+	if strings.TrimSpace(def.Name) == "" {
+		return spec.ProviderSkillIndexRecord{}, fmt.Errorf("%w: def.name is required", spec.ErrInvalidArgument)
+	}
+
+	root, err := canonicalRoot(p.fsys, def.Location)
+	if err != nil {
+		return spec.ProviderSkillIndexRecord{}, err
+	}
+
+	raw, err := p.readSkillFile(root)
+	if err != nil {
+		return spec.ProviderSkillIndexRecord{}, err
+	}
+	document, parseWarnings, err := agentskills.ParseSkillDocument(
+		raw,
+		spec.ParseSkillDocumentOptions{ExpectedName: path.Base(root)},
+	)
+	if err != nil {
+		return spec.ProviderSkillIndexRecord{}, fmt.Errorf("index %s: %w", root, err)
+	}
+	if document.Name != def.Name {
+		return spec.ProviderSkillIndexRecord{}, fmt.Errorf(
+			"%w: key.name=%q does not match SKILL.md frontmatter.name=%q",
+			spec.ErrInvalidArgument,
+			def.Name,
+			document.Name,
+		)
+	}
+
+	resources, resourceWarnings, err := p.indexResources(ctx, root)
+	if err != nil {
+		return spec.ProviderSkillIndexRecord{}, err
+	}
+
+	return spec.ProviderSkillIndexRecord{
+		Key:            spec.ProviderSkillKey{Type: def.Type, Name: def.Name, Location: root},
+		Name:           document.Name,
+		Description:    document.Description,
+		DisplayName:    document.DisplayName,
+		Insert:         document.Insert,
+		Version:        document.Version,
+		Arguments:      document.Arguments,
+		Tags:           document.Tags,
+		Requires:       document.Requires,
+		Conflicts:      document.Conflicts,
+		Resources:      resources,
+		RawFrontmatter: document.RawFrontmatter,
+		Warnings:       append(parseWarnings, resourceWarnings...),
+		Digest:         providerutil.Digest(raw),
+	}, nil
+}
+
+func (p *Provider) LoadBody(ctx context.Context, key spec.ProviderSkillKey) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if key.Type != p.typ {
+		return "", fmt.Errorf("%w: wrong provider type: %q", spec.ErrInvalidArgument, key.Type)
+	}
+	root, err := canonicalRoot(p.fsys, key.Location)
+	if err != nil {
+		return "", err
+	}
+	raw, err := p.readSkillFile(root)
+	if err != nil {
+		return "", err
+	}
+	document, _, err := agentskills.ParseSkillDocument(
+		raw,
+		spec.ParseSkillDocumentOptions{ExpectedName: path.Base(root)},
+	)
+	if err != nil {
+		return "", fmt.Errorf("load body %s: %w", root, err)
+	}
+	return document.MarkdownBody, nil
+}
+
+func (p *Provider) ReadResource(
+	ctx context.Context,
+	key spec.ProviderSkillKey,
+	resourceLocation string,
+	encoding spec.ReadResourceEncoding,
+) ([]llmtoolsgoSpec.ToolOutputUnion, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if key.Type != p.typ {
+		return nil, fmt.Errorf("%w: wrong provider type: %q", spec.ErrInvalidArgument, key.Type)
+	}
+	root, err := canonicalRoot(p.fsys, key.Location)
+	if err != nil {
+		return nil, err
+	}
+	enc, err := providerutil.NormalizeEncoding(encoding)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := providerutil.CleanLocation(resourceLocation)
+	if err != nil {
+		return nil, err
+	}
+	if rel == "." {
+		return nil, fmt.Errorf("%w: resource location must name a file", spec.ErrInvalidArgument)
+	}
+
+	name := path.Join(root, rel)
+	data, err := providerutil.ReadRegularFile(p.fsys, name, providerutil.MaxResourceBytes)
+	if err != nil {
+		return nil, err
+	}
+	return providerutil.ResourceOutput(rel, data, enc)
+}
+
+func (p *Provider) RunScript(
+	ctx context.Context,
+	key spec.ProviderSkillKey,
+	scriptLocation string,
+	args []string,
+	env map[string]string,
+	workdir string,
+) (spec.RunScriptOut, error) {
+	if err := ctx.Err(); err != nil {
+		return spec.RunScriptOut{}, err
+	}
+	if key.Type != p.typ {
+		return spec.RunScriptOut{}, fmt.Errorf("%w: wrong provider type: %q", spec.ErrInvalidArgument, key.Type)
+	}
+	return spec.RunScriptOut{}, spec.ErrRunScriptUnsupported
+}
+
+func (p *Provider) readSkillFile(root string) ([]byte, error) {
+	return providerutil.ReadRegularFile(p.fsys, path.Join(root, skillFileName), maxSkillMDBytes)
+}
+
+// indexResources lists regular, non-symlink files below root (excluding SKILL.md).
+func (p *Provider) indexResources(ctx context.Context, root string) (spec.SkillResourceInfo, []string, error) {
+	var (
+		locations []string
+		warnings  []string
+	)
+	err := fs.WalkDir(p.fsys, root, func(name string, d fs.DirEntry, walkErr error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rel := strings.TrimPrefix(strings.TrimPrefix(name, root), "/")
+		if walkErr != nil {
+			warnings = append(warnings, fmt.Sprintf("resource scan skipped %q: %v", rel, walkErr))
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if rel == "" || rel == "." || rel == skillFileName || d.IsDir() {
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			// Symlinks and special files are never advertised.
+			return nil
+		}
+		locations = append(locations, rel)
+		return nil
+	})
+	if err != nil {
+		return spec.SkillResourceInfo{}, warnings, err
+	}
+	return providerutil.ResourceInfo(locations), warnings, nil
+}
+
+// canonicalRoot validates a skill location and returns the clean fs.FS path of its directory.
+func canonicalRoot(fsys fs.FS, location string) (string, error) {
+	loc := strings.TrimPrefix(strings.TrimSpace(location), "./")
+	root, err := providerutil.CleanLocation(loc)
+	if err != nil {
+		return "", err
+	}
+	if root == "." || !fs.ValidPath(root) {
+		// The skill directory basename is its name, so the FS root itself cannot be a skill.
+		return "", fmt.Errorf("%w: invalid location %q", spec.ErrInvalidArgument, location)
+	}
+	st, err := fs.Lstat(fsys, root)
+	if err != nil {
+		return "", fmt.Errorf("%w: skill location %q: %w", spec.ErrInvalidArgument, location, err)
+	}
+	if !st.IsDir() {
+		return "", fmt.Errorf("%w: not a directory: %q", spec.ErrInvalidArgument, location)
+	}
+	return root, nil
+}
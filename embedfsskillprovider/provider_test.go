@@ -0,0 +1,206 @@
+package embedfsskillprovider
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/flexigpt/agentskills-go"
+	llmtoolsgoSpec "github.com/flexigpt/llmtools-go/spec"
+
+	"github.com/flexigpt/agentskills-go/internal/providerutil"
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+const (
+	helloSkillName = "hello-skill"
+	helloSkillRoot = "skills/hello-skill"
+)
+
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		helloSkillRoot + "/SKILL.md": &fstest.MapFile{
+			Data: []byte("---\nname: hello-skill\ndescription: embedded hello\n---\n\nSay hello.\n"),
+		},
+		helloSkillRoot + "/references/guide.md": &fstest.MapFile{Data: []byte("guide")},
+		helloSkillRoot + "/assets/logo.png":     &fstest.MapFile{Data: []byte("\x89PNG\r\n\x1a\n\x00\x00")},
+		helloSkillRoot + "/link.md":             &fstest.MapFile{Data: []byte("guide.md"), Mode: fs.ModeSymlink},
+		"skills/mismatch/SKILL.md": &fstest.MapFile{
+			Data: []byte("---\nname: other-name\ndescription: x\n---\n\nBody\n"),
+		},
+		"skills/not-a-dir": &fstest.MapFile{Data: []byte("x")},
+	}
+}
+
+func mustNew(t *testing.T, fsys fs.FS, opts ...Option) *Provider {
+	t.Helper()
+	p, err := New(fsys, opts...)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return p
+}
+
+func TestNew_Validation(t *testing.T) {
+	t.Parallel()
+
+	if _, err := New(nil); !errors.Is(err, spec.ErrInvalidArgument) {
+		t.Fatalf("expected invalid argument for nil fs, got %v", err)
+	}
+	if _, err := New(testFS(), WithType(" ")); !errors.Is(err, spec.ErrInvalidArgument) {
+		t.Fatalf("expected invalid argument for blank type, got %v", err)
+	}
+	p := mustNew(t, testFS(), nil, WithType("builtin"))
+	if p.Type() != "builtin" {
+		t.Fatalf("expected overridden type, got %q", p.Type())
+	}
+	if mustNew(t, testFS()).Type() != Type {
+		t.Fatalf("expected default type %q", Type)
+	}
+}
+
+func TestProvider_IndexAndLoadBody(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	p := mustNew(t, testFS())
+
+	rec, err := p.Index(ctx, spec.SkillDef{Type: Type, Name: helloSkillName, Location: "./" + helloSkillRoot + "/"})
+	if err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+	if rec.Key.Location != helloSkillRoot {
+		t.Fatalf("expected canonical location %q, got %q", helloSkillRoot, rec.Key.Location)
+	}
+	if rec.Description != "embedded hello" {
+		t.Fatalf("unexpected description: %q", rec.Description)
+	}
+	if !strings.HasPrefix(rec.Digest, "sha256:") {
+		t.Fatalf("expected sha256 digest, got %q", rec.Digest)
+	}
+	want := []string{"assets/logo.png", "references/guide.md"}
+	if !rec.Resources.HasResources || strings.Join(rec.Resources.Locations, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected resources %v (symlink excluded), got %+v", want, rec.Resources)
+	}
+
+	body, err := p.LoadBody(ctx, rec.Key)
+	if err != nil {
+		t.Fatalf("LoadBody: %v", err)
+	}
+	if strings.TrimSpace(body) != "Say hello." {
+		t.Fatalf("unexpected body: %q", body)
+	}
+}
+
+func TestProvider_IndexErrors(t *testing.T) {
+	t.Parallel()
+
+	p := mustNew(t, testFS())
+	tests := []struct {
+		name string
+		def  spec.SkillDef
+	}{
+		{"wrong type", spec.SkillDef{Type: "fs", Name: helloSkillName, Location: helloSkillRoot}},
+		{"empty name", spec.SkillDef{Type: Type, Name: " ", Location: helloSkillRoot}},
+		{"root", spec.SkillDef{Type: Type, Name: helloSkillName, Location: "."}},
+		{"absolute", spec.SkillDef{Type: Type, Name: helloSkillName, Location: "/" + helloSkillRoot}},
+		{"traversal", spec.SkillDef{Type: Type, Name: helloSkillName, Location: "skills/../" + helloSkillRoot}},
+		{"missing", spec.SkillDef{Type: Type, Name: "missing", Location: "skills/missing"}},
+		{"not a dir", spec.SkillDef{Type: Type, Name: "not-a-dir", Location: "skills/not-a-dir"}},
+		{"name mismatch", spec.SkillDef{Type: Type, Name: "mismatch", Location: "skills/mismatch"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := p.Index(t.Context(), tt.def); err == nil {
+				t.Fatalf("expected error")
+			}
+		})
+	}
+
+	_, err := p.Index(t.Context(), spec.SkillDef{Type: Type, Name: "missing", Location: "skills/missing"})
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected missing skill to wrap fs.ErrNotExist, got %v", err)
+	}
+}
+
+func TestProvider_ReadResource(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	fsys := testFS()
+	fsys[helloSkillRoot+"/big.bin"] = &fstest.MapFile{Data: make([]byte, providerutil.MaxResourceBytes+1)}
+	p := mustNew(t, fsys)
+	key := spec.ProviderSkillKey{Type: Type, Name: helloSkillName, Location: helloSkillRoot}
+
+	out, err := p.ReadResource(ctx, key, "references/guide.md", "")
+	if err != nil {
+		t.Fatalf("ReadResource text: %v", err)
+	}
+	if len(out) != 1 || out[0].TextItem == nil || out[0].TextItem.Text != "guide" {
+		t.Fatalf("unexpected text output: %+v", out)
+	}
+
+	out, err = p.ReadResource(ctx, key, "assets/logo.png", spec.ReadResourceEncodingBinary)
+	if err != nil {
+		t.Fatalf("ReadResource binary: %v", err)
+	}
+	if len(out) != 1 || out[0].Kind != llmtoolsgoSpec.ToolOutputKindImage || out[0].ImageItem.ImageMIME != "image/png" {
+		t.Fatalf("unexpected binary output: %+v", out)
+	}
+
+	if _, err := p.ReadResource(ctx, key, "assets/logo.png", spec.ReadResourceEncodingText); err == nil {
+		t.Fatalf("expected text read of binary resource to fail")
+	}
+	for _, loc := range []string{"../mismatch/SKILL.md", "/etc/passwd", "a\\b", ".", "link.md", "assets", "big.bin"} {
+		if _, err := p.ReadResource(ctx, key, loc, ""); err == nil {
+			t.Fatalf("expected error for resource %q", loc)
+		}
+	}
+	if _, err := p.ReadResource(ctx, key, "references/guide.md", "hex"); !errors.Is(err, spec.ErrInvalidArgument) {
+		t.Fatalf("expected invalid argument for unknown encoding, got %v", err)
+	}
+}
+
+func TestProvider_RunScriptUnsupported(t *testing.T) {
+	t.Parallel()
+
+	p := mustNew(t, testFS())
+	key := spec.ProviderSkillKey{Type: Type, Name: helloSkillName, Location: helloSkillRoot}
+	if _, err := p.RunScript(t.Context(), key, "scripts/x.sh", nil, nil, ""); !errors.Is(
+		err,
+		spec.ErrRunScriptUnsupported,
+	) {
+		t.Fatalf("expected ErrRunScriptUnsupported, got %v", err)
+	}
+}
+
+func TestProvider_RuntimeIntegration(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	rt, err := agentskills.New(agentskills.WithProvider(mustNew(t, testFS())))
+	if err != nil {
+		t.Fatalf("agentskills.New: %v", err)
+	}
+	def := spec.SkillDef{Type: Type, Name: helloSkillName, Location: helloSkillRoot}
+	if _, err := rt.AddSkill(ctx, def); err != nil {
+		t.Fatalf("AddSkill: %v", err)
+	}
+	sid, active, err := rt.NewSession(ctx, agentskills.WithSessionActiveSkills([]spec.SkillDef{def}))
+	if err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+	if len(active) != 1 {
+		t.Fatalf("expected one active skill, got %+v", active)
+	}
+	prompt, err := rt.SkillsPrompt(ctx, &agentskills.SkillFilter{SessionID: sid, Activity: spec.SkillActivityActive})
+	if err != nil {
+		t.Fatalf("SkillsPrompt: %v", err)
+	}
+	if !strings.Contains(prompt, "Say hello.") {
+		t.Fatalf("expected embedded body in prompt, got:\n%s", prompt)
+	}
+}
@@ -0,0 +1,180 @@
+package agentskills
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/flexigpt/agentskills-go/internal/catalog"
+	"github.com/flexigpt/agentskills-go/internal/session"
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+// Subscribe registers fn to receive runtime events (session lifecycle, session activation changes,
+// and catalog changes) until ctx is done or the returned cancel function is called.
+//
+// Events are delivered in order, one at a time, on a goroutine dedicated to the subscription and never
+// while runtime locks are held, so fn may call back into the Runtime. Publishing never blocks on a slow
+// subscriber: undelivered events queue up in memory.
+//
+// IMPORTANT CONTRACT:
+//   - This is a HOST/LIFECYCLE API; events carry only user-provided skill definitions (spec.SkillDef).
+func (r *Runtime) Subscribe(ctx context.Context, fn func(spec.Event)) (func(), error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("%w: nil context", spec.ErrInvalidArgument)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, fmt.Errorf("%w: nil runtime receiver", spec.ErrInvalidArgument)
+	}
+	if fn == nil {
+		return nil, fmt.Errorf("%w: nil event callback", spec.ErrInvalidArgument)
+	}
+	return r.events.subscribe(ctx, fn), nil
+}
+
+// eventBus fans events out to subscribers. Each subscriber has an unbounded queue drained by its own
+// goroutine, so publish never blocks and may be called while holding catalog/session locks.
+type eventBus struct {
+	mu     sync.Mutex
+	nextID uint64
+	subs   map[uint64]*subscriber
+}
+
+type subscriber struct {
+	fn func(spec.Event)
+
+	mu    sync.Mutex
+	queue []spec.Event
+
+	wake chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+func newEventBus() *eventBus {
+	return &eventBus{subs: map[uint64]*subscriber{}}
+}
+
+func (b *eventBus) subscribe(ctx context.Context, fn func(spec.Event)) func() {
+	s := &subscriber{fn: fn, wake: make(chan struct{}, 1), done: make(chan struct{})}
+
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+	b.subs[id] = s
+	b.mu.Unlock()
+
+	cancel := func() {
+		s.once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, id)
+			b.mu.Unlock()
+			close(s.done)
+		})
+	}
+	go s.run()
+	go func() {
+		select {
+		case <-ctx.Done():
+			cancel()
+		case <-s.done:
+		}
+	}()
+	return cancel
+}
+
+// hasSubscribers lets publishers skip building events nobody receives.
+func (b *eventBus) hasSubscribers() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs) > 0
+}
+
+func (b *eventBus) publish(ev spec.Event) {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, s := range b.subs {
+		s.mu.Lock()
+		s.queue = append(s.queue, ev)
+		s.mu.Unlock()
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// publishPruned reports def as pruned from each of the given sessions.
+func (b *eventBus) publishPruned(def spec.SkillDef, sessionIDs []string) {
+	for _, id := range sessionIDs {
+		b.publish(spec.Event{
+			Type:      spec.EventTypeSkillsPruned,
+			SessionID: spec.SessionID(id),
+			Skills:    []spec.SkillDef{def},
+		})
+	}
+}
+
+func (s *subscriber) run() {
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-s.wake:
+		}
+		for {
+			s.mu.Lock()
+			batch := s.queue
+			s.queue = nil
+			s.mu.Unlock()
+			if len(batch) == 0 {
+				break
+			}
+			for _, ev := range batch {
+				select {
+				case <-s.done:
+					return
+				default:
+				}
+				s.fn(ev)
+			}
+		}
+	}
+}
+
+// sessionEventPublisher converts store/session events (internal keys) into host events (defs).
+// Keys that are no longer in the catalog are dropped from the lists.
+func sessionEventPublisher(b *eventBus, cat *catalog.Catalog) func(session.Event) {
+	defs := func(keys []spec.ProviderSkillKey) []spec.SkillDef {
+		if len(keys) == 0 {
+			return nil
+		}
+		out := make([]spec.SkillDef, 0, len(keys))
+		for _, k := range keys {
+			if d, ok := cat.DefForKey(k); ok {
+				out = append(out, d)
+			}
+		}
+		return out
+	}
+	return func(ev session.Event) {
+		if !b.hasSubscribers() {
+			return
+		}
+		b.publish(spec.Event{
+			Type:                 ev.Type,
+			SessionID:            spec.SessionID(ev.SessionID),
+			EvictionReason:       ev.Reason,
+			Skills:               defs(ev.Keys),
+			PreviousActiveSkills: defs(ev.Previous),
+			ActiveSkills:         defs(ev.Active),
+		})
+	}
+}
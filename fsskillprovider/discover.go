@@ -0,0 +1,116 @@
+package fsskillprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+// Discover implements spec.SkillDiscoverer.
+//
+// It walks rootLocation (without following symlinks) and returns every directory up to opts.MaxDepth
+// that contains a SKILL.md. Skill directories are not descended into (their contents are resources).
+// The returned defs use the directory basename as name and a location joined onto the user-provided
+// rootLocation, so canonicalization stays internal.
+func (p *Provider) Discover(
+	ctx context.Context,
+	rootLocation string,
+	opts spec.SkillDiscoveryOptions,
+) ([]spec.SkillDiscoveryCandidate, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	for _, pattern := range opts.Ignore {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("%w: invalid ignore pattern %q: %w", spec.ErrInvalidArgument, pattern, err)
+		}
+	}
+	maxDepth := opts.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = 1
+	}
+
+	root, err := canonicalRoot(rootLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []spec.SkillDiscoveryCandidate
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, walkErr error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rel := resourceRelLocation(root, p)
+		if rel == "." {
+			if walkErr != nil {
+				return walkErr
+			}
+			return nil
+		}
+		if walkErr != nil || d == nil || !d.IsDir() {
+			// Unreadable entries, files and symlinks are never skill roots.
+			if walkErr != nil && d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		depth := strings.Count(rel, "/") + 1
+		name := d.Name()
+		isSkill := hasSkillFile(p)
+
+		skip := ""
+		switch {
+		case strings.HasPrefix(name, ".") && !opts.IncludeHidden:
+			skip = "hidden directory"
+		default:
+			if pattern, ok := matchIgnore(opts.Ignore, rel, name); ok {
+				skip = fmt.Sprintf("ignored by pattern %q", pattern)
+			}
+		}
+
+		if isSkill {
+			out = append(out, spec.SkillDiscoveryCandidate{
+				Def: spec.SkillDef{
+					Type:     Type,
+					Name:     name,
+					Location: filepath.Join(rootLocation, filepath.FromSlash(rel)),
+				},
+				SkipReason: skip,
+			})
+			return filepath.SkipDir
+		}
+		if skip != "" || depth >= maxDepth {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func hasSkillFile(dir string) bool {
+	_, err := os.Lstat(filepath.Join(dir, skillFileName))
+	return err == nil || !errors.Is(err, fs.ErrNotExist)
+}
+
+func matchIgnore(patterns []string, rel, name string) (string, bool) {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, rel); ok {
+			return pattern, true
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return pattern, true
+		}
+	}
+	return "", false
+}
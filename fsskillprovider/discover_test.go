@@ -0,0 +1,115 @@
+package fsskillprovider
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+func TestProvider_Discover(t *testing.T) {
+	t.Parallel()
+
+	tmp := t.TempDir()
+	// "b/c/inner" must not be reported because "b/c" is already a skill root.
+	for _, rel := range []string{"a", "b/c", "b/c/inner", "d/e/f", ".hidden", "skip-me"} {
+		dir := filepath.Join(tmp, filepath.FromSlash(rel))
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte("x"), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(tmp, "file.txt"), []byte("x"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	p, err := New()
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		opts spec.SkillDiscoveryOptions
+		want map[string]string // rel -> skip reason
+	}{
+		{
+			name: "default depth is one",
+			opts: spec.SkillDiscoveryOptions{},
+			want: map[string]string{"a": "", ".hidden": "hidden directory", "skip-me": ""},
+		},
+		{
+			name: "depth two, skill dirs not descended, ignore and hidden",
+			opts: spec.SkillDiscoveryOptions{MaxDepth: 2, Ignore: []string{"skip-*"}, IncludeHidden: true},
+			want: map[string]string{
+				"a":       "",
+				"b/c":     "",
+				".hidden": "",
+				"skip-me": `ignored by pattern "skip-*"`,
+			},
+		},
+		{
+			name: "depth three finds deep skill",
+			opts: spec.SkillDiscoveryOptions{MaxDepth: 3},
+			want: map[string]string{"a": "", "b/c": "", "d/e/f": "", ".hidden": "hidden directory", "skip-me": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := p.Discover(t.Context(), tmp, tt.opts)
+			if err != nil {
+				t.Fatalf("Discover: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %+v, want %v", got, tt.want)
+			}
+			for _, c := range got {
+				rel, err := filepath.Rel(tmp, c.Def.Location)
+				if err != nil {
+					t.Fatalf("rel: %v", err)
+				}
+				rel = filepath.ToSlash(rel)
+				reason, ok := tt.want[rel]
+				if !ok {
+					t.Fatalf("unexpected candidate %+v", c)
+				}
+				if c.SkipReason != reason {
+					t.Fatalf("%s: skip reason %q want %q", rel, c.SkipReason, reason)
+				}
+				if c.Def.Type != Type || c.Def.Name != filepath.Base(c.Def.Location) {
+					t.Fatalf("unexpected def %+v", c.Def)
+				}
+			}
+		})
+	}
+}
+
+func TestProvider_Discover_Errors(t *testing.T) {
+	t.Parallel()
+
+	p, err := New()
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	tmp := t.TempDir()
+
+	if _, err := p.Discover(t.Context(), filepath.Join(tmp, "missing"), spec.SkillDiscoveryOptions{}); !errors.Is(
+		err,
+		spec.ErrInvalidArgument,
+	) {
+		t.Fatalf("expected ErrInvalidArgument for missing root, got %v", err)
+	}
+	if _, err := p.Discover(t.Context(), tmp, spec.SkillDiscoveryOptions{Ignore: []string{"["}}); !errors.Is(
+		err,
+		spec.ErrInvalidArgument,
+	) {
+		t.Fatalf("expected ErrInvalidArgument for bad pattern, got %v", err)
+	}
+}
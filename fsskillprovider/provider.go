@@ -46,9 +46,12 @@ type skillDirIndex struct {
 	DisplayName string
 
 	Insert    spec.SkillInsert
+	Version   string
 	Arguments []spec.SkillArgument
 
-	Tags []string
+	Tags      []string
+	Requires  []string
+	Conflicts []string
 
 	Resources spec.SkillResourceInfo
 
@@ -229,8 +232,11 @@ func (p *Provider) Index(ctx context.Context, def spec.SkillDef) (spec.ProviderS
 		Description:    meta.Description,
 		DisplayName:    meta.DisplayName,
 		Insert:         meta.Insert,
+		Version:        meta.Version,
 		Arguments:      meta.Arguments,
 		Tags:           append([]string(nil), meta.Tags...),
+		Requires:       append([]string(nil), meta.Requires...),
+		Conflicts:      append([]string(nil), meta.Conflicts...),
 		Resources:      meta.Resources,
 		RawFrontmatter: meta.Props,
 		Warnings:       meta.Warnings,
@@ -436,8 +442,11 @@ func indexSkillDir(
 		Description: document.Description,
 		DisplayName: document.DisplayName,
 		Insert:      document.Insert,
+		Version:     document.Version,
 		Arguments:   append([]spec.SkillArgument(nil), document.Arguments...),
 		Tags:        append([]string(nil), document.Tags...),
+		Requires:    append([]string(nil), document.Requires...),
+		Conflicts:   append([]string(nil), document.Conflicts...),
 		Resources:   resources,
 		Props:       document.RawFrontmatter,
 		Warnings:    uniqueStrings(warnings),
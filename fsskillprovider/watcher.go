@@ -0,0 +1,368 @@
+package fsskillprovider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+const (
+	defaultWatchInterval = 2 * time.Second
+	defaultWatchDebounce = 500 * time.Millisecond
+
+	// maxWatchEntries caps how many directory entries are fingerprinted per skill root.
+	maxWatchEntries = 16384
+)
+
+// WatchTarget is the subset of *agentskills.Runtime used by Watcher.
+type WatchTarget interface {
+	// ListSkillDefs returns the definitions of the registered skills of the given provider type.
+	ListSkillDefs(ctx context.Context, providerType string) ([]spec.SkillDef, error)
+	RefreshSkill(ctx context.Context, def spec.SkillDef) (spec.SkillRefreshResult, error)
+}
+
+// WatchEventKind classifies a WatchEvent.
+type WatchEventKind string
+
+const (
+	// WatchEventRefreshed means a change was detected and the runtime catalog was refreshed.
+	// Result carries the refresh report (including removed skills when missing ones are removed).
+	WatchEventRefreshed WatchEventKind = "refreshed"
+
+	// WatchEventMissing means the skill root or SKILL.md disappeared and removal is disabled.
+	// The skill stays registered; it is reported once until it reappears.
+	WatchEventMissing WatchEventKind = "missing"
+
+	// WatchEventError means fingerprinting or refreshing failed for a skill.
+	WatchEventError WatchEventKind = "error"
+)
+
+// WatchEvent reports what the watcher observed for a single registered fs skill.
+type WatchEvent struct {
+	Kind WatchEventKind
+	Def  spec.SkillDef
+
+	Result spec.SkillRefreshResult
+	Err    error
+}
+
+type watchState struct {
+	fingerprint string
+	missing     bool
+	pending     bool
+	changedAt   time.Time
+}
+
+// Watcher polls the roots of every registered fs skill and pushes changes into the runtime catalog
+// through RefreshSkill. Polling keeps it portable (no inotify/FSEvents dependencies).
+//
+// A change is refreshed once the root fingerprint has been stable for the debounce duration, so
+// editors writing files in several steps trigger a single refresh.
+type Watcher struct {
+	target WatchTarget
+
+	interval      time.Duration
+	debounce      time.Duration
+	removeMissing bool
+	handler       func(WatchEvent)
+	now           func() time.Time
+
+	mu     sync.Mutex
+	states map[spec.SkillDef]*watchState
+}
+
+type WatchOption func(*Watcher) error
+
+// WithWatchInterval sets the polling interval used by Run. Default is 2s.
+func WithWatchInterval(d time.Duration) WatchOption {
+	return func(w *Watcher) error {
+		if d <= 0 {
+			return fmt.Errorf("%w: watch interval must be > 0", spec.ErrInvalidArgument)
+		}
+		w.interval = d
+		return nil
+	}
+}
+
+// WithWatchDebounce sets how long a change must be stable before it is refreshed. Default is 500ms.
+// Zero refreshes on the first poll that observes a change.
+func WithWatchDebounce(d time.Duration) WatchOption {
+	return func(w *Watcher) error {
+		if d < 0 {
+			return fmt.Errorf("%w: watch debounce must be >= 0", spec.ErrInvalidArgument)
+		}
+		w.debounce = d
+		return nil
+	}
+}
+
+// WithWatchRemoveMissing controls whether skills whose root disappeared are removed from the catalog
+// (default true). When disabled, a WatchEventMissing is reported instead.
+func WithWatchRemoveMissing(enabled bool) WatchOption {
+	return func(w *Watcher) error {
+		w.removeMissing = enabled
+		return nil
+	}
+}
+
+// WithWatchHandler registers a callback invoked synchronously from the polling goroutine, after each scan.
+func WithWatchHandler(fn func(WatchEvent)) WatchOption {
+	return func(w *Watcher) error {
+		w.handler = fn
+		return nil
+	}
+}
+
+func NewWatcher(target WatchTarget, opts ...WatchOption) (*Watcher, error) {
+	if target == nil {
+		return nil, fmt.Errorf("%w: nil watch target", spec.ErrInvalidArgument)
+	}
+	w := &Watcher{
+		target:        target,
+		interval:      defaultWatchInterval,
+		debounce:      defaultWatchDebounce,
+		removeMissing: true,
+		now:           time.Now,
+		states:        map[spec.SkillDef]*watchState{},
+	}
+	for _, o := range opts {
+		if o == nil {
+			continue
+		}
+		if err := o(w); err != nil {
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// Run polls until ctx is done. The first poll only records a baseline for each skill.
+func (w *Watcher) Run(ctx context.Context) error {
+	if err := w.Poll(ctx); err != nil {
+		return err
+	}
+	t := time.NewTicker(w.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+			if err := w.Poll(ctx); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Poll performs a single scan of all registered fs skills.
+//
+// Skills seen for the first time only get a baseline fingerprint. The returned error is non-nil only for
+// context errors or when listing skills fails; per-skill problems are reported as WatchEventError. No lock
+// is held while listing, fingerprinting or refreshing skills, and events are delivered to the handler after
+// the scan, so the handler may call Poll itself.
+func (w *Watcher) Poll(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	defs, err := w.target.ListSkillDefs(ctx, Type)
+	if err != nil {
+		return err
+	}
+
+	var events []WatchEvent
+	defer func() {
+		for _, ev := range events {
+			w.emit(ev)
+		}
+	}()
+	seen := make(map[spec.SkillDef]struct{}, len(defs))
+	for _, def := range defs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		seen[def] = struct{}{}
+		ev, err := w.pollSkill(ctx, def)
+		if err != nil {
+			return err
+		}
+		if ev != nil {
+			events = append(events, *ev)
+		}
+	}
+
+	// Forget skills that are no longer registered.
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for def := range w.states {
+		if _, ok := seen[def]; !ok {
+			delete(w.states, def)
+		}
+	}
+	return nil
+}
+
+// pollSkill fingerprints one skill root and refreshes the skill if needed. It returns the event to report,
+// if any.
+func (w *Watcher) pollSkill(ctx context.Context, def spec.SkillDef) (*WatchEvent, error) {
+	fp, missing, err := fingerprintSkillRoot(ctx, def.Location)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return &WatchEvent{Kind: WatchEventError, Def: def, Err: err}, nil
+	}
+	switch w.observe(def, fp, missing) {
+	case watchActionRefresh:
+		return w.refresh(ctx, def)
+	case watchActionMissing:
+		return &WatchEvent{Kind: WatchEventMissing, Def: def}, nil
+	default:
+		return nil, nil
+	}
+}
+
+type watchAction int
+
+const (
+	watchActionNone watchAction = iota
+	watchActionRefresh
+	watchActionMissing
+)
+
+// observe records a fingerprint of def's root and decides what to do about it.
+func (w *Watcher) observe(def spec.SkillDef, fp string, missing bool) watchAction {
+	now := w.now()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	st, ok := w.states[def]
+	if !ok {
+		w.states[def] = &watchState{fingerprint: fp, missing: missing}
+		return watchActionNone
+	}
+
+	if missing {
+		st.pending = false
+		if st.missing {
+			// Already reported or refreshed; wait until it reappears.
+			return watchActionNone
+		}
+		st.missing = true
+		st.fingerprint = ""
+		if w.removeMissing {
+			// Refresh once: a removed skill drops its state, and a failed removal is not retried every poll.
+			return watchActionRefresh
+		}
+		return watchActionMissing
+	}
+	st.missing = false
+
+	if fp != st.fingerprint {
+		st.fingerprint = fp
+		st.pending = true
+		st.changedAt = now
+	}
+	if !st.pending || now.Sub(st.changedAt) < w.debounce {
+		return watchActionNone
+	}
+	st.pending = false
+	return watchActionRefresh
+}
+
+func (w *Watcher) refresh(ctx context.Context, def spec.SkillDef) (*WatchEvent, error) {
+	res, err := w.target.RefreshSkill(ctx, def)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		if errors.Is(err, spec.ErrSkillNotFound) {
+			// Removed by the host in the meantime.
+			w.forget(def)
+			return nil, nil
+		}
+		return &WatchEvent{Kind: WatchEventError, Def: def, Err: err}, nil
+	}
+	if res.Status == spec.SkillRefreshStatusRemoved {
+		w.forget(def)
+	}
+	return &WatchEvent{Kind: WatchEventRefreshed, Def: def, Result: res}, nil
+}
+
+func (w *Watcher) forget(def spec.SkillDef) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.states, def)
+}
+
+func (w *Watcher) emit(ev WatchEvent) {
+	if w.handler != nil {
+		w.handler(ev)
+	}
+}
+
+// fingerprintSkillRoot hashes the name, size, mode and modification time of every entry below the
+// skill root. missing is true when the root or its SKILL.md no longer exists.
+func fingerprintSkillRoot(ctx context.Context, location string) (fingerprint string, missing bool, err error) {
+	root, err := canonicalRoot(location)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", true, nil
+		}
+		return "", false, err
+	}
+	if _, err := os.Lstat(filepath.Join(root, skillFileName)); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", true, nil
+		}
+		return "", false, err
+	}
+
+	h := sha256.New()
+	entries := 0
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, walkErr error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if walkErr != nil {
+			// Unreadable entries are part of the fingerprint so permission changes are noticed.
+			fmt.Fprintf(h, "%s\x00err\n", resourceRelLocation(root, path))
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		entries++
+		if entries > maxWatchEntries {
+			return filepath.SkipAll
+		}
+		info, err := d.Info()
+		if err != nil {
+			fmt.Fprintf(h, "%s\x00err\n", resourceRelLocation(root, path))
+			return nil
+		}
+		fmt.Fprintf(
+			h,
+			"%s\x00%d\x00%d\x00%d\n",
+			resourceRelLocation(root, path),
+			info.Mode(),
+			info.Size(),
+			info.ModTime().UnixNano(),
+		)
+		return nil
+	})
+	if err != nil {
+		return "", false, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), false, nil
+}
@@ -0,0 +1,326 @@
+package fsskillprovider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flexigpt/agentskills-go"
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+func writeWatchSkill(t *testing.T, root, body string) {
+	t.Helper()
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	doc := "---\nname: " + filepath.Base(root) + "\ndescription: watched\n---\n\n" + body + "\n"
+	if err := os.WriteFile(filepath.Join(root, "SKILL.md"), []byte(doc), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+}
+
+func TestWatcher_PollRefreshesChangedAndRemovesMissingSkills(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	tmp := t.TempDir()
+	root := filepath.Join(tmp, helloSkillName)
+	writeWatchSkill(t, root, "Body v1")
+
+	p, err := New()
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	rt, err := agentskills.New(agentskills.WithProvider(p))
+	if err != nil {
+		t.Fatalf("agentskills.New: %v", err)
+	}
+	def := spec.SkillDef{Type: Type, Name: helloSkillName, Location: root}
+	if _, err := rt.AddSkill(ctx, def); err != nil {
+		t.Fatalf("AddSkill: %v", err)
+	}
+	sid, _, err := rt.NewSession(ctx, agentskills.WithSessionActiveSkills([]spec.SkillDef{def}))
+	if err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+
+	var events []WatchEvent
+	w, err := NewWatcher(rt,
+		WithWatchDebounce(0),
+		WithWatchHandler(func(ev WatchEvent) { events = append(events, ev) }),
+	)
+	if err != nil {
+		t.Fatalf("NewWatcher: %v", err)
+	}
+
+	// Baseline + no-op poll.
+	for range 2 {
+		if err := w.Poll(ctx); err != nil {
+			t.Fatalf("Poll: %v", err)
+		}
+	}
+	if len(events) != 0 {
+		t.Fatalf("expected no events before changes, got %+v", events)
+	}
+
+	writeWatchSkill(t, root, "Body v2")
+	// Ensure the fingerprint changes even on filesystems with coarse mtimes.
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(root, "SKILL.md"), future, future); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	if err := w.Poll(ctx); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if len(events) != 1 || events[0].Kind != WatchEventRefreshed ||
+		events[0].Result.Status != spec.SkillRefreshStatusUpdated {
+		t.Fatalf("expected one updated refresh event, got %+v", events)
+	}
+
+	prompt, err := rt.SkillsPrompt(ctx, &agentskills.SkillFilter{SessionID: sid, Activity: spec.SkillActivityActive})
+	if err != nil {
+		t.Fatalf("SkillsPrompt: %v", err)
+	}
+	if !strings.Contains(prompt, "Body v2") {
+		t.Fatalf("expected refreshed body in active prompt, got:\n%s", prompt)
+	}
+
+	if err := os.RemoveAll(root); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if err := w.Poll(ctx); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if len(events) != 2 || events[1].Result.Status != spec.SkillRefreshStatusRemoved {
+		t.Fatalf("expected removed refresh event, got %+v", events)
+	}
+	recs, err := rt.ListSkills(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListSkills: %v", err)
+	}
+	if len(recs) != 0 {
+		t.Fatalf("expected skill to be removed from catalog, got %+v", recs)
+	}
+}
+
+func TestWatcher_MissingReportedOnceWhenRemovalDisabled(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	tmp := t.TempDir()
+	root := filepath.Join(tmp, helloSkillName)
+	writeWatchSkill(t, root, "Body")
+
+	p, err := New()
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	rt, err := agentskills.New(agentskills.WithProvider(p))
+	if err != nil {
+		t.Fatalf("agentskills.New: %v", err)
+	}
+	def := spec.SkillDef{Type: Type, Name: helloSkillName, Location: root}
+	if _, err := rt.AddSkill(ctx, def); err != nil {
+		t.Fatalf("AddSkill: %v", err)
+	}
+
+	var events []WatchEvent
+	w, err := NewWatcher(rt,
+		WithWatchDebounce(0),
+		WithWatchRemoveMissing(false),
+		WithWatchHandler(func(ev WatchEvent) { events = append(events, ev) }),
+	)
+	if err != nil {
+		t.Fatalf("NewWatcher: %v", err)
+	}
+	if err := w.Poll(ctx); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+
+	if err := os.RemoveAll(root); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	for range 2 {
+		if err := w.Poll(ctx); err != nil {
+			t.Fatalf("Poll: %v", err)
+		}
+	}
+	if len(events) != 1 || events[0].Kind != WatchEventMissing || events[0].Def != def {
+		t.Fatalf("expected exactly one missing event, got %+v", events)
+	}
+	recs, err := rt.ListSkills(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListSkills: %v", err)
+	}
+	if len(recs) != 1 {
+		t.Fatalf("expected skill to stay registered, got %+v", recs)
+	}
+}
+
+// failingRefreshTarget lists one skill and reports every refresh of it as failed.
+type failingRefreshTarget struct {
+	def       spec.SkillDef
+	refreshes int
+}
+
+func (f *failingRefreshTarget) ListSkillDefs(context.Context, string) ([]spec.SkillDef, error) {
+	return []spec.SkillDef{f.def}, nil
+}
+
+func (f *failingRefreshTarget) RefreshSkill(_ context.Context, def spec.SkillDef) (spec.SkillRefreshResult, error) {
+	f.refreshes++
+	return spec.SkillRefreshResult{Def: def, Status: spec.SkillRefreshStatusFailed}, nil
+}
+
+func TestWatcher_FailedRemovalIsNotRetriedEveryPoll(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	root := filepath.Join(t.TempDir(), helloSkillName)
+	writeWatchSkill(t, root, "Body")
+
+	target := &failingRefreshTarget{def: spec.SkillDef{Type: Type, Name: helloSkillName, Location: root}}
+	w, err := NewWatcher(target, WithWatchDebounce(0))
+	if err != nil {
+		t.Fatalf("NewWatcher: %v", err)
+	}
+	poll := func() {
+		t.Helper()
+		if err := w.Poll(ctx); err != nil {
+			t.Fatalf("Poll: %v", err)
+		}
+	}
+	poll()
+
+	if err := os.RemoveAll(root); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	for range 3 {
+		poll()
+	}
+	if target.refreshes != 1 {
+		t.Fatalf("expected one removal attempt while missing, got %d", target.refreshes)
+	}
+
+	// Reappearing re-arms the watcher.
+	writeWatchSkill(t, root, "Body v2")
+	poll()
+	if target.refreshes != 2 {
+		t.Fatalf("expected a refresh after the skill reappeared, got %d", target.refreshes)
+	}
+}
+
+func TestWatcher_HandlerCanPoll(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	root := filepath.Join(t.TempDir(), helloSkillName)
+	writeWatchSkill(t, root, "Body")
+
+	target := &failingRefreshTarget{def: spec.SkillDef{Type: Type, Name: helloSkillName, Location: root}}
+	var w *Watcher
+	var events []WatchEvent
+	w, err := NewWatcher(target,
+		WithWatchRemoveMissing(false),
+		WithWatchHandler(func(ev WatchEvent) {
+			events = append(events, ev)
+			// Polling from the handler must not deadlock; the skill was already reported missing.
+			if err := w.Poll(ctx); err != nil {
+				t.Errorf("nested Poll: %v", err)
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewWatcher: %v", err)
+	}
+	if err := w.Poll(ctx); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if err := os.RemoveAll(root); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- w.Poll(ctx) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Poll: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Poll deadlocked when the handler polled")
+	}
+	if len(events) != 1 || events[0].Kind != WatchEventMissing {
+		t.Fatalf("expected one missing event, got %+v", events)
+	}
+}
+
+func TestWatcher_DebounceAndOptionValidation(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewWatcher(nil); err == nil {
+		t.Fatalf("expected error for nil target")
+	}
+	if _, err := NewWatcher(&agentskills.Runtime{}, WithWatchInterval(0)); err == nil {
+		t.Fatalf("expected error for zero interval")
+	}
+	if _, err := NewWatcher(&agentskills.Runtime{}, WithWatchDebounce(-time.Second)); err == nil {
+		t.Fatalf("expected error for negative debounce")
+	}
+
+	ctx := t.Context()
+	tmp := t.TempDir()
+	root := filepath.Join(tmp, helloSkillName)
+	writeWatchSkill(t, root, "Body v1")
+
+	p, err := New()
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	rt, err := agentskills.New(agentskills.WithProvider(p))
+	if err != nil {
+		t.Fatalf("agentskills.New: %v", err)
+	}
+	if _, err := rt.AddSkill(ctx, spec.SkillDef{Type: Type, Name: helloSkillName, Location: root}); err != nil {
+		t.Fatalf("AddSkill: %v", err)
+	}
+
+	clock := time.Unix(1000, 0)
+	var events []WatchEvent
+	w, err := NewWatcher(rt,
+		WithWatchDebounce(time.Second),
+		WithWatchHandler(func(ev WatchEvent) { events = append(events, ev) }),
+	)
+	if err != nil {
+		t.Fatalf("NewWatcher: %v", err)
+	}
+	w.now = func() time.Time { return clock }
+
+	if err := w.Poll(ctx); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	writeWatchSkill(t, root, "Body v2")
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(root, "SKILL.md"), future, future); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+
+	if err := w.Poll(ctx); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("expected change to be debounced, got %+v", events)
+	}
+
+	clock = clock.Add(time.Second)
+	if err := w.Poll(ctx); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if len(events) != 1 || events[0].Result.Status != spec.SkillRefreshStatusUpdated {
+		t.Fatalf("expected refresh after debounce, got %+v", events)
+	}
+}
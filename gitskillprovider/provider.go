@@ -0,0 +1,395 @@
+package gitskillprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/flexigpt/agentskills-go"
+	llmtoolsgoSpec "github.com/flexigpt/llmtools-go/spec"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/flexigpt/agentskills-go/internal/providerutil"
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+const Type = "git"
+
+const (
+	skillFileName   = agentskills.SkillDocumentFileName
+	maxSkillMDBytes = agentskills.MaxSkillDocumentBytes
+
+	defaultMaxTreeEntries = 10000
+)
+
+// Provider serves skills from commits of local git repositories (plain or bare) without checking out
+// a worktree. Nothing is ever fetched, so it works fully offline.
+//
+// A skill location is "<local-repo-path>#<ref>:<subdir>". ref is anything git rev-parse style
+// resolution accepts (branch, tag, full or abbreviated commit hash, HEAD~1, ...). subdir is the
+// slash-separated skill directory in the commit tree; ":<subdir>" may be omitted for a skill at the
+// repository root. When subdir is given, its basename must match the SKILL.md frontmatter name.
+//
+// The canonical key pins the resolved commit, so body loads and resource reads always use the
+// indexed commit even if a branch moves; RefreshSkill picks up the new commit. The digest is
+// "<commit>:<tree>", where tree is the hash of the skill directory.
+//
+// Only regular and executable blobs are advertised as resources; symlinks and submodules are
+// skipped. Scripts are never executed.
+type Provider struct {
+	maxTreeEntries int
+}
+
+type Option func(*Provider) error
+
+// WithMaxTreeEntries caps how many tree entries are walked when indexing resources. Default is 10000.
+func WithMaxTreeEntries(n int) Option {
+	return func(p *Provider) error {
+		if n <= 0 {
+			return fmt.Errorf("%w: max tree entries must be > 0", spec.ErrInvalidArgument)
+		}
+		p.maxTreeEntries = n
+		return nil
+	}
+}
+
+func New(opts ...Option) (*Provider, error) {
+	p := &Provider{maxTreeEntries: defaultMaxTreeEntries}
+	for _, o := range opts {
+		if o == nil {
+			continue
+		}
+		if err := o(p); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+func (p *Provider) Type() string { return Type }
+
+func (p *Provider) Index(ctx context.Context, def spec.SkillDef) (spec.ProviderSkillIndexRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return spec.ProviderSkillIndexRecord{}, err
+	}
+	if def.Type != Type {
+		return spec.ProviderSkillIndexRecord{}, fmt.Errorf(
+			"%w: wrong provider type: %q",
+			spec.ErrInvalidArgument,
+			def.Type,
+		)
+	}
+	if strings.TrimSpace(def.Name) == "" {
+		return spec.ProviderSkillIndexRecord{}, fmt.Errorf("%w: def.name is required", spec.ErrInvalidArgument)
+	}
+
+	snap, err := openSnapshot(def.Location)
+	if err != nil {
+		return spec.ProviderSkillIndexRecord{}, err
+	}
+	raw, err := snap.readFile(skillFileName, maxSkillMDBytes)
+	if err != nil {
+		return spec.ProviderSkillIndexRecord{}, err
+	}
+	document, parseWarnings, err := agentskills.ParseSkillDocument(
+		raw,
+		spec.ParseSkillDocumentOptions{ExpectedName: snap.loc.expectedName()},
+	)
+	if err != nil {
+		return spec.ProviderSkillIndexRecord{}, fmt.Errorf("index %s: %w", def.Location, err)
+	}
+	if document.Name != def.Name {
+		return spec.ProviderSkillIndexRecord{}, fmt.Errorf(
+			"%w: key.name=%q does not match SKILL.md frontmatter.name=%q",
+			spec.ErrInvalidArgument,
+			def.Name,
+			document.Name,
+		)
+	}
+
+	resources, resourceWarnings, err := p.indexResources(ctx, snap.tree)
+	if err != nil {
+		return spec.ProviderSkillIndexRecord{}, err
+	}
+
+	return spec.ProviderSkillIndexRecord{
+		Key:            spec.ProviderSkillKey{Type: def.Type, Name: def.Name, Location: snap.pinned()},
+		Name:           document.Name,
+		Description:    document.Description,
+		DisplayName:    document.DisplayName,
+		Insert:         document.Insert,
+		Version:        document.Version,
+		Arguments:      document.Arguments,
+		Tags:           document.Tags,
+		Requires:       document.Requires,
+		Conflicts:      document.Conflicts,
+		Resources:      resources,
+		RawFrontmatter: document.RawFrontmatter,
+		Warnings:       append(parseWarnings, resourceWarnings...),
+		Digest:         snap.commit.String() + ":" + snap.tree.Hash.String(),
+	}, nil
+}
+
+func (p *Provider) LoadBody(ctx context.Context, key spec.ProviderSkillKey) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if key.Type != Type {
+		return "", fmt.Errorf("%w: wrong provider type: %q", spec.ErrInvalidArgument, key.Type)
+	}
+	snap, err := openSnapshot(key.Location)
+	if err != nil {
+		return "", err
+	}
+	raw, err := snap.readFile(skillFileName, maxSkillMDBytes)
+	if err != nil {
+		return "", err
+	}
+	document, _, err := agentskills.ParseSkillDocument(
+		raw,
+		spec.ParseSkillDocumentOptions{ExpectedName: snap.loc.expectedName()},
+	)
+	if err != nil {
+		return "", fmt.Errorf("load body %s: %w", key.Location, err)
+	}
+	return document.MarkdownBody, nil
+}
+
+func (p *Provider) ReadResource(
+	ctx context.Context,
+	key spec.ProviderSkillKey,
+	resourceLocation string,
+	encoding spec.ReadResourceEncoding,
+) ([]llmtoolsgoSpec.ToolOutputUnion, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if key.Type != Type {
+		return nil, fmt.Errorf("%w: wrong provider type: %q", spec.ErrInvalidArgument, key.Type)
+	}
+	enc, err := providerutil.NormalizeEncoding(encoding)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := providerutil.CleanLocation(resourceLocation)
+	if err != nil {
+		return nil, err
+	}
+	if rel == "." {
+		return nil, fmt.Errorf("%w: resource location must name a file", spec.ErrInvalidArgument)
+	}
+	snap, err := openSnapshot(key.Location)
+	if err != nil {
+		return nil, err
+	}
+	data, err := snap.readFile(rel, providerutil.MaxResourceBytes)
+	if err != nil {
+		return nil, err
+	}
+	return providerutil.ResourceOutput(rel, data, enc)
+}
+
+func (p *Provider) RunScript(
+	ctx context.Context,
+	key spec.ProviderSkillKey,
+	scriptLocation string,
+	args []string,
+	env map[string]string,
+	workdir string,
+) (spec.RunScriptOut, error) {
+	if err := ctx.Err(); err != nil {
+		return spec.RunScriptOut{}, err
+	}
+	if key.Type != Type {
+		return spec.RunScriptOut{}, fmt.Errorf("%w: wrong provider type: %q", spec.ErrInvalidArgument, key.Type)
+	}
+	return spec.RunScriptOut{}, spec.ErrRunScriptUnsupported
+}
+
+func (p *Provider) indexResources(ctx context.Context, tree *object.Tree) (spec.SkillResourceInfo, []string, error) {
+	var (
+		locations []string
+		warnings  []string
+	)
+	w := object.NewTreeWalker(tree, true, nil)
+	defer w.Close()
+	for entries := 0; ; entries++ {
+		if err := ctx.Err(); err != nil {
+			return spec.SkillResourceInfo{}, nil, err
+		}
+		if entries >= p.maxTreeEntries {
+			warnings = append(warnings, fmt.Sprintf("resource scan stopped after %d tree entries", p.maxTreeEntries))
+			break
+		}
+		name, entry, err := w.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return spec.SkillResourceInfo{}, nil, fmt.Errorf("walk tree %s: %w", tree.Hash, err)
+		}
+		if name == skillFileName || !isRegularBlob(entry.Mode) {
+			// Directories, symlinks and submodules are never advertised.
+			continue
+		}
+		locations = append(locations, name)
+	}
+	return providerutil.ResourceInfo(locations), warnings, nil
+}
+
+func isRegularBlob(m filemode.FileMode) bool {
+	return m.IsRegular() || m == filemode.Executable
+}
+
+type gitLocation struct {
+	// repo is the absolute repository path.
+	repo string
+	ref  string
+	// subdir is the clean skill directory in the commit tree ("." for the root).
+	subdir string
+}
+
+func (l gitLocation) expectedName() string {
+	if l.subdir == "." {
+		return ""
+	}
+	return path.Base(l.subdir)
+}
+
+// parseLocation splits "<local-repo-path>#<ref>[:<subdir>]".
+func parseLocation(location string) (gitLocation, error) {
+	raw := strings.TrimSpace(location)
+	if raw == "" {
+		return gitLocation{}, fmt.Errorf("%w: empty location", spec.ErrInvalidArgument)
+	}
+	if strings.ContainsRune(raw, '\x00') {
+		return gitLocation{}, fmt.Errorf("%w: location contains NUL byte", spec.ErrInvalidArgument)
+	}
+	i := strings.LastIndex(raw, "#")
+	if i < 0 {
+		return gitLocation{}, fmt.Errorf(
+			"%w: location must be <repo>#<ref>:<subdir>, got %q",
+			spec.ErrInvalidArgument,
+			location,
+		)
+	}
+	repo := raw[:i]
+	ref, subdir, hasSubdir := strings.Cut(raw[i+1:], ":")
+	if strings.TrimSpace(repo) == "" || strings.TrimSpace(ref) == "" || strings.TrimSpace(ref) != ref {
+		return gitLocation{}, fmt.Errorf(
+			"%w: location must be <repo>#<ref>:<subdir>, got %q",
+			spec.ErrInvalidArgument,
+			location,
+		)
+	}
+	sub := "."
+	if hasSubdir && subdir != "" {
+		s, err := providerutil.CleanLocation(subdir)
+		if err != nil {
+			return gitLocation{}, err
+		}
+		sub = s
+	}
+
+	abs, err := filepath.Abs(filepath.Clean(repo))
+	if err != nil {
+		return gitLocation{}, fmt.Errorf("%w: invalid repository path %q: %w", spec.ErrInvalidArgument, repo, err)
+	}
+	if resolved, rerr := filepath.EvalSymlinks(abs); rerr == nil && strings.TrimSpace(resolved) != "" {
+		abs = resolved
+	}
+	st, err := os.Stat(abs)
+	if err != nil {
+		return gitLocation{}, fmt.Errorf("%w: repository %q: %w", spec.ErrInvalidArgument, repo, err)
+	}
+	if !st.IsDir() {
+		return gitLocation{}, fmt.Errorf("%w: repository is not a directory: %q", spec.ErrInvalidArgument, repo)
+	}
+	return gitLocation{repo: abs, ref: ref, subdir: sub}, nil
+}
+
+// snapshot is a skill directory resolved at a specific commit.
+type snapshot struct {
+	loc    gitLocation
+	commit plumbing.Hash
+	tree   *object.Tree
+}
+
+func openSnapshot(location string) (*snapshot, error) {
+	loc, err := parseLocation(location)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := git.PlainOpen(loc.repo)
+	if err != nil {
+		return nil, fmt.Errorf("%w: open repository %q: %w", spec.ErrInvalidArgument, loc.repo, err)
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(loc.ref))
+	if err != nil {
+		return nil, fmt.Errorf("%w: ref %q does not resolve: %w", spec.ErrInvalidArgument, loc.ref, err)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("%w: ref %q is not a commit: %w", spec.ErrInvalidArgument, loc.ref, err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("read tree of %s: %w", hash, err)
+	}
+	if loc.subdir != "." {
+		tree, err = tree.Tree(loc.subdir)
+		if err != nil {
+			return nil, fmt.Errorf("%q not found at %s: %w", loc.subdir, hash, fs.ErrNotExist)
+		}
+	}
+	return &snapshot{loc: loc, commit: *hash, tree: tree}, nil
+}
+
+// pinned returns the canonical location with the ref replaced by the resolved commit hash.
+func (s *snapshot) pinned() string {
+	out := s.loc.repo + "#" + s.commit.String()
+	if s.loc.subdir != "." {
+		out += ":" + s.loc.subdir
+	}
+	return out
+}
+
+// readFile reads a regular blob at rel (relative to the skill directory).
+func (s *snapshot) readFile(rel string, limit int) ([]byte, error) {
+	entry, err := s.tree.FindEntry(rel)
+	if err != nil {
+		return nil, fmt.Errorf("%q not found at %s: %w", rel, s.commit, fs.ErrNotExist)
+	}
+	if !isRegularBlob(entry.Mode) {
+		return nil, fmt.Errorf("%w: %q is not a regular file", spec.ErrInvalidArgument, rel)
+	}
+	f, err := s.tree.TreeEntryFile(entry)
+	if err != nil {
+		return nil, fmt.Errorf("read %q at %s: %w", rel, s.commit, err)
+	}
+	if f.Size > int64(limit) {
+		return nil, fmt.Errorf("%s too large (max %d bytes)", rel, limit)
+	}
+	r, err := f.Reader()
+	if err != nil {
+		return nil, fmt.Errorf("read %q at %s: %w", rel, s.commit, err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+	if err != nil {
+		return nil, fmt.Errorf("read %q at %s: %w", rel, s.commit, err)
+	}
+	if len(data) > limit {
+		return nil, fmt.Errorf("%s too large (max %d bytes)", rel, limit)
+	}
+	return data, nil
+}
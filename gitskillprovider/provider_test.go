@@ -0,0 +1,230 @@
+package gitskillprovider
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flexigpt/agentskills-go"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+const (
+	helloSkillName = "hello-skill"
+	helloSubdir    = "skills/hello-skill"
+)
+
+type testRepo struct {
+	t    *testing.T
+	dir  string
+	repo *git.Repository
+	wt   *git.Worktree
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("PlainInit: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("Worktree: %v", err)
+	}
+	return &testRepo{t: t, dir: dir, repo: repo, wt: wt}
+}
+
+func (r *testRepo) write(rel, data string) {
+	r.t.Helper()
+	p := filepath.Join(r.dir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		r.t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(p, []byte(data), 0o600); err != nil {
+		r.t.Fatalf("write: %v", err)
+	}
+}
+
+func (r *testRepo) commit(msg string) plumbing.Hash {
+	r.t.Helper()
+	if err := r.wt.AddWithOptions(&git.AddOptions{All: true}); err != nil {
+		r.t.Fatalf("add: %v", err)
+	}
+	h, err := r.wt.Commit(msg, &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Unix(1700000000, 0)},
+	})
+	if err != nil {
+		r.t.Fatalf("commit: %v", err)
+	}
+	return h
+}
+
+func helloDoc(body string) string {
+	return "---\nname: hello-skill\ndescription: from git\n---\n\n" + body + "\n"
+}
+
+func mustNew(t *testing.T, opts ...Option) *Provider {
+	t.Helper()
+	p, err := New(opts...)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return p
+}
+
+func TestProvider_IndexPinsCommitAndServesFromTree(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	r := newTestRepo(t)
+	r.write(helloSubdir+"/SKILL.md", helloDoc("Body v1"))
+	r.write(helloSubdir+"/references/guide.md", "guide v1")
+	if err := os.Symlink("references/guide.md", filepath.Join(r.dir, helloSubdir, "link.md")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+	r.write("README.md", "outside")
+	c1 := r.commit("v1")
+	if _, err := r.repo.CreateTag("v1", c1, nil); err != nil {
+		t.Fatalf("tag: %v", err)
+	}
+
+	r.write(helloSubdir+"/SKILL.md", helloDoc("Body v2"))
+	c2 := r.commit("v2")
+
+	// Nothing below reads the worktree: wipe it to prove reads come from the object store.
+	if err := os.RemoveAll(filepath.Join(r.dir, "skills")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+
+	p := mustNew(t)
+	rec, err := p.Index(ctx, spec.SkillDef{Type: Type, Name: helloSkillName, Location: r.dir + "#v1:" + helloSubdir})
+	if err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+	if !strings.Contains(rec.Key.Location, "#"+c1.String()+":"+helloSubdir) {
+		t.Fatalf("expected key pinned to %s, got %q", c1, rec.Key.Location)
+	}
+	if !strings.HasPrefix(rec.Digest, c1.String()+":") {
+		t.Fatalf("expected commit+tree digest, got %q", rec.Digest)
+	}
+	if rec.Resources.TotalCount != 1 || rec.Resources.Locations[0] != "references/guide.md" {
+		t.Fatalf("expected only the regular resource, got %+v", rec.Resources)
+	}
+
+	body, err := p.LoadBody(ctx, rec.Key)
+	if err != nil || strings.TrimSpace(body) != "Body v1" {
+		t.Fatalf("LoadBody = %q, %v", body, err)
+	}
+	out, err := p.ReadResource(ctx, rec.Key, "references/guide.md", "")
+	if err != nil || len(out) != 1 || out[0].TextItem == nil || out[0].TextItem.Text != "guide v1" {
+		t.Fatalf("ReadResource = %+v, %v", out, err)
+	}
+	for _, loc := range []string{"link.md", "../../README.md", "missing.md"} {
+		if _, err := p.ReadResource(ctx, rec.Key, loc, ""); err == nil {
+			t.Fatalf("expected error for resource %q", loc)
+		}
+	}
+
+	for _, ref := range []string{"HEAD", "master", c2.String(), c2.String()[:10]} {
+		rec2, err := p.Index(ctx, spec.SkillDef{Type: Type, Name: helloSkillName, Location: r.dir + "#" + ref + ":" + helloSubdir})
+		if err != nil {
+			t.Fatalf("Index %s: %v", ref, err)
+		}
+		if !strings.HasPrefix(rec2.Digest, c2.String()+":") || rec2.Digest == rec.Digest {
+			t.Fatalf("ref %s: expected digest for %s, got %q", ref, c2, rec2.Digest)
+		}
+	}
+	if _, err := p.RunScript(ctx, rec.Key, "x.sh", nil, nil, ""); !errors.Is(err, spec.ErrRunScriptUnsupported) {
+		t.Fatalf("expected ErrRunScriptUnsupported, got %v", err)
+	}
+}
+
+func TestProvider_IndexErrors(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	r := newTestRepo(t)
+	r.write(helloSubdir+"/SKILL.md", helloDoc("Body"))
+	r.write("other/SKILL.md", helloDoc("Body"))
+	r.commit("init")
+	p := mustNew(t)
+
+	tests := []struct {
+		name     string
+		skill    string
+		location string
+		wantIs   error
+	}{
+		{"no ref", helloSkillName, r.dir, spec.ErrInvalidArgument},
+		{"empty ref", helloSkillName, r.dir + "#:" + helloSubdir, spec.ErrInvalidArgument},
+		{"unresolvable ref", helloSkillName, r.dir + "#nope:" + helloSubdir, spec.ErrInvalidArgument},
+		{"not a repo", helloSkillName, t.TempDir() + "#HEAD:" + helloSubdir, spec.ErrInvalidArgument},
+		{"traversal", helloSkillName, r.dir + "#HEAD:../x", spec.ErrInvalidArgument},
+		{"missing subdir", helloSkillName, r.dir + "#HEAD:skills/missing", fs.ErrNotExist},
+		{"no SKILL.md", helloSkillName, r.dir + "#HEAD:skills", fs.ErrNotExist},
+		{"dir name mismatch", helloSkillName, r.dir + "#HEAD:other", nil},
+		{"def name mismatch", "other-name", r.dir + "#HEAD:" + helloSubdir, spec.ErrInvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := p.Index(ctx, spec.SkillDef{Type: Type, Name: tt.skill, Location: tt.location})
+			if err == nil {
+				t.Fatalf("expected error")
+			}
+			if tt.wantIs != nil && !errors.Is(err, tt.wantIs) {
+				t.Fatalf("expected errors.Is(err, %v), got %v", tt.wantIs, err)
+			}
+		})
+	}
+
+	if _, err := New(WithMaxTreeEntries(0)); !errors.Is(err, spec.ErrInvalidArgument) {
+		t.Fatalf("expected invalid option error, got %v", err)
+	}
+}
+
+func TestProvider_RefreshFollowsBranch(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	r := newTestRepo(t)
+	r.write(helloSubdir+"/SKILL.md", helloDoc("Body v1"))
+	r.commit("v1")
+
+	rt, err := agentskills.New(agentskills.WithProvider(mustNew(t)))
+	if err != nil {
+		t.Fatalf("agentskills.New: %v", err)
+	}
+	def := spec.SkillDef{Type: Type, Name: helloSkillName, Location: r.dir + "#master:" + helloSubdir}
+	if _, err := rt.AddSkill(ctx, def); err != nil {
+		t.Fatalf("AddSkill: %v", err)
+	}
+	sid, _, err := rt.NewSession(ctx, agentskills.WithSessionActiveSkills([]spec.SkillDef{def}))
+	if err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+
+	r.write(helloSubdir+"/SKILL.md", helloDoc("Body v2"))
+	r.commit("v2")
+
+	res, err := rt.RefreshSkill(ctx, def)
+	if err != nil || res.Status != spec.SkillRefreshStatusUpdated {
+		t.Fatalf("expected updated refresh, got %+v, %v", res, err)
+	}
+	prompt, err := rt.SkillsPrompt(ctx, &agentskills.SkillFilter{SessionID: sid, Activity: spec.SkillActivityActive})
+	if err != nil {
+		t.Fatalf("SkillsPrompt: %v", err)
+	}
+	if !strings.Contains(prompt, "Body v2") {
+		t.Fatalf("expected new commit body in prompt, got:\n%s", prompt)
+	}
+}
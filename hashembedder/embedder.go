@@ -0,0 +1,104 @@
+// Package hashembedder provides a deterministic, dependency-free spec.Embedder based on feature hashing.
+//
+// Texts are split into lower-cased words; each word and each of its character trigrams is hashed into one
+// of the vector's dimensions. Similar spellings ("changelog", "change log") therefore get similar vectors,
+// but true paraphrases do not: the embedder is meant for tests and offline defaults, not as a replacement
+// for an embedding model.
+package hashembedder
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+// DefaultDimensions is the vector dimension used unless WithDimensions is given.
+const DefaultDimensions = 256
+
+// Embedder is a feature-hashing spec.Embedder. It is safe for concurrent use.
+type Embedder struct {
+	dims int
+}
+
+var _ spec.Embedder = (*Embedder)(nil)
+
+type Option func(*Embedder) error
+
+// WithDimensions sets the vector dimension (n > 0).
+func WithDimensions(n int) Option {
+	return func(e *Embedder) error {
+		if n <= 0 {
+			return fmt.Errorf("%w: dimensions must be positive, got %d", spec.ErrInvalidArgument, n)
+		}
+		e.dims = n
+		return nil
+	}
+}
+
+func New(opts ...Option) (*Embedder, error) {
+	e := &Embedder{dims: DefaultDimensions}
+	for _, o := range opts {
+		if o == nil {
+			continue
+		}
+		if err := o(e); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// Embed returns one L2-normalized vector per text. Texts without letters or digits get a zero vector.
+func (e *Embedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	out := make([][]float32, len(texts))
+	for i, text := range texts {
+		out[i] = e.embed(text)
+	}
+	return out, nil
+}
+
+func (e *Embedder) embed(text string) []float32 {
+	vec := make([]float32, e.dims)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, w := range words {
+		e.add(vec, "w:"+w, 1)
+		// Pad words so that prefixes and suffixes form their own trigrams.
+		runes := []rune("^" + w + "$")
+		for j := 0; j+3 <= len(runes); j++ {
+			e.add(vec, "t:"+string(runes[j:j+3]), 0.5)
+		}
+	}
+
+	var norm float64
+	for _, v := range vec {
+		norm += float64(v) * float64(v)
+	}
+	if norm > 0 {
+		scale := float32(1 / math.Sqrt(norm))
+		for i := range vec {
+			vec[i] *= scale
+		}
+	}
+	return vec
+}
+
+// add hashes feature into a dimension; one hash bit picks the sign to reduce the bias of collisions.
+func (e *Embedder) add(vec []float32, feature string, weight float32) {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(feature))
+	sum := h.Sum64()
+	if sum&1 == 1 {
+		weight = -weight
+	}
+	vec[(sum>>1)%uint64(e.dims)] += weight
+}
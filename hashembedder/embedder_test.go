@@ -0,0 +1,58 @@
+package hashembedder
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+func TestEmbedder_DeterministicNormalizedAndSimilar(t *testing.T) {
+	t.Parallel()
+
+	e, err := New(WithDimensions(128))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	texts := []string{"changelog writer", "Write the change log", "translate text", "--"}
+	a, err := e.Embed(t.Context(), texts)
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	b, _ := e.Embed(t.Context(), texts)
+	if len(a) != len(texts) {
+		t.Fatalf("got %d vectors, want %d", len(a), len(texts))
+	}
+	for i := range a {
+		if len(a[i]) != 128 || !slices.Equal(a[i], b[i]) {
+			t.Fatalf("vector %d is not deterministic with 128 dimensions", i)
+		}
+	}
+
+	dot := func(x, y []float32) float64 {
+		var s float64
+		for i := range x {
+			s += float64(x[i]) * float64(y[i])
+		}
+		return s
+	}
+	if n := dot(a[0], a[0]); math.Abs(n-1) > 1e-5 {
+		t.Fatalf("norm^2 = %v, want 1", n)
+	}
+	if dot(a[3], a[3]) != 0 {
+		t.Fatalf("expected a zero vector for text without words")
+	}
+	if related, unrelated := dot(a[0], a[1]), dot(a[0], a[2]); related <= unrelated {
+		t.Fatalf("similarity(changelog, change log) = %v <= similarity(changelog, translate) = %v", related, unrelated)
+	}
+}
+
+func TestNew_RejectsInvalidDimensions(t *testing.T) {
+	t.Parallel()
+
+	if _, err := New(WithDimensions(0)); !errors.Is(err, spec.ErrInvalidArgument) {
+		t.Fatalf("New(WithDimensions(0)) err = %v, want ErrInvalidArgument", err)
+	}
+}
@@ -0,0 +1,228 @@
+package httpskillprovider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/flexigpt/agentskills-go/internal/providerutil"
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+const (
+	// maxManifestBytes caps a skill manifest response.
+	maxManifestBytes = 1 << 20
+
+	// maxRedirects matches the default http.Client redirect limit.
+	maxRedirects = 10
+)
+
+// Manifest describes one skill served by a registry. It is the JSON document behind SkillDef.Location.
+//
+// Document and resource URLs may be relative; they are resolved against the manifest URL and must stay
+// on the same scheme and host.
+type Manifest struct {
+	Name string `json:"name"`
+
+	// Document is the URL of the SKILL.md file.
+	Document string `json:"document"`
+
+	// DocumentDigest is an optional content digest of SKILL.md (informational).
+	DocumentDigest string `json:"documentDigest,omitempty"`
+
+	Resources []ManifestResource `json:"resources,omitempty"`
+}
+
+// ManifestResource is a resource advertised by a skill manifest.
+type ManifestResource struct {
+	// Location is the slash-separated relative location passed to skills-readresource.
+	Location string `json:"location"`
+
+	// URL is where the resource bytes are fetched from.
+	URL string `json:"url"`
+
+	Size   int64  `json:"size,omitempty"`
+	Digest string `json:"digest,omitempty"`
+}
+
+type cacheEntry struct {
+	etag string
+	body []byte
+}
+
+// responseCache keeps ETag-validated response bodies, bounded by total body bytes.
+type responseCache struct {
+	mu       sync.Mutex
+	maxBytes int
+	size     int
+	entries  map[string]cacheEntry
+}
+
+func (c *responseCache) get(u string) (cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[u]
+	return e, ok
+}
+
+func (c *responseCache) put(u string, e cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if old, ok := c.entries[u]; ok {
+		c.size -= len(old.body)
+		delete(c.entries, u)
+	}
+	if e.etag == "" || len(e.body) > c.maxBytes {
+		return
+	}
+	for k, old := range c.entries {
+		if c.size+len(e.body) <= c.maxBytes {
+			break
+		}
+		c.size -= len(old.body)
+		delete(c.entries, k)
+	}
+	c.entries[u] = e
+	c.size += len(e.body)
+}
+
+// fetch GETs u with If-None-Match revalidation and returns the body and its ETag.
+// A 404/410 response is reported as fs.ErrNotExist so refreshes drop removed skills.
+func (p *Provider) fetch(ctx context.Context, u *url.URL, limit int) (body []byte, etag string, err error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	key := u.String()
+	cached, hasCached := p.cache.get(key)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", spec.ErrInvalidArgument, err)
+	}
+	for k, v := range p.headers {
+		req.Header.Set(k, v)
+	}
+	if hasCached {
+		req.Header.Set("If-None-Match", cached.etag)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("GET %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && hasCached:
+		return cached.body, cached.etag, nil
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return nil, "", fmt.Errorf("GET %s: %s: %w", key, resp.Status, fs.ErrNotExist)
+	case resp.StatusCode != http.StatusOK:
+		return nil, "", fmt.Errorf("GET %s: unexpected status %s", key, resp.Status)
+	}
+
+	if resp.ContentLength > int64(limit) {
+		return nil, "", fmt.Errorf("GET %s: response too large (max %d bytes)", key, limit)
+	}
+	body, err = io.ReadAll(io.LimitReader(resp.Body, int64(limit)+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("GET %s: %w", key, err)
+	}
+	if len(body) > limit {
+		return nil, "", fmt.Errorf("GET %s: response too large (max %d bytes)", key, limit)
+	}
+
+	etag = resp.Header.Get("ETag")
+	p.cache.put(key, cacheEntry{etag: etag, body: body})
+	return body, etag, nil
+}
+
+// fetchedManifest is a decoded manifest with resolved URLs.
+type fetchedManifest struct {
+	url       *url.URL
+	raw       []byte
+	etag      string
+	document  *url.URL
+	resources map[string]*url.URL
+	warnings  []string
+}
+
+func (p *Provider) fetchManifest(ctx context.Context, u *url.URL) (*fetchedManifest, error) {
+	raw, etag, err := p.fetch(ctx, u, maxManifestBytes)
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, fmt.Errorf("decode manifest %s: %w", u, err)
+	}
+	if strings.TrimSpace(m.Document) == "" {
+		return nil, fmt.Errorf("manifest %s: document URL is required", u)
+	}
+	doc, err := resolveSameOrigin(u, m.Document)
+	if err != nil {
+		return nil, fmt.Errorf("manifest %s: document: %w", u, err)
+	}
+
+	out := &fetchedManifest{
+		url:       u,
+		raw:       raw,
+		etag:      etag,
+		document:  doc,
+		resources: make(map[string]*url.URL, len(m.Resources)),
+	}
+	for _, r := range m.Resources {
+		loc, err := providerutil.CleanLocation(r.Location)
+		if err == nil && (loc == "." || loc != r.Location) {
+			err = fmt.Errorf("%w: non-canonical location", spec.ErrInvalidArgument)
+		}
+		var ru *url.URL
+		if err == nil {
+			ru, err = resolveSameOrigin(u, r.URL)
+		}
+		if err != nil {
+			out.warnings = append(out.warnings, fmt.Sprintf("manifest resource %q skipped: %v", r.Location, err))
+			continue
+		}
+		out.resources[loc] = ru
+	}
+	return out, nil
+}
+
+// sameOriginRedirect returns an http.Client CheckRedirect policy that rejects redirects to another scheme or
+// host, like resolveSameOrigin does for manifest URLs, and then applies next (nil means the default policy of
+// at most 10 redirects).
+func sameOriginRedirect(next func(*http.Request, []*http.Request) error) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) > 0 && (req.URL.Scheme != via[0].URL.Scheme || req.URL.Host != via[0].URL.Host) {
+			return fmt.Errorf("%w: redirect to %s leaves the registry origin", spec.ErrInvalidArgument, req.URL)
+		}
+		if next != nil {
+			return next(req, via)
+		}
+		if len(via) >= maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		return nil
+	}
+}
+
+// resolveSameOrigin resolves ref against base and rejects URLs on another scheme or host.
+func resolveSameOrigin(base *url.URL, ref string) (*url.URL, error) {
+	r, err := url.Parse(ref)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid URL %q: %w", spec.ErrInvalidArgument, ref, err)
+	}
+	u := base.ResolveReference(r)
+	if u.Scheme != base.Scheme || u.Host != base.Host {
+		return nil, fmt.Errorf("%w: URL %q leaves the registry origin", spec.ErrInvalidArgument, ref)
+	}
+	u.Fragment = ""
+	return u, nil
+}
@@ -0,0 +1,319 @@
+package httpskillprovider
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flexigpt/agentskills-go"
+	llmtoolsgoSpec "github.com/flexigpt/llmtools-go/spec"
+
+	"github.com/flexigpt/agentskills-go/internal/providerutil"
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+const Type = "http"
+
+const (
+	maxSkillMDBytes = agentskills.MaxSkillDocumentBytes
+
+	defaultTimeout       = 30 * time.Second
+	defaultMaxCacheBytes = 64 << 20
+)
+
+// Provider serves skills from an HTTP(S) registry.
+//
+// A skill location is the URL of a JSON Manifest. SKILL.md is fetched when indexing and when the body
+// is loaded; resources are fetched lazily on skills-readresource. Every response carrying an ETag is
+// cached and revalidated with If-None-Match, so unchanged documents cost a 304 round trip.
+//
+// The record digest is the manifest ETag (a compatible registry changes it whenever SKILL.md or any
+// resource changes, see RegistryHandler). Without a manifest ETag, the digest is a hash of the manifest
+// and SKILL.md bytes. A 404/410 manifest makes RefreshSkill remove the skill. Scripts are never executed.
+//
+// LoadBody and ReadResource never mix revisions: if the registry's current digest differs from the one
+// last indexed for the location, they fail with spec.ErrSkillStale until RefreshSkill re-indexes the skill.
+type Provider struct {
+	client  *http.Client
+	timeout time.Duration
+	headers map[string]string
+	cache   *responseCache
+
+	mu      sync.Mutex
+	indexed map[string]string // manifest URL -> digest of the last indexed revision
+}
+
+type Option func(*Provider) error
+
+// WithHTTPClient sets the client used for all requests. Default is a new http.Client.
+//
+// The provider uses a copy of c whose redirect policy additionally rejects redirects that leave the origin
+// of the original request; c's own CheckRedirect still applies to same-origin redirects.
+func WithHTTPClient(c *http.Client) Option {
+	return func(p *Provider) error {
+		if c == nil {
+			return fmt.Errorf("%w: nil http client", spec.ErrInvalidArgument)
+		}
+		cc := *c
+		cc.CheckRedirect = sameOriginRedirect(c.CheckRedirect)
+		p.client = &cc
+		return nil
+	}
+}
+
+// WithTimeout bounds each individual request. Default is 30s.
+func WithTimeout(d time.Duration) Option {
+	return func(p *Provider) error {
+		if d <= 0 {
+			return fmt.Errorf("%w: timeout must be > 0", spec.ErrInvalidArgument)
+		}
+		p.timeout = d
+		return nil
+	}
+}
+
+// WithRequestHeader adds a header (e.g. Authorization) to every request.
+func WithRequestHeader(key, value string) Option {
+	return func(p *Provider) error {
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("%w: empty header name", spec.ErrInvalidArgument)
+		}
+		p.headers[http.CanonicalHeaderKey(key)] = value
+		return nil
+	}
+}
+
+// WithMaxCacheBytes bounds the total size of cached response bodies. Default is 64 MiB.
+func WithMaxCacheBytes(n int) Option {
+	return func(p *Provider) error {
+		if n < 0 {
+			return fmt.Errorf("%w: max cache bytes must be >= 0", spec.ErrInvalidArgument)
+		}
+		p.cache.maxBytes = n
+		return nil
+	}
+}
+
+func New(opts ...Option) (*Provider, error) {
+	p := &Provider{
+		client:  &http.Client{CheckRedirect: sameOriginRedirect(nil)},
+		timeout: defaultTimeout,
+		headers: map[string]string{},
+		cache:   &responseCache{maxBytes: defaultMaxCacheBytes, entries: map[string]cacheEntry{}},
+		indexed: map[string]string{},
+	}
+	for _, o := range opts {
+		if o == nil {
+			continue
+		}
+		if err := o(p); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+func (p *Provider) Type() string { return Type }
+
+func (p *Provider) Index(ctx context.Context, def spec.SkillDef) (spec.ProviderSkillIndexRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return spec.ProviderSkillIndexRecord{}, err
+	}
+	if def.Type != Type {
+		return spec.ProviderSkillIndexRecord{}, fmt.Errorf(
+			"%w: wrong provider type: %q",
+			spec.ErrInvalidArgument,
+			def.Type,
+		)
+	}
+	if strings.TrimSpace(def.Name) == "" {
+		return spec.ProviderSkillIndexRecord{}, fmt.Errorf("%w: def.name is required", spec.ErrInvalidArgument)
+	}
+	u, err := parseManifestURL(def.Location)
+	if err != nil {
+		return spec.ProviderSkillIndexRecord{}, err
+	}
+
+	m, err := p.fetchManifest(ctx, u)
+	if err != nil {
+		return spec.ProviderSkillIndexRecord{}, err
+	}
+	raw, _, err := p.fetch(ctx, m.document, maxSkillMDBytes)
+	if err != nil {
+		return spec.ProviderSkillIndexRecord{}, err
+	}
+	document, parseWarnings, err := agentskills.ParseSkillDocument(raw, spec.ParseSkillDocumentOptions{})
+	if err != nil {
+		return spec.ProviderSkillIndexRecord{}, fmt.Errorf("index %s: %w", def.Location, err)
+	}
+	if document.Name != def.Name {
+		return spec.ProviderSkillIndexRecord{}, fmt.Errorf(
+			"%w: key.name=%q does not match SKILL.md frontmatter.name=%q",
+			spec.ErrInvalidArgument,
+			def.Name,
+			document.Name,
+		)
+	}
+
+	digest := revisionDigest(m, raw)
+	p.mu.Lock()
+	p.indexed[u.String()] = digest
+	p.mu.Unlock()
+
+	return spec.ProviderSkillIndexRecord{
+		Key:            spec.ProviderSkillKey{Type: def.Type, Name: def.Name, Location: u.String()},
+		Name:           document.Name,
+		Description:    document.Description,
+		DisplayName:    document.DisplayName,
+		Insert:         document.Insert,
+		Version:        document.Version,
+		Arguments:      document.Arguments,
+		Tags:           document.Tags,
+		Requires:       document.Requires,
+		Conflicts:      document.Conflicts,
+		Resources:      providerutil.ResourceInfo(slices.Collect(maps.Keys(m.resources))),
+		RawFrontmatter: document.RawFrontmatter,
+		Warnings:       append(parseWarnings, m.warnings...),
+		Digest:         digest,
+	}, nil
+}
+
+func (p *Provider) LoadBody(ctx context.Context, key spec.ProviderSkillKey) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if key.Type != Type {
+		return "", fmt.Errorf("%w: wrong provider type: %q", spec.ErrInvalidArgument, key.Type)
+	}
+	u, err := parseManifestURL(key.Location)
+	if err != nil {
+		return "", err
+	}
+	m, err := p.fetchManifest(ctx, u)
+	if err != nil {
+		return "", err
+	}
+	raw, _, err := p.fetch(ctx, m.document, maxSkillMDBytes)
+	if err != nil {
+		return "", err
+	}
+	if err := p.checkIndexed(u, revisionDigest(m, raw)); err != nil {
+		return "", err
+	}
+	document, _, err := agentskills.ParseSkillDocument(raw, spec.ParseSkillDocumentOptions{})
+	if err != nil {
+		return "", fmt.Errorf("load body %s: %w", key.Location, err)
+	}
+	return document.MarkdownBody, nil
+}
+
+func (p *Provider) ReadResource(
+	ctx context.Context,
+	key spec.ProviderSkillKey,
+	resourceLocation string,
+	encoding spec.ReadResourceEncoding,
+) ([]llmtoolsgoSpec.ToolOutputUnion, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if key.Type != Type {
+		return nil, fmt.Errorf("%w: wrong provider type: %q", spec.ErrInvalidArgument, key.Type)
+	}
+	enc, err := providerutil.NormalizeEncoding(encoding)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := providerutil.CleanLocation(resourceLocation)
+	if err != nil {
+		return nil, err
+	}
+	u, err := parseManifestURL(key.Location)
+	if err != nil {
+		return nil, err
+	}
+	m, err := p.fetchManifest(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	var raw []byte
+	if m.etag == "" {
+		// Without an ETag the revision digest covers SKILL.md too.
+		if raw, _, err = p.fetch(ctx, m.document, maxSkillMDBytes); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.checkIndexed(u, revisionDigest(m, raw)); err != nil {
+		return nil, err
+	}
+	ru, ok := m.resources[rel]
+	if !ok {
+		return nil, fmt.Errorf("%w: resource not found: %q", spec.ErrInvalidArgument, resourceLocation)
+	}
+	data, _, err := p.fetch(ctx, ru, providerutil.MaxResourceBytes)
+	if err != nil {
+		return nil, err
+	}
+	return providerutil.ResourceOutput(rel, data, enc)
+}
+
+func (p *Provider) RunScript(
+	ctx context.Context,
+	key spec.ProviderSkillKey,
+	scriptLocation string,
+	args []string,
+	env map[string]string,
+	workdir string,
+) (spec.RunScriptOut, error) {
+	if err := ctx.Err(); err != nil {
+		return spec.RunScriptOut{}, err
+	}
+	if key.Type != Type {
+		return spec.RunScriptOut{}, fmt.Errorf("%w: wrong provider type: %q", spec.ErrInvalidArgument, key.Type)
+	}
+	return spec.RunScriptOut{}, spec.ErrRunScriptUnsupported
+}
+
+// revisionDigest returns the digest of a manifest revision: the manifest ETag, or a hash of the manifest and
+// SKILL.md bytes when the registry sends no ETag.
+func revisionDigest(m *fetchedManifest, document []byte) string {
+	if m.etag != "" {
+		return m.etag
+	}
+	return providerutil.Digest(append(append([]byte(nil), m.raw...), document...))
+}
+
+// checkIndexed reports spec.ErrSkillStale if digest differs from the one last indexed for the manifest at u.
+func (p *Provider) checkIndexed(u *url.URL, digest string) error {
+	p.mu.Lock()
+	want, ok := p.indexed[u.String()]
+	p.mu.Unlock()
+	if ok && want != digest {
+		return fmt.Errorf("%w: %s (indexed %s, registry serves %s)", spec.ErrSkillStale, u, want, digest)
+	}
+	return nil
+}
+
+func parseManifestURL(location string) (*url.URL, error) {
+	raw := strings.TrimSpace(location)
+	if raw == "" {
+		return nil, fmt.Errorf("%w: empty location", spec.ErrInvalidArgument)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid URL %q: %w", spec.ErrInvalidArgument, location, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: location must be an absolute http(s) URL: %q", spec.ErrInvalidArgument, location)
+	}
+	if u.User != nil {
+		return nil, fmt.Errorf("%w: credentials in URL are not allowed; use WithRequestHeader", spec.ErrInvalidArgument)
+	}
+	u.Fragment = ""
+	return u, nil
+}
@@ -0,0 +1,226 @@
+package httpskillprovider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/flexigpt/agentskills-go"
+
+	"github.com/flexigpt/agentskills-go/internal/providerutil"
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+// RegistryHandler is a reference registry serving skills from an fs.FS. Every top-level directory that
+// contains a SKILL.md is a skill named after the directory.
+//
+// Routes:
+//
+//	GET /skills                         JSON list of skill names
+//	GET /skills/{name}                  Manifest
+//	GET /skills/{name}/SKILL.md         SKILL.md
+//	GET /skills/{name}/resources/{path} resource bytes
+//
+// Manifest URLs are relative, so the handler can be mounted under a prefix with http.StripPrefix.
+// Every response has a strong ETag over its body and honors If-None-Match. The manifest embeds the
+// SKILL.md and resource digests, so its ETag changes whenever any file of the skill changes.
+// Files are read on every request; symlinks and special files are never served.
+type RegistryHandler struct {
+	fsys fs.FS
+	mux  *http.ServeMux
+}
+
+func NewRegistryHandler(fsys fs.FS) (*RegistryHandler, error) {
+	if fsys == nil {
+		return nil, fmt.Errorf("%w: nil fs.FS", spec.ErrInvalidArgument)
+	}
+	h := &RegistryHandler{fsys: fsys, mux: http.NewServeMux()}
+	h.mux.HandleFunc("GET /skills", h.serveList)
+	h.mux.HandleFunc("GET /skills/{name}", h.serveManifest)
+	h.mux.HandleFunc("GET /skills/{name}/SKILL.md", h.serveDocument)
+	h.mux.HandleFunc("GET /skills/{name}/resources/{path...}", h.serveResource)
+	return h, nil
+}
+
+// NewTestRegistry starts an httptest.Server running a RegistryHandler over fsys.
+// The caller must Close the server.
+func NewTestRegistry(fsys fs.FS) (*httptest.Server, error) {
+	h, err := NewRegistryHandler(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return httptest.NewServer(h), nil
+}
+
+// SkillLocation returns the manifest URL (the SkillDef.Location) of a skill in a registry at baseURL.
+func SkillLocation(baseURL, name string) string {
+	return strings.TrimSuffix(baseURL, "/") + "/skills/" + url.PathEscape(name)
+}
+
+func (h *RegistryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *RegistryHandler) serveList(w http.ResponseWriter, r *http.Request) {
+	entries, err := fs.ReadDir(h.fsys, ".")
+	if err != nil {
+		http.Error(w, "cannot list skills", http.StatusInternalServerError)
+		return
+	}
+	names := []string{}
+	for _, e := range entries {
+		if e.IsDir() && h.isSkill(e.Name()) {
+			names = append(names, e.Name())
+		}
+	}
+	body, err := json.Marshal(map[string][]string{"skills": names})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	serveWithETag(w, r, "application/json", body)
+}
+
+func (h *RegistryHandler) serveManifest(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	doc, ok := h.readSkillFile(w, name, agentskills.SkillDocumentFileName, agentskills.MaxSkillDocumentBytes)
+	if !ok {
+		return
+	}
+
+	escaped := url.PathEscape(name)
+	m := Manifest{
+		Name:           name,
+		Document:       escaped + "/" + agentskills.SkillDocumentFileName,
+		DocumentDigest: providerutil.Digest(doc),
+	}
+	var resources []ManifestResource
+	err := fs.WalkDir(h.fsys, name, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel := strings.TrimPrefix(p, name+"/")
+		if d.IsDir() || !d.Type().IsRegular() || rel == agentskills.SkillDocumentFileName {
+			return nil
+		}
+		data, readErr := providerutil.ReadRegularFile(h.fsys, p, providerutil.MaxResourceBytes)
+		if readErr != nil {
+			// Oversized or unreadable files are not advertised.
+			return nil
+		}
+		resources = append(resources, ManifestResource{
+			Location: rel,
+			URL:      escaped + "/resources/" + escapePath(rel),
+			Size:     int64(len(data)),
+			Digest:   providerutil.Digest(data),
+		})
+		return nil
+	})
+	if err != nil {
+		http.Error(w, "cannot list resources", http.StatusInternalServerError)
+		return
+	}
+	slices.SortFunc(resources, func(a, b ManifestResource) int { return strings.Compare(a.Location, b.Location) })
+	m.Resources = resources
+
+	body, err := json.Marshal(m)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	serveWithETag(w, r, "application/json", body)
+}
+
+func (h *RegistryHandler) serveDocument(w http.ResponseWriter, r *http.Request) {
+	doc, ok := h.readSkillFile(
+		w,
+		r.PathValue("name"),
+		agentskills.SkillDocumentFileName,
+		agentskills.MaxSkillDocumentBytes,
+	)
+	if !ok {
+		return
+	}
+	serveWithETag(w, r, "text/markdown; charset=utf-8", doc)
+}
+
+func (h *RegistryHandler) serveResource(w http.ResponseWriter, r *http.Request) {
+	rel, err := providerutil.CleanLocation(r.PathValue("path"))
+	if err != nil || rel == "." || rel == agentskills.SkillDocumentFileName {
+		http.NotFound(w, r)
+		return
+	}
+	data, ok := h.readSkillFile(w, r.PathValue("name"), rel, providerutil.MaxResourceBytes)
+	if !ok {
+		return
+	}
+	ct := mime.TypeByExtension(path.Ext(rel))
+	if ct == "" {
+		ct = http.DetectContentType(data)
+	}
+	serveWithETag(w, r, ct, data)
+}
+
+func (h *RegistryHandler) isSkill(name string) bool {
+	st, err := fs.Lstat(h.fsys, path.Join(name, agentskills.SkillDocumentFileName))
+	return err == nil && st.Mode().IsRegular()
+}
+
+// readSkillFile reads rel inside skill name, writing an error response when it fails.
+func (h *RegistryHandler) readSkillFile(w http.ResponseWriter, name, rel string, limit int) ([]byte, bool) {
+	if !fs.ValidPath(name) || strings.Contains(name, "/") || strings.HasPrefix(name, ".") || !h.isSkill(name) {
+		http.Error(w, "skill not found", http.StatusNotFound)
+		return nil, false
+	}
+	data, err := providerutil.ReadRegularFile(h.fsys, path.Join(name, rel), limit)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			http.Error(w, "not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "cannot read file", http.StatusForbidden)
+		}
+		return nil, false
+	}
+	return data, true
+}
+
+func serveWithETag(w http.ResponseWriter, r *http.Request, contentType string, body []byte) {
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	_, _ = w.Write(body)
+}
+
+func etagMatches(header, etag string) bool {
+	for candidate := range strings.SplitSeq(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func escapePath(rel string) string {
+	parts := strings.Split(rel, "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,90 @@
+package httpskillprovider
+
+import (
+	"encoding/json"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestRegistryHandler_RoutesAndConditionalRequests(t *testing.T) {
+	t.Parallel()
+
+	h, err := NewRegistryHandler(fstest.MapFS{
+		"hello-skill/SKILL.md":        &fstest.MapFile{Data: []byte("---\nname: hello-skill\ndescription: x\n---\n")},
+		"hello-skill/notes.txt":       &fstest.MapFile{Data: []byte("notes")},
+		"hello-skill/link.txt":        &fstest.MapFile{Data: []byte("notes.txt"), Mode: fs.ModeSymlink | 0o777},
+		"not-a-skill/README.md":       &fstest.MapFile{Data: []byte("x")},
+		"hello-skill/nested/deep.txt": &fstest.MapFile{Data: []byte("deep")},
+	})
+	if err != nil {
+		t.Fatalf("NewRegistryHandler: %v", err)
+	}
+	if _, err := NewRegistryHandler(nil); err == nil {
+		t.Fatalf("expected error for nil fs")
+	}
+
+	get := func(path, ifNoneMatch string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Result()
+	}
+
+	resp := get("/skills", "")
+	var list map[string][]string
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		t.Fatalf("decode list: %v", err)
+	}
+	if len(list["skills"]) != 1 || list["skills"][0] != "hello-skill" {
+		t.Fatalf("unexpected skill list: %v", list)
+	}
+
+	resp = get("/skills/hello-skill", "")
+	var m Manifest
+	if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
+		t.Fatalf("decode manifest: %v", err)
+	}
+	if m.Document != "hello-skill/SKILL.md" || len(m.Resources) != 2 ||
+		m.Resources[0].Location != "nested/deep.txt" || m.Resources[1].Digest == "" {
+		t.Fatalf("unexpected manifest: %+v", m)
+	}
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		t.Fatalf("expected ETag on manifest")
+	}
+	if got := get("/skills/hello-skill", etag).StatusCode; got != http.StatusNotModified {
+		t.Fatalf("expected 304 for matching If-None-Match, got %d", got)
+	}
+	if got := get("/skills/hello-skill", `"other"`).StatusCode; got != http.StatusOK {
+		t.Fatalf("expected 200 for stale If-None-Match, got %d", got)
+	}
+
+	resp = get("/skills/hello-skill/resources/nested/deep.txt", "")
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "deep" {
+		t.Fatalf("unexpected resource response: %d %q", resp.StatusCode, body)
+	}
+
+	for _, path := range []string{
+		"/skills/missing",
+		"/skills/not-a-skill",
+		"/skills/hello-skill/resources/missing.txt",
+		"/skills/hello-skill/resources/SKILL.md",
+		"/skills/hello-skill/resources/..%2FSKILL.md",
+	} {
+		if got := get(path, "").StatusCode; got != http.StatusNotFound {
+			t.Fatalf("%s: expected 404, got %d", path, got)
+		}
+	}
+	if got := get("/skills/hello-skill/resources/link.txt", "").StatusCode; got == http.StatusOK {
+		t.Fatalf("expected symlink resource to be refused")
+	}
+}
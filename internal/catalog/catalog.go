@@ -1,15 +1,21 @@
 package catalog
 
 import (
+	"cmp"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io/fs"
+	"maps"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/flexigpt/agentskills-go/internal/semver"
 	"github.com/flexigpt/agentskills-go/spec"
 )
 
@@ -31,6 +37,13 @@ type entry struct {
 	// (success or failure).
 	bodyWait chan struct{}
 	bodyErr  error
+
+	// Vec is the embedding of vecText, the semantic text of idx when it was embedded (see Recommend).
+	vec     []float32
+	vecText string
+
+	// AddedAt is when the skill was registered; refreshes keep it.
+	addedAt time.Time
 }
 
 type handleKey struct {
@@ -51,6 +64,12 @@ type Catalog struct {
 
 	// HandleIndex maps LLM-facing handles (computed name + user location) to canonical internal keys.
 	handleIndex map[handleKey]spec.ProviderSkillKey
+
+	// VersionPins maps skill names to the version LLM-facing handles resolve to by default.
+	versionPins map[string]string
+
+	// Embedder is optional; it enables Recommend.
+	embedder spec.Embedder
 }
 
 func New(providers ProviderResolver) *Catalog {
@@ -76,52 +95,11 @@ func (c *Catalog) Add(ctx context.Context, def spec.SkillDef) (spec.SkillRecord,
 		)
 	}
 
-	p, ok := c.providers.Provider(def.Type)
-	if !ok || p == nil {
-		return spec.SkillRecord{}, errors.Join(
-			spec.ErrProviderNotFound,
-			fmt.Errorf("unknown provider type: %q", def.Type),
-		)
-	}
-
-	idx, err := p.Index(ctx, def)
+	idx, err := c.indexDef(ctx, def)
 	if err != nil {
 		return spec.SkillRecord{}, err
 	}
-
-	// Provider is allowed to canonicalize Location, but must not change Type/Name identity.
-	if idx.Key.Type != def.Type {
-		return spec.SkillRecord{}, fmt.Errorf(
-			"%w: provider changed type from %q to %q",
-			spec.ErrInvalidArgument,
-			def.Type,
-			idx.Key.Type,
-		)
-	}
-	if idx.Key.Name != def.Name {
-		return spec.SkillRecord{}, fmt.Errorf(
-			"%w: provider changed name from %q to %q",
-			spec.ErrInvalidArgument,
-			def.Name,
-			idx.Key.Name,
-		)
-	}
-
-	if strings.TrimSpace(idx.Key.Type) == "" ||
-		strings.TrimSpace(idx.Key.Name) == "" ||
-		strings.TrimSpace(idx.Key.Location) == "" {
-		return spec.SkillRecord{}, fmt.Errorf("%w: provider returned invalid record key", spec.ErrInvalidArgument)
-	}
-
-	insert, ok := NormalizeSkillInsert(idx.Insert)
-	if !ok {
-		return spec.SkillRecord{}, fmt.Errorf(
-			"%w: provider returned invalid insert value: %q",
-			spec.ErrInvalidArgument,
-			idx.Insert,
-		)
-	}
-	idx.Insert = insert
+	vec, vecText := c.embedRecord(ctx, idx)
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -134,7 +112,8 @@ func (c *Catalog) Add(ctx context.Context, def spec.SkillDef) (spec.SkillRecord,
 		return spec.SkillRecord{}, spec.ErrSkillAlreadyExists
 	}
 
-	e := &entry{def: def, idx: idx}
+	// Round(0) strips the monotonic reading so addedAt compares like a time decoded from a page cursor.
+	e := &entry{def: def, idx: idx, vec: vec, vecText: vecText, addedAt: time.Now().Round(0)}
 
 	// If a provider pre-populates SkillBody we treat it as already loaded
 	// only when non-empty. (With the current data model, empty-but-loaded
@@ -146,7 +125,7 @@ func (c *Catalog) Add(ctx context.Context, def spec.SkillDef) (spec.SkillRecord,
 
 	c.recomputeLLMNamesLocked()
 
-	return skillRecordFrom(def, idx), nil
+	return e.userRecord(), nil
 }
 
 // ResolveDef resolves an EXACT user-provided skill def (as originally added) to the internal canonical key.
@@ -175,19 +154,138 @@ func (c *Catalog) Remove(def spec.SkillDef) (spec.SkillRecord, spec.ProviderSkil
 		return spec.SkillRecord{}, spec.ProviderSkillKey{}, false
 	}
 
-	// Wake any waiters to avoid deadlocks if Remove races EnsureBody.
-	if ch := e.bodyWait; ch != nil {
-		e.bodyWait = nil
-		close(ch)
+	rec := e.userRecord()
+	c.removeEntryLocked(e)
+
+	c.recomputeLLMNamesLocked()
+	return rec, canon, true
+}
+
+// RefreshResult is the outcome of Refresh. Keys are INTERNAL canonical keys; callers must not expose them.
+type RefreshResult struct {
+	Status         spec.SkillRefreshStatus
+	Record         spec.SkillRecord
+	PreviousDigest string
+
+	// OldKey is the canonical key before the refresh. NewKey equals OldKey unless the provider
+	// re-canonicalized the location (e.g. a retargeted symlink). NewKey is zero for removed results.
+	OldKey spec.ProviderSkillKey
+	NewKey spec.ProviderSkillKey
+
+	// Err is the provider/validation error for failed and removed results.
+	Err error
+}
+
+// Refresh re-indexes a skill by its EXACT host/lifecycle definition and swaps the index record in place.
+//
+// Semantics:
+//   - If the provider returns the same non-empty digest and resource listing under the same key,
+//     the entry is left untouched.
+//   - Otherwise the index record is replaced and any cached/in-flight body is invalidated.
+//   - If the provider reports the skill as missing (fs.ErrNotExist or spec.ErrSkillNotFound), the entry is
+//     removed and Status is removed; the caller is responsible for pruning sessions using OldKey.
+//   - Any other index error leaves the entry untouched and returns Status failed.
+//
+// The returned error is non-nil only for context errors or when def is not registered.
+func (c *Catalog) Refresh(ctx context.Context, def spec.SkillDef) (RefreshResult, error) {
+	if err := ctx.Err(); err != nil {
+		return RefreshResult{}, err
 	}
 
-	rec := skillRecordFrom(e.def, e.idx)
+	c.mu.RLock()
+	oldKey, ok := c.byDef[def]
+	var snap *entry
+	var prev spec.SkillRecord
+	var vec []float32
+	var vecText string
+	if ok {
+		snap = c.byKey[oldKey]
+		if snap != nil {
+			prev = snap.userRecord()
+			vec, vecText = snap.vec, snap.vecText
+		}
+	}
+	c.mu.RUnlock()
+	if !ok || snap == nil {
+		return RefreshResult{}, spec.ErrSkillNotFound
+	}
 
-	delete(c.byKey, canon)
-	delete(c.byDef, e.def)
+	res := RefreshResult{
+		Status:         spec.SkillRefreshStatusFailed,
+		Record:         prev,
+		PreviousDigest: prev.Digest,
+		OldKey:         oldKey,
+		NewKey:         oldKey,
+	}
 
-	c.recomputeLLMNamesLocked()
-	return rec, canon, true
+	idx, indexErr := c.indexDef(ctx, def)
+	if indexErr != nil {
+		if err := ctx.Err(); err != nil {
+			return RefreshResult{}, err
+		}
+		res.Err = indexErr
+	}
+	if indexErr == nil && (vec == nil || vecText != semanticText(idx)) {
+		vec, vecText = c.embedRecord(ctx, idx)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// The entry may have been removed or replaced while the provider was indexing.
+	if k, exists := c.byDef[def]; !exists || k != oldKey || c.byKey[oldKey] != snap {
+		if !exists {
+			return RefreshResult{}, spec.ErrSkillNotFound
+		}
+		res.Err = errors.New("skill was modified concurrently; please retry")
+		return res, nil
+	}
+
+	if indexErr != nil {
+		if !isMissingSkillErr(indexErr) {
+			return res, nil
+		}
+		c.removeEntryLocked(snap)
+		c.recomputeLLMNamesLocked()
+		res.Status = spec.SkillRefreshStatusRemoved
+		res.NewKey = spec.ProviderSkillKey{}
+		return res, nil
+	}
+
+	if idx.Key == oldKey && idx.Digest != "" && idx.Digest == snap.idx.Digest &&
+		sameResourceInfo(idx.Resources, snap.idx.Resources) {
+		res.Status = spec.SkillRefreshStatusUnchanged
+		return res, nil
+	}
+
+	if idx.Key != oldKey {
+		if _, taken := c.byKey[idx.Key]; taken {
+			res.Err = fmt.Errorf("%w: refreshed key collides with another skill", spec.ErrSkillAlreadyExists)
+			return res, nil
+		}
+	}
+
+	// Invalidate any in-flight body load so a stale body cannot be published.
+	if ch := snap.bodyWait; ch != nil {
+		snap.bodyWait = nil
+		close(ch)
+	}
+	snap.idx = idx
+	snap.bodyLoaded = idx.SkillBody != ""
+	snap.bodyErr = nil
+	snap.vec, snap.vecText = vec, vecText
+
+	if idx.Key != oldKey {
+		delete(c.byKey, oldKey)
+		c.byKey[idx.Key] = snap
+		c.byDef[def] = idx.Key
+		c.recomputeLLMNamesLocked()
+	}
+
+	res.Status = spec.SkillRefreshStatusUpdated
+	res.Record = snap.userRecord()
+	res.NewKey = idx.Key
+	return res, nil
 }
 
 func (c *Catalog) GetIndex(key spec.ProviderSkillKey) (spec.ProviderSkillIndexRecord, bool) {
@@ -222,6 +320,109 @@ func (c *Catalog) HandleForKey(key spec.ProviderSkillKey) (spec.SkillHandle, boo
 	return spec.SkillHandle{Name: e.llmName, Location: e.def.Location}, true
 }
 
+// DefForKey returns the exact host/lifecycle definition registered for a canonical key.
+func (c *Catalog) DefForKey(key spec.ProviderSkillKey) (spec.SkillDef, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	e, ok := c.byKey[key]
+	if !ok {
+		return spec.SkillDef{}, false
+	}
+	return e.def, true
+}
+
+// SetVersionPins sets the catalog-level version pins (skill name -> semantic version) used by SelectVersion.
+func (c *Catalog) SetVersionPins(pins map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.versionPins = maps.Clone(pins)
+}
+
+// SelectVersion returns the key of the version of key's skill that LLM-facing handles resolve to.
+//
+// Only skill names registered with at least two distinct semantic versions have a selection: the version
+// pinned in pins, else the catalog-level pin (pins naming unregistered versions are skipped), else the
+// highest release version (or the highest pre-release if there is no release). Keys without a selection are
+// returned unchanged.
+func (c *Catalog) SelectVersion(key spec.ProviderSkillKey, pins map[string]string) spec.ProviderSkillKey {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	e, ok := c.byKey[key]
+	if !ok || !semver.IsValid(e.idx.Version) {
+		return key
+	}
+	if sel, ok := c.selectedVersionsLocked(pins)[recordName(e.idx)]; ok {
+		return sel
+	}
+	return key
+}
+
+// UnselectedVersions returns the keys of the versions of multi-version skills that are not selected (see
+// SelectVersion). The available-skills prompt does not advertise them.
+func (c *Catalog) UnselectedVersions(pins map[string]string) map[spec.ProviderSkillKey]struct{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	selected := c.selectedVersionsLocked(pins)
+	out := map[spec.ProviderSkillKey]struct{}{}
+	for k, e := range c.byKey {
+		if sel, ok := selected[recordName(e.idx)]; ok && sel != k && semver.IsValid(e.idx.Version) {
+			out[k] = struct{}{}
+		}
+	}
+	return out
+}
+
+// ResolveRequirement resolves a "requires" entry (a skill name) of the skill at from to a registered skill.
+//
+// Among skills with that name, one with the same provider type as from is preferred, then one registered
+// in the same parent location (e.g. a sibling directory). It returns spec.ErrSkillNotFound if no skill has
+// the name and spec.ErrInvalidArgument if several candidates remain.
+func (c *Catalog) ResolveRequirement(from spec.ProviderSkillKey, name string) (spec.ProviderSkillKey, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var fromLoc string
+	if e, ok := c.byKey[from]; ok {
+		fromLoc = e.def.Location
+	}
+	// Of a multi-version skill only the selected version is a candidate.
+	selected, versioned := c.selectedVersionsLocked(nil)[name]
+	best, bestScore, ties := spec.ProviderSkillKey{}, -1, 0
+	for k, e := range c.byKey {
+		if k == from || recordName(e.idx) != name || (versioned && k != selected) {
+			continue
+		}
+		score := 0
+		if k.Type == from.Type {
+			score += 2
+		}
+		if fromLoc != "" && parentLocation(e.def.Location) == parentLocation(fromLoc) {
+			score++
+		}
+		switch {
+		case score > bestScore:
+			best, bestScore, ties = k, score, 1
+		case score == bestScore:
+			ties++
+		}
+	}
+	switch {
+	case ties == 0:
+		return spec.ProviderSkillKey{}, fmt.Errorf("%w: no skill named %q is registered", spec.ErrSkillNotFound, name)
+	case ties > 1:
+		return spec.ProviderSkillKey{}, fmt.Errorf(
+			"%w: %d skills named %q match equally",
+			spec.ErrInvalidArgument,
+			ties,
+			name,
+		)
+	}
+	return best, nil
+}
+
 func (c *Catalog) EnsureBody(ctx context.Context, key spec.ProviderSkillKey) (string, error) {
 	if err := ctx.Err(); err != nil {
 		return "", err
@@ -309,44 +510,33 @@ type UserEntry struct {
 	Record spec.SkillRecord      // host-facing record (user def)
 }
 
-// ListUserEntries lists host-facing records, while preserving the canonical key for internal consumers.
+// ListUserEntries lists host-facing records sorted by name then location, while preserving the canonical key
+// for internal consumers.
 func (c *Catalog) ListUserEntries(f UserFilter) []UserEntry {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	out := make([]UserEntry, 0, len(c.byKey))
-	for k, e := range c.byKey {
-		if !f.match(e) {
-			continue
-		}
-		out = append(out, UserEntry{
-			Key:    k,
-			Record: skillRecordFrom(e.def, e.idx),
-		})
-	}
-	sort.Slice(out, func(i, j int) bool {
-		if out[i].Record.Def.Name == out[j].Record.Def.Name {
-			return out[i].Record.Def.Location < out[j].Record.Def.Location
-		}
-		return out[i].Record.Def.Name < out[j].Record.Def.Name
-	})
+	out, _ := c.ListUserPage(f, UserPageQuery{})
 	return out
 }
 
+// userRecord returns the host-facing record of e.
+func (e *entry) userRecord() spec.SkillRecord {
+	rec := skillRecordFrom(e.def, e.idx)
+	rec.AddedAt = e.addedAt
+	return rec
+}
+
 func skillRecordFrom(def spec.SkillDef, idx spec.ProviderSkillIndexRecord) spec.SkillRecord {
 	insert, _ := NormalizeSkillInsert(idx.Insert)
-	name := idx.Name
-	if name == "" {
-		name = idx.Key.Name
-	}
 	return spec.SkillRecord{
 		Def:            def,
-		Name:           name,
+		Name:           recordName(idx),
 		Description:    idx.Description,
 		DisplayName:    idx.DisplayName,
 		Insert:         insert,
+		Version:        idx.Version,
 		Arguments:      append([]spec.SkillArgument(nil), idx.Arguments...),
 		Tags:           append([]string(nil), idx.Tags...),
+		Requires:       append([]string(nil), idx.Requires...),
+		Conflicts:      append([]string(nil), idx.Conflicts...),
 		Resources:      cloneSkillResourceInfo(idx.Resources),
 		RawFrontmatter: idx.RawFrontmatter,
 		Warnings:       append([]string(nil), idx.Warnings...),
@@ -354,6 +544,30 @@ func skillRecordFrom(def spec.SkillDef, idx spec.ProviderSkillIndexRecord) spec.
 	}
 }
 
+// recordName is the SKILL.md name of a skill, falling back to the key name.
+func recordName(idx spec.ProviderSkillIndexRecord) string {
+	if idx.Name != "" {
+		return idx.Name
+	}
+	return idx.Key.Name
+}
+
+// parentLocation returns location without its last slash- or backslash-separated element.
+func parentLocation(location string) string {
+	location = strings.TrimRight(location, `/\`)
+	if i := strings.LastIndexAny(location, `/\`); i >= 0 {
+		return location[:i]
+	}
+	return ""
+}
+
+func sameResourceInfo(a, b spec.SkillResourceInfo) bool {
+	return a.HasResources == b.HasResources &&
+		a.TotalCount == b.TotalCount &&
+		a.MoreLocations == b.MoreLocations &&
+		slices.Equal(a.Locations, b.Locations)
+}
+
 func cloneSkillResourceInfo(in spec.SkillResourceInfo) spec.SkillResourceInfo {
 	in.Locations = append([]string(nil), in.Locations...)
 	return in
@@ -410,6 +624,60 @@ func (c *Catalog) recomputeLLMNamesLocked() {
 	}
 }
 
+// selectedVersionsLocked returns the selected key of every skill name registered with at least two distinct
+// semantic versions (see SelectVersion). Callers must hold c.mu.
+func (c *Catalog) selectedVersionsLocked(pins map[string]string) map[string]spec.ProviderSkillKey {
+	type versioned struct {
+		e *entry
+		v semver.Version
+	}
+	groups := map[string][]versioned{}
+	for _, e := range c.byKey {
+		if v, ok := semver.Parse(e.idx.Version); ok {
+			name := recordName(e.idx)
+			groups[name] = append(groups[name], versioned{e: e, v: v})
+		}
+	}
+
+	out := map[string]spec.ProviderSkillKey{}
+	for name, grp := range groups {
+		// Highest version first; equal versions are ordered deterministically by key.
+		slices.SortFunc(grp, func(a, b versioned) int {
+			return cmp.Or(
+				semver.Compare(b.v, a.v),
+				cmp.Compare(a.e.idx.Key.Type, b.e.idx.Key.Type),
+				cmp.Compare(a.e.idx.Key.Location, b.e.idx.Key.Location),
+			)
+		})
+		if semver.Compare(grp[0].v, grp[len(grp)-1].v) == 0 {
+			continue
+		}
+
+		sel := -1
+		for _, pin := range []string{pins[name], c.versionPins[name]} {
+			if pv, ok := semver.Parse(pin); ok && sel < 0 {
+				sel = slices.IndexFunc(grp, func(x versioned) bool { return semver.Compare(x.v, pv) == 0 })
+			}
+		}
+		if sel < 0 {
+			sel = max(slices.IndexFunc(grp, func(x versioned) bool { return len(x.v.Prerelease) == 0 }), 0)
+		}
+		out[name] = grp[sel].e.idx.Key
+	}
+	return out
+}
+
+// removeEntryLocked drops e from all indexes. Callers must recompute LLM names afterwards.
+func (c *Catalog) removeEntryLocked(e *entry) {
+	// Wake any waiters to avoid deadlocks if removal races EnsureBody.
+	if ch := e.bodyWait; ch != nil {
+		e.bodyWait = nil
+		close(ch)
+	}
+	delete(c.byKey, e.idx.Key)
+	delete(c.byDef, e.def)
+}
+
 func (c *Catalog) finishBodyLoad(key spec.ProviderSkillKey, ch chan struct{}, body string, err error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -440,6 +708,63 @@ func (c *Catalog) finishBodyLoad(key spec.ProviderSkillKey, ch chan struct{}, bo
 	e.bodyErr = nil
 }
 
+// indexDef runs the provider Index for def and validates the returned record identity.
+func (c *Catalog) indexDef(ctx context.Context, def spec.SkillDef) (spec.ProviderSkillIndexRecord, error) {
+	p, ok := c.providers.Provider(def.Type)
+	if !ok || p == nil {
+		return spec.ProviderSkillIndexRecord{}, errors.Join(
+			spec.ErrProviderNotFound,
+			fmt.Errorf("unknown provider type: %q", def.Type),
+		)
+	}
+
+	idx, err := p.Index(ctx, def)
+	if err != nil {
+		return spec.ProviderSkillIndexRecord{}, err
+	}
+
+	// Provider is allowed to canonicalize Location, but must not change Type/Name identity.
+	if idx.Key.Type != def.Type {
+		return spec.ProviderSkillIndexRecord{}, fmt.Errorf(
+			"%w: provider changed type from %q to %q",
+			spec.ErrInvalidArgument,
+			def.Type,
+			idx.Key.Type,
+		)
+	}
+	if idx.Key.Name != def.Name {
+		return spec.ProviderSkillIndexRecord{}, fmt.Errorf(
+			"%w: provider changed name from %q to %q",
+			spec.ErrInvalidArgument,
+			def.Name,
+			idx.Key.Name,
+		)
+	}
+
+	if strings.TrimSpace(idx.Key.Type) == "" ||
+		strings.TrimSpace(idx.Key.Name) == "" ||
+		strings.TrimSpace(idx.Key.Location) == "" {
+		return spec.ProviderSkillIndexRecord{}, fmt.Errorf("%w: provider returned invalid record key", spec.ErrInvalidArgument)
+	}
+
+	insert, ok := NormalizeSkillInsert(idx.Insert)
+	if !ok {
+		return spec.ProviderSkillIndexRecord{}, fmt.Errorf(
+			"%w: provider returned invalid insert value: %q",
+			spec.ErrInvalidArgument,
+			idx.Insert,
+		)
+	}
+	idx.Insert = insert
+
+	return idx, nil
+}
+
+// isMissingSkillErr reports whether a provider Index error means the skill no longer exists.
+func isMissingSkillErr(err error) bool {
+	return errors.Is(err, fs.ErrNotExist) || errors.Is(err, spec.ErrSkillNotFound)
+}
+
 func normHandle(h spec.SkillHandle) handleKey {
 	return handleKey{
 		Name:     strings.TrimSpace(h.Name),
@@ -3,6 +3,8 @@ package catalog
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io/fs"
 	"regexp"
 	"strings"
 	"sync"
@@ -717,3 +719,343 @@ func TestCatalog_EnsureBody_ProviderNotFoundIsCached(t *testing.T) {
 		t.Fatalf("expected 0 LoadBody calls (provider not found happens before LoadBody), got %d", got)
 	}
 }
+
+func TestCatalog_Refresh(t *testing.T) {
+	t.Parallel()
+
+	def := spec.SkillDef{Type: "t", Name: "n", Location: "/p"}
+
+	tests := []struct {
+		name string
+		// Index result served after the initial Add.
+		refreshFn  func(context.Context, spec.SkillDef) (spec.ProviderSkillIndexRecord, error)
+		wantStatus spec.SkillRefreshStatus
+		wantDigest string
+		wantKey    spec.ProviderSkillKey
+		wantBody   string
+		wantErrSub string
+	}{
+		{
+			name: "same digest is unchanged and keeps cached body",
+			refreshFn: func(ctx context.Context, d spec.SkillDef) (spec.ProviderSkillIndexRecord, error) {
+				return spec.ProviderSkillIndexRecord{Key: spec.ProviderSkillKey(d), Digest: "d1"}, nil
+			},
+			wantStatus: spec.SkillRefreshStatusUnchanged,
+			wantDigest: "d1",
+			wantKey:    spec.ProviderSkillKey(def),
+			wantBody:   "BODY:v1",
+		},
+		{
+			name: "new digest swaps record and invalidates body",
+			refreshFn: func(ctx context.Context, d spec.SkillDef) (spec.ProviderSkillIndexRecord, error) {
+				return spec.ProviderSkillIndexRecord{Key: spec.ProviderSkillKey(d), Digest: "d2"}, nil
+			},
+			wantStatus: spec.SkillRefreshStatusUpdated,
+			wantDigest: "d2",
+			wantKey:    spec.ProviderSkillKey(def),
+			wantBody:   "BODY:v2",
+		},
+		{
+			name: "re-canonicalized key is rekeyed",
+			refreshFn: func(ctx context.Context, d spec.SkillDef) (spec.ProviderSkillIndexRecord, error) {
+				return spec.ProviderSkillIndexRecord{
+					Key:    spec.ProviderSkillKey{Type: d.Type, Name: d.Name, Location: canonicalLocation},
+					Digest: "d1",
+				}, nil
+			},
+			wantStatus: spec.SkillRefreshStatusUpdated,
+			wantDigest: "d1",
+			wantKey:    spec.ProviderSkillKey{Type: "t", Name: "n", Location: canonicalLocation},
+			wantBody:   "BODY:v2",
+		},
+		{
+			name: "index error keeps previous entry",
+			refreshFn: func(ctx context.Context, d spec.SkillDef) (spec.ProviderSkillIndexRecord, error) {
+				return spec.ProviderSkillIndexRecord{}, errors.New("parse-failed")
+			},
+			wantStatus: spec.SkillRefreshStatusFailed,
+			wantDigest: "d1",
+			wantKey:    spec.ProviderSkillKey(def),
+			wantBody:   "BODY:v1",
+			wantErrSub: "parse-failed",
+		},
+		{
+			name: "missing skill is removed",
+			refreshFn: func(ctx context.Context, d spec.SkillDef) (spec.ProviderSkillIndexRecord, error) {
+				return spec.ProviderSkillIndexRecord{}, fmt.Errorf("open: %w", fs.ErrNotExist)
+			},
+			wantStatus: spec.SkillRefreshStatusRemoved,
+			wantDigest: "d1",
+			wantErrSub: "file does not exist",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				mu      sync.Mutex
+				indexFn = func(ctx context.Context, d spec.SkillDef) (spec.ProviderSkillIndexRecord, error) {
+					return spec.ProviderSkillIndexRecord{Key: spec.ProviderSkillKey(d), Digest: "d1"}, nil
+				}
+				bodyVer = "v1"
+			)
+			p := &testProvider{
+				typ: "t",
+				indexFn: func(ctx context.Context, d spec.SkillDef) (spec.ProviderSkillIndexRecord, error) {
+					mu.Lock()
+					fn := indexFn
+					mu.Unlock()
+					return fn(ctx, d)
+				},
+				loadBodyFn: func(ctx context.Context, k spec.ProviderSkillKey) (string, error) {
+					mu.Lock()
+					defer mu.Unlock()
+					return "BODY:" + bodyVer, nil
+				},
+			}
+			c := New(mapResolver{"t": p})
+			if _, err := c.Add(t.Context(), def); err != nil {
+				t.Fatalf("Add: %v", err)
+			}
+			oldKey, _ := c.ResolveDef(def)
+			if _, err := c.EnsureBody(t.Context(), oldKey); err != nil {
+				t.Fatalf("EnsureBody: %v", err)
+			}
+
+			mu.Lock()
+			indexFn = tt.refreshFn
+			bodyVer = "v2"
+			mu.Unlock()
+
+			res, err := c.Refresh(t.Context(), def)
+			if err != nil {
+				t.Fatalf("Refresh: %v", err)
+			}
+			if res.Status != tt.wantStatus {
+				t.Fatalf("status: got %q want %q (err=%v)", res.Status, tt.wantStatus, res.Err)
+			}
+			if res.Record.Digest != tt.wantDigest || res.PreviousDigest != "d1" {
+				t.Fatalf("digest: got %q prev %q, want %q prev d1", res.Record.Digest, res.PreviousDigest, tt.wantDigest)
+			}
+			if res.Record.Def != def {
+				t.Fatalf("record def leaked canonicalization: %+v", res.Record.Def)
+			}
+			if tt.wantErrSub == "" && res.Err != nil {
+				t.Fatalf("unexpected err: %v", res.Err)
+			}
+			if tt.wantErrSub != "" && (res.Err == nil || !strings.Contains(res.Err.Error(), tt.wantErrSub)) {
+				t.Fatalf("err: got %v want substring %q", res.Err, tt.wantErrSub)
+			}
+			if res.OldKey != oldKey {
+				t.Fatalf("OldKey: got %+v want %+v", res.OldKey, oldKey)
+			}
+
+			if tt.wantStatus == spec.SkillRefreshStatusRemoved {
+				if _, ok := c.ResolveDef(def); ok {
+					t.Fatalf("expected def to be removed from catalog")
+				}
+				if _, ok := c.GetIndex(oldKey); ok {
+					t.Fatalf("expected key to be removed from catalog")
+				}
+				return
+			}
+
+			gotKey, ok := c.ResolveDef(def)
+			if !ok || gotKey != tt.wantKey || res.NewKey != tt.wantKey {
+				t.Fatalf("key: got %+v (res %+v) want %+v", gotKey, res.NewKey, tt.wantKey)
+			}
+			h, ok := c.HandleForKey(gotKey)
+			if !ok || h.Name != def.Name || h.Location != def.Location {
+				t.Fatalf("handle: got %+v ok=%v", h, ok)
+			}
+			if k, ok := c.ResolveHandle(h); !ok || k != gotKey {
+				t.Fatalf("ResolveHandle: got %+v ok=%v", k, ok)
+			}
+			body, err := c.EnsureBody(t.Context(), gotKey)
+			if err != nil {
+				t.Fatalf("EnsureBody after refresh: %v", err)
+			}
+			if body != tt.wantBody {
+				t.Fatalf("body: got %q want %q", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestCatalog_Refresh_UnknownDefAndCanceledContext(t *testing.T) {
+	t.Parallel()
+
+	c := New(mapResolver{"t": &testProvider{typ: "t"}})
+
+	if _, err := c.Refresh(t.Context(), spec.SkillDef{Type: "t", Name: "n", Location: "/p"}); !errors.Is(
+		err,
+		spec.ErrSkillNotFound,
+	) {
+		t.Fatalf("expected ErrSkillNotFound, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if _, err := c.Refresh(ctx, spec.SkillDef{Type: "t", Name: "n", Location: "/p"}); !errors.Is(
+		err,
+		context.Canceled,
+	) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestCatalog_ResolveRequirement_PrefersSameTypeThenSiblingLocation(t *testing.T) {
+	t.Parallel()
+
+	c := New(mapResolver{"a": &testProvider{typ: "a"}, "b": &testProvider{typ: "b"}})
+	from := spec.SkillDef{Type: "a", Name: "from", Location: "/skills/from"}
+	sibling := spec.SkillDef{Type: "a", Name: "dep", Location: "/skills/dep"}
+	far := spec.SkillDef{Type: "a", Name: "dep", Location: "/other/dep"}
+	otherType := spec.SkillDef{Type: "b", Name: "dep", Location: "/skills/dep2"}
+	for _, def := range []spec.SkillDef{from, sibling, far, otherType} {
+		if _, err := c.Add(t.Context(), def); err != nil {
+			t.Fatalf("Add %+v: %v", def, err)
+		}
+	}
+	fromKey, _ := c.ResolveDef(from)
+	wantKey, _ := c.ResolveDef(sibling)
+
+	got, err := c.ResolveRequirement(fromKey, "dep")
+	if err != nil || got != wantKey {
+		t.Fatalf("ResolveRequirement = %+v, %v; want %+v", got, err, wantKey)
+	}
+
+	if _, err := c.ResolveRequirement(fromKey, "missing"); !errors.Is(err, spec.ErrSkillNotFound) {
+		t.Fatalf("missing: err = %v, want ErrSkillNotFound", err)
+	}
+
+	// Without a same-type sibling, the two remaining same-type candidates are ambiguous.
+	if _, _, ok := c.Remove(sibling); !ok {
+		t.Fatalf("Remove: not found")
+	}
+	if _, err := c.Add(t.Context(), spec.SkillDef{Type: "a", Name: "dep", Location: "/third/dep"}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if _, err := c.ResolveRequirement(fromKey, "dep"); !errors.Is(err, spec.ErrInvalidArgument) {
+		t.Fatalf("ambiguous: err = %v, want ErrInvalidArgument", err)
+	}
+}
+
+func TestCatalog_SelectVersion(t *testing.T) {
+	t.Parallel()
+
+	versions := map[string]string{"/v1": "1.0.0", "/v2": "2.0.0", "/v3": "3.0.0-beta.1", "/plain": ""}
+	p := &testProvider{typ: "t"}
+	p.indexFn = func(_ context.Context, def spec.SkillDef) (spec.ProviderSkillIndexRecord, error) {
+		return spec.ProviderSkillIndexRecord{
+			Key:         spec.ProviderSkillKey(def),
+			Description: "desc",
+			Version:     versions[def.Location],
+			Digest:      "digest",
+		}, nil
+	}
+	c := New(mapResolver{"t": p})
+	keys := map[string]spec.ProviderSkillKey{}
+	for loc := range versions {
+		def := spec.SkillDef{Type: "t", Name: "tool", Location: loc}
+		if _, err := c.Add(t.Context(), def); err != nil {
+			t.Fatalf("Add %+v: %v", def, err)
+		}
+		keys[loc], _ = c.ResolveDef(def)
+	}
+	other := spec.SkillDef{Type: "t", Name: "other", Location: "/other"}
+	if _, err := c.Add(t.Context(), other); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	otherKey, _ := c.ResolveDef(other)
+
+	// The highest release wins over a higher pre-release; unversioned records are left alone.
+	for _, loc := range []string{"/v1", "/v2", "/v3"} {
+		if got := c.SelectVersion(keys[loc], nil); got != keys["/v2"] {
+			t.Fatalf("SelectVersion(%s) = %+v, want /v2", loc, got)
+		}
+	}
+	if got := c.SelectVersion(keys["/plain"], nil); got != keys["/plain"] {
+		t.Fatalf("SelectVersion(/plain) = %+v", got)
+	}
+	if got, err := c.ResolveRequirement(otherKey, "tool"); err != nil || got != keys["/v2"] {
+		t.Fatalf("ResolveRequirement = %+v, %v; want /v2", got, err)
+	}
+
+	// Catalog pins apply unless overridden; pins naming unregistered versions are ignored.
+	c.SetVersionPins(map[string]string{"tool": "1.0.0"})
+	if got := c.SelectVersion(keys["/v2"], nil); got != keys["/v1"] {
+		t.Fatalf("catalog pin: SelectVersion = %+v, want /v1", got)
+	}
+	if got := c.SelectVersion(keys["/v1"], map[string]string{"tool": "3.0.0-beta.1"}); got != keys["/v3"] {
+		t.Fatalf("session pin: SelectVersion = %+v, want /v3", got)
+	}
+	if got := c.SelectVersion(keys["/v1"], map[string]string{"tool": "9.9.9"}); got != keys["/v1"] {
+		t.Fatalf("unknown pin: SelectVersion = %+v, want /v1", got)
+	}
+
+	unselected := c.UnselectedVersions(map[string]string{"tool": "2.0.0"})
+	if len(unselected) != 2 {
+		t.Fatalf("UnselectedVersions = %+v, want /v1 and /v3", unselected)
+	}
+	for _, loc := range []string{"/v1", "/v3"} {
+		if _, ok := unselected[keys[loc]]; !ok {
+			t.Fatalf("UnselectedVersions misses %s", loc)
+		}
+	}
+}
+
+func TestCatalog_Search_RanksByBM25(t *testing.T) {
+	t.Parallel()
+
+	records := map[string]spec.ProviderSkillIndexRecord{
+		"pdf-tools":  {Description: "Merge and split documents.", Tags: []string{"documents"}},
+		"doc-writer": {Description: "Write documents; can export a PDF at the end."},
+		"csv-tools":  {Description: "Clean CSV files.", Tags: []string{"pdf"}},
+		"unrelated":  {Description: "Nothing to see here."},
+		"user-msg":   {Description: "PDF prompt template.", Insert: spec.SkillInsertUserMessage},
+	}
+	p := &testProvider{typ: "t"}
+	p.indexFn = func(_ context.Context, def spec.SkillDef) (spec.ProviderSkillIndexRecord, error) {
+		rec := records[def.Name]
+		rec.Key = spec.ProviderSkillKey(def)
+		return rec, nil
+	}
+	c := New(mapResolver{"t": p})
+	for name := range records {
+		if _, err := c.Add(t.Context(), spec.SkillDef{Type: "t", Name: name, Location: "/" + name}); err != nil {
+			t.Fatalf("Add %s: %v", name, err)
+		}
+	}
+
+	names := func(hits []SearchHit) string {
+		out := make([]string, 0, len(hits))
+		for _, h := range hits {
+			if h.Score <= 0 {
+				t.Fatalf("hit %s has score %v", h.Record.Key.Name, h.Score)
+			}
+			out = append(out, h.Record.Key.Name)
+		}
+		return strings.Join(out, ",")
+	}
+
+	// Name matches outrank tag matches, which outrank description matches; the filter applies.
+	f := PromptFilter{Inserts: []spec.SkillInsert{spec.SkillInsertInstructions}}
+	if got := names(c.Search("PDF", f)); got != "pdf-tools,csv-tools,doc-writer" {
+		t.Fatalf("Search(PDF) = %s", got)
+	}
+	if got := names(c.Search("pdf", PromptFilter{})); !strings.Contains(got, "user-msg") {
+		t.Fatalf("Search without insert filter = %s, want user-msg included", got)
+	}
+	if got := names(c.Search("split documents", f)); got != "pdf-tools,doc-writer" {
+		t.Fatalf("Search(split documents) = %s", got)
+	}
+	if hits := c.Search("missing", f); len(hits) != 0 {
+		t.Fatalf("Search(missing) = %+v, want none", hits)
+	}
+	if hits := c.Search(" -- ", f); hits != nil {
+		t.Fatalf("Search without terms = %+v, want nil", hits)
+	}
+}
@@ -0,0 +1,118 @@
+package catalog
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+// UserPageQuery selects a sorted window of host/lifecycle records. The zero value lists every record sorted by
+// name.
+type UserPageQuery struct {
+	// SortBy defaults to spec.SkillSortByName. Ties are broken by def name, location and type, so the order is
+	// total and stable across calls.
+	SortBy     spec.SkillSortKey
+	Descending bool
+
+	// After, when non-nil, starts the page right after this position (keyset pagination): records added or
+	// removed meanwhile never shift the remaining pages.
+	After *PagePosition
+
+	// Limit caps the page size (<= 0 means no limit).
+	Limit int
+
+	// Lite omits resource locations and raw frontmatter from the returned records.
+	Lite bool
+
+	// Keep optionally filters by canonical key (e.g. session activity). It is called under the catalog lock
+	// and must not call back into the catalog.
+	Keep func(spec.ProviderSkillKey) bool
+}
+
+// PagePosition is the sort position of a listed record.
+type PagePosition struct {
+	Def     spec.SkillDef
+	AddedAt time.Time
+}
+
+// Position returns the sort position of a listed record.
+func (e UserEntry) Position() PagePosition {
+	return PagePosition{Def: e.Record.Def, AddedAt: e.Record.AddedAt}
+}
+
+// ListUserPage lists at most q.Limit host-facing records matching f in q's order, and reports whether more
+// records follow. Only the returned page is copied out of the catalog.
+func (c *Catalog) ListUserPage(f UserFilter, q UserPageQuery) (page []UserEntry, more bool) {
+	compare := func(a, b PagePosition) int {
+		if q.Descending {
+			return comparePositions(q.SortBy, b, a)
+		}
+		return comparePositions(q.SortBy, a, b)
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	matched := make([]*entry, 0, len(c.byKey))
+	for k, e := range c.byKey {
+		if !f.match(e) || (q.Keep != nil && !q.Keep(k)) {
+			continue
+		}
+		if q.After != nil && compare(e.position(), *q.After) <= 0 {
+			continue
+		}
+		matched = append(matched, e)
+	}
+	slices.SortFunc(matched, func(a, b *entry) int { return compare(a.position(), b.position()) })
+	if q.Limit > 0 && len(matched) > q.Limit {
+		matched, more = matched[:q.Limit], true
+	}
+
+	page = make([]UserEntry, 0, len(matched))
+	for _, e := range matched {
+		rec := e.userRecord
+		if q.Lite {
+			rec = e.liteRecord
+		}
+		page = append(page, UserEntry{Key: e.idx.Key, Record: rec()})
+	}
+	return page, more
+}
+
+func (e *entry) position() PagePosition {
+	return PagePosition{Def: e.def, AddedAt: e.addedAt}
+}
+
+func comparePositions(by spec.SkillSortKey, a, b PagePosition) int {
+	var primary int
+	switch by {
+	case spec.SkillSortByLocation:
+		primary = cmp.Compare(a.Def.Location, b.Def.Location)
+	case spec.SkillSortByType:
+		primary = cmp.Compare(a.Def.Type, b.Def.Type)
+	case spec.SkillSortByAddedAt:
+		primary = a.AddedAt.Compare(b.AddedAt)
+	default:
+		// Name is the primary tie-breaker below.
+	}
+	return cmp.Or(
+		primary,
+		cmp.Compare(a.Def.Name, b.Def.Name),
+		cmp.Compare(a.Def.Location, b.Def.Location),
+		cmp.Compare(a.Def.Type, b.Def.Type),
+	)
+}
+
+// liteRecord is userRecord without the potentially large parts: resource locations (counts are kept) and raw
+// frontmatter. They are dropped before copying.
+func (e *entry) liteRecord() spec.SkillRecord {
+	idx := e.idx
+	idx.Resources.MoreLocations = idx.Resources.MoreLocations || len(idx.Resources.Locations) > 0
+	idx.Resources.Locations = nil
+	idx.RawFrontmatter = nil
+	rec := skillRecordFrom(e.def, idx)
+	rec.AddedAt = e.addedAt
+	return rec
+}
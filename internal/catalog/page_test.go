@@ -0,0 +1,100 @@
+package catalog
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+func TestCatalog_ListUserPage(t *testing.T) {
+	t.Parallel()
+
+	p := &testProvider{
+		typ: "t",
+		indexFn: func(_ context.Context, def spec.SkillDef) (spec.ProviderSkillIndexRecord, error) {
+			return spec.ProviderSkillIndexRecord{
+				Key:            spec.ProviderSkillKey(def),
+				Description:    "d",
+				RawFrontmatter: map[string]any{"name": def.Name},
+				Resources:      spec.SkillResourceInfo{HasResources: true, TotalCount: 1, Locations: []string{"a.txt"}},
+			}, nil
+		},
+	}
+	c := New(mapResolver{"t": p})
+
+	// Registration order differs from name and location order.
+	defs := []spec.SkillDef{
+		{Type: "t", Name: "c", Location: "/1"},
+		{Type: "t", Name: "a", Location: "/3"},
+		{Type: "t", Name: "b", Location: "/2"},
+		{Type: "t", Name: "a", Location: "/0"},
+	}
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, d := range defs {
+		if _, err := c.Add(t.Context(), d); err != nil {
+			t.Fatalf("Add(%+v): %v", d, err)
+		}
+		c.byKey[spec.ProviderSkillKey(d)].addedAt = base.Add(time.Duration(i) * time.Minute)
+	}
+
+	// pages walks the whole listing two records at a time.
+	pages := func(q UserPageQuery) []string {
+		t.Helper()
+		q.Limit = 2
+		var got []string
+		for range len(defs) {
+			page, more := c.ListUserPage(UserFilter{}, q)
+			for _, e := range page {
+				got = append(got, e.Record.Def.Name+"@"+e.Record.Def.Location)
+			}
+			if !more {
+				return got
+			}
+			pos := page[len(page)-1].Position()
+			q.After = &pos
+		}
+		t.Fatalf("pagination did not terminate: %v", got)
+		return nil
+	}
+
+	for _, tc := range []struct {
+		q    UserPageQuery
+		want []string
+	}{
+		{q: UserPageQuery{}, want: []string{"a@/0", "a@/3", "b@/2", "c@/1"}},
+		{q: UserPageQuery{Descending: true}, want: []string{"c@/1", "b@/2", "a@/3", "a@/0"}},
+		{q: UserPageQuery{SortBy: spec.SkillSortByLocation}, want: []string{"a@/0", "c@/1", "b@/2", "a@/3"}},
+		{q: UserPageQuery{SortBy: spec.SkillSortByAddedAt}, want: []string{"c@/1", "a@/3", "b@/2", "a@/0"}},
+		{
+			q:    UserPageQuery{SortBy: spec.SkillSortByAddedAt, Descending: true},
+			want: []string{"a@/0", "b@/2", "a@/3", "c@/1"},
+		},
+	} {
+		if got := pages(tc.q); !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("ListUserPage(%+v) = %v, want %v", tc.q, got, tc.want)
+		}
+	}
+
+	// Keep filters by canonical key before paging.
+	keep := func(k spec.ProviderSkillKey) bool { return k.Name == "a" }
+	page, more := c.ListUserPage(UserFilter{}, UserPageQuery{Keep: keep, Limit: 2})
+	if len(page) != 2 || more {
+		t.Fatalf("Keep: got %d entries (more=%v), want 2 and no more", len(page), more)
+	}
+
+	// Lite drops resource locations and raw frontmatter but keeps resource counts.
+	page, _ = c.ListUserPage(UserFilter{}, UserPageQuery{Lite: true, Limit: 1})
+	rec := page[0].Record
+	if rec.RawFrontmatter != nil || rec.Resources.Locations != nil {
+		t.Fatalf("lite record kept heavy fields: %+v", rec)
+	}
+	if !rec.Resources.HasResources || rec.Resources.TotalCount != 1 || !rec.Resources.MoreLocations {
+		t.Fatalf("lite record lost resource counts: %+v", rec.Resources)
+	}
+	if !rec.AddedAt.Equal(base.Add(3 * time.Minute)) {
+		t.Fatalf("AddedAt = %v", rec.AddedAt)
+	}
+}
@@ -15,6 +15,34 @@ type PromptFilter struct {
 	Inserts []spec.SkillInsert
 	// AllowDefs restricts to an explicit allowlist of host/lifecycle definitions. Empty means "all".
 	AllowDefs []spec.SkillDef
+
+	Tags TagFilter
+}
+
+// TagFilter matches skills by their SKILL.md tags (exact, case-sensitive). The zero value matches everything.
+type TagFilter struct {
+	// Include restricts to skills with any (or, with All, every) of these tags. Empty means "all".
+	Include []string
+	All     bool
+
+	// Exclude drops skills with any of these tags.
+	Exclude []string
+}
+
+func (f TagFilter) match(tags []string) bool {
+	for _, t := range f.Exclude {
+		if slices.Contains(tags, t) {
+			return false
+		}
+	}
+	if len(f.Include) == 0 {
+		return true
+	}
+	has := func(t string) bool { return slices.Contains(tags, t) }
+	if f.All {
+		return !slices.ContainsFunc(f.Include, func(t string) bool { return !has(t) })
+	}
+	return slices.ContainsFunc(f.Include, has)
 }
 
 func (f PromptFilter) match(e *entry) bool {
@@ -24,6 +52,9 @@ func (f PromptFilter) match(e *entry) bool {
 	if len(f.AllowDefs) > 0 && !slices.Contains(f.AllowDefs, e.def) {
 		return false
 	}
+	if !f.Tags.match(e.idx.Tags) {
+		return false
+	}
 
 	if len(f.Types) > 0 {
 		ok := slices.Contains(f.Types, e.idx.Key.Type)
@@ -62,6 +93,7 @@ type UserFilter struct {
 	LocationPrefix string
 	AllowDefs      []spec.SkillDef
 	Inserts        []spec.SkillInsert
+	Tags           TagFilter
 }
 
 func (f UserFilter) match(e *entry) bool {
@@ -71,6 +103,9 @@ func (f UserFilter) match(e *entry) bool {
 	if len(f.AllowDefs) > 0 && !slices.Contains(f.AllowDefs, e.def) {
 		return false
 	}
+	if !f.Tags.match(e.idx.Tags) {
+		return false
+	}
 	if len(f.Types) > 0 && !slices.Contains(f.Types, e.idx.Key.Type) {
 		return false
 	}
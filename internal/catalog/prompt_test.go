@@ -163,3 +163,31 @@ func TestCatalog_ListUserEntries_FiltersAndSorts(t *testing.T) {
 		t.Fatalf("unexpected allowlist result: %+v", allowed)
 	}
 }
+
+func TestTagFilter_Match(t *testing.T) {
+	t.Parallel()
+
+	tags := []string{"python", "testing"}
+	tests := []struct {
+		name string
+		f    TagFilter
+		want bool
+	}{
+		{name: "zero value", want: true},
+		{name: "any of", f: TagFilter{Include: []string{"go", "python"}}, want: true},
+		{name: "any of none", f: TagFilter{Include: []string{"go", "rust"}}, want: false},
+		{name: "all of", f: TagFilter{Include: []string{"python", "testing"}, All: true}, want: true},
+		{name: "all of missing one", f: TagFilter{Include: []string{"python", "go"}, All: true}, want: false},
+		{name: "exclude", f: TagFilter{Include: []string{"python"}, Exclude: []string{"testing"}}, want: false},
+		{name: "exclude other", f: TagFilter{Exclude: []string{"experimental"}}, want: true},
+		{name: "case sensitive", f: TagFilter{Include: []string{"Python"}}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.f.match(tags); got != tt.want {
+				t.Fatalf("match(%v) = %v, want %v", tags, got, tt.want)
+			}
+		})
+	}
+}
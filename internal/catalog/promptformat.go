@@ -0,0 +1,210 @@
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+// SkillsPromptDoc is the content of a skills prompt. Nil sections are omitted.
+type SkillsPromptDoc struct {
+	Available *AvailableSkillsSection
+	Active    *ActiveSkillsSection
+}
+
+// AvailableSkillsSection lists the skills the model may load, rendered in order.
+type AvailableSkillsSection struct {
+	Items []AvailableSkillItem
+
+	// Total is the number of available skills. When it exceeds len(Items) (search mode), the section tells the
+	// model to find the others with skills-search.
+	Total int
+}
+
+// ActiveSkillsSection lists the active skills with their bodies, rendered in order.
+type ActiveSkillsSection struct {
+	Items []ActiveSkillItem
+}
+
+// RenderSkillsPrompt renders doc in format. A document with one section renders as that section alone; with
+// both, the sections are wrapped in a root (text: <<<SKILLS_PROMPT>>>, JSON: one object, XML: <skills_prompt>).
+//
+// User-controlled names, descriptions and bodies are escaped so they cannot break out of their section: text
+// bodies get delimiter lines escaped, JSON strings are encoded with HTML-sensitive characters escaped, and XML
+// character data has markup characters escaped and invalid characters replaced.
+func RenderSkillsPrompt(format spec.SkillsPromptFormat, doc SkillsPromptDoc) (string, error) {
+	switch format {
+	case spec.SkillsPromptFormatText, "":
+		return textSkillsPrompt(doc), nil
+	case spec.SkillsPromptFormatJSON:
+		return jsonSkillsPrompt(doc)
+	case spec.SkillsPromptFormatXML:
+		return xmlSkillsPrompt(doc), nil
+	default:
+		return "", fmt.Errorf("%w: invalid prompt format %q", spec.ErrInvalidArgument, format)
+	}
+}
+
+func textSkillsPrompt(doc SkillsPromptDoc) string {
+	var available, active string
+	if a := doc.Available; a != nil {
+		available = availableSkillsPrompt(a.Items, searchNote(len(a.Items), a.Total))
+	}
+	if doc.Active != nil {
+		active = ActiveSkillsPrompt(doc.Active.Items)
+	}
+	switch {
+	case active == "":
+		return available
+	case available == "":
+		return active
+	default:
+		return wrapSkillsPrompt(available, active)
+	}
+}
+
+type skillsPromptJSON struct {
+	AvailableSkills *availableSkillsJSON `json:"available_skills,omitempty"`
+	ActiveSkills    *activeSkillsJSON    `json:"active_skills,omitempty"`
+}
+
+type availableSkillsJSON struct {
+	Note   string               `json:"note,omitempty"`
+	Skills []availableSkillJSON `json:"skills"`
+}
+
+type availableSkillJSON struct {
+	Name        string `json:"name"`
+	Location    string `json:"location,omitempty"`
+	Version     string `json:"version,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type activeSkillsJSON struct {
+	Skills []activeSkillJSON `json:"skills"`
+}
+
+type activeSkillJSON struct {
+	Name string `json:"name"`
+	Body string `json:"body"`
+}
+
+func jsonSkillsPrompt(doc SkillsPromptDoc) (string, error) {
+	var out skillsPromptJSON
+	if a := doc.Available; a != nil {
+		out.AvailableSkills = &availableSkillsJSON{
+			Note:   searchNote(len(a.Items), a.Total),
+			Skills: make([]availableSkillJSON, 0, len(a.Items)),
+		}
+		for _, it := range a.Items {
+			out.AvailableSkills.Skills = append(out.AvailableSkills.Skills, availableSkillJSON{
+				Name:        trimInline(it.Name),
+				Location:    trimInline(it.Location),
+				Version:     trimInline(it.Version),
+				Description: trimInline(it.Description),
+			})
+		}
+	}
+	if doc.Active != nil {
+		out.ActiveSkills = &activeSkillsJSON{Skills: make([]activeSkillJSON, 0, len(doc.Active.Items))}
+		for _, it := range doc.Active.Items {
+			out.ActiveSkills.Skills = append(out.ActiveSkills.Skills, activeSkillJSON{
+				Name: trimInline(it.Name),
+				Body: trimTrailingNewlines(it.Body),
+			})
+		}
+	}
+	// Marshal escapes <, > and & too, so values cannot close tags around the JSON block either.
+	b, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func xmlSkillsPrompt(doc SkillsPromptDoc) string {
+	var sb strings.Builder
+	both := doc.Available != nil && doc.Active != nil
+	if both {
+		sb.WriteString("<skills_prompt>\n")
+	}
+	if a := doc.Available; a != nil {
+		sb.WriteString("<available_skills>\n")
+		writeXMLElement(&sb, "note", searchNote(len(a.Items), a.Total))
+		for _, it := range a.Items {
+			sb.WriteString("<skill>\n")
+			writeXMLElement(&sb, "name", trimInline(it.Name))
+			writeXMLElement(&sb, "location", trimInline(it.Location))
+			writeXMLElement(&sb, "version", trimInline(it.Version))
+			writeXMLElement(&sb, "description", trimInline(it.Description))
+			sb.WriteString("</skill>\n")
+		}
+		sb.WriteString("</available_skills>")
+	}
+	if doc.Active != nil {
+		if doc.Available != nil {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString("<active_skills>\n")
+		for _, it := range doc.Active.Items {
+			sb.WriteString("<skill>\n")
+			writeXMLElement(&sb, "name", trimInline(it.Name))
+			// The body keeps its line breaks; it starts on its own line for readability.
+			sb.WriteString("<body>\n")
+			if body := trimTrailingNewlines(it.Body); body != "" {
+				sb.WriteString(escapeXMLText(body))
+				sb.WriteByte('\n')
+			}
+			sb.WriteString("</body>\n")
+			sb.WriteString("</skill>\n")
+		}
+		sb.WriteString("</active_skills>")
+	}
+	if both {
+		sb.WriteString("\n</skills_prompt>")
+	}
+	return sb.String()
+}
+
+// writeXMLElement writes <tag>text</tag> on its own line, or nothing when text is empty.
+func writeXMLElement(sb *strings.Builder, tag, text string) {
+	if text == "" {
+		return
+	}
+	sb.WriteString("<" + tag + ">")
+	sb.WriteString(escapeXMLText(text))
+	sb.WriteString("</" + tag + ">\n")
+}
+
+// escapeXMLText escapes s as XML character data. Unlike xml.EscapeText it keeps line breaks and tabs as is,
+// which keeps skill bodies readable; invalid UTF-8 and characters XML does not allow become U+FFFD.
+func escapeXMLText(s string) string {
+	var sb strings.Builder
+	sb.Grow(len(s))
+	for _, r := range s {
+		switch {
+		case r == '&':
+			sb.WriteString("&amp;")
+		case r == '<':
+			sb.WriteString("&lt;")
+		case r == '>':
+			sb.WriteString("&gt;")
+		case !isXMLChar(r):
+			sb.WriteRune(utf8.RuneError)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// isXMLChar reports whether r is in the XML 1.0 Char production.
+func isXMLChar(r rune) bool {
+	return r == '\t' || r == '\n' || r == '\r' ||
+		(r >= 0x20 && r <= 0xD7FF) ||
+		(r >= 0xE000 && r <= 0xFFFD) ||
+		(r >= 0x10000 && r <= 0x10FFFF)
+}
@@ -1,6 +1,7 @@
 package catalog
 
 import (
+	"fmt"
 	"sort"
 	"strings"
 
@@ -8,6 +9,8 @@ import (
 )
 
 const (
+	skillsPromptStart            = "<<<SKILLS_PROMPT>>>"
+	skillsPromptEnd              = "<<<END_SKILLS_PROMPT>>>"
 	availableSkillsStart         = "<<<AVAILABLE_SKILLS>>>"
 	availableSkillsEnd           = "<<<END_AVAILABLE_SKILLS>>>"
 	activeSkillsStart            = "<<<ACTIVE_SKILLS>>>"
@@ -20,6 +23,7 @@ type AvailableSkillItem struct {
 	Name        string
 	Description string
 	Location    string
+	Version     string
 	Resources   spec.SkillResourceInfo
 }
 
@@ -37,18 +41,40 @@ func AvailableSkillsPrompt(items []AvailableSkillItem) string {
 		}
 		return sorted[i].Name < sorted[j].Name
 	})
+	return availableSkillsPrompt(sorted, "")
+}
+
+// searchNote tells the model how to find the available skills left out of a partial list, if any.
+func searchNote(shown, total int) string {
+	if total <= shown {
+		return ""
+	}
+	return fmt.Sprintf(
+		"Showing %d of %d available skills. Call skills-search with keywords to find the others.",
+		shown,
+		total,
+	)
+}
 
+func availableSkillsPrompt(items []AvailableSkillItem, note string) string {
 	var sb strings.Builder
 	sb.WriteString(availableSkillsStart)
 	sb.WriteByte('\n')
 
-	if len(sorted) == 0 {
-		sb.WriteString("(none)\n")
+	if note != "" {
+		sb.WriteString(note)
+		sb.WriteByte('\n')
+	}
+
+	if len(items) == 0 {
+		if note == "" {
+			sb.WriteString("(none)\n")
+		}
 		sb.WriteString(availableSkillsEnd)
 		return sb.String()
 	}
 
-	for idx, it := range sorted {
+	for idx, it := range items {
 		if idx != 0 {
 			sb.WriteString(nextAvailableSkillsSeparator + "\n")
 		}
@@ -63,6 +89,12 @@ func AvailableSkillsPrompt(items []AvailableSkillItem) string {
 			sb.WriteByte('\n')
 		}
 
+		if it.Version != "" {
+			sb.WriteString("version: ")
+			sb.WriteString(trimInline(it.Version))
+			sb.WriteByte('\n')
+		}
+
 		if it.Description != "" {
 			sb.WriteString("description: ")
 			sb.WriteString(trimInline(it.Description))
@@ -96,7 +128,7 @@ func ActiveSkillsPrompt(items []ActiveSkillItem) string {
 
 		sb.WriteString("body:\n")
 
-		body := trimTrailingNewlines(it.Body)
+		body := escapeTextBody(trimTrailingNewlines(it.Body))
 		if body != "" {
 			sb.WriteString(body)
 			sb.WriteByte('\n')
@@ -118,3 +150,42 @@ func trimInline(s string) string {
 func trimTrailingNewlines(s string) string {
 	return strings.TrimRight(s, "\r\n")
 }
+
+// wrapSkillsPrompt wraps text sections into one skills prompt document, skipping empty sections.
+func wrapSkillsPrompt(parts ...string) string {
+	var b strings.Builder
+	b.WriteString(skillsPromptStart)
+	wrote := false
+	for _, p := range parts {
+		if strings.TrimSpace(p) == "" {
+			continue
+		}
+		wrote = true
+		b.WriteByte('\n')
+		b.WriteString(p)
+	}
+	if wrote {
+		b.WriteByte('\n')
+	}
+	b.WriteString(skillsPromptEnd)
+	return b.String()
+}
+
+// escapeTextBody keeps a skill body from closing or splitting the prompt sections: a body line consisting of
+// a section delimiter gets a backslash prepended (as does one already backslash-escaped, so the escaping is
+// unambiguous).
+func escapeTextBody(body string) string {
+	if !strings.Contains(body, "<<<") && !strings.Contains(body, nextActiveSkillsSeparator) {
+		return body
+	}
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		marker := strings.TrimLeft(strings.TrimSpace(line), `\`)
+		switch marker {
+		case skillsPromptStart, skillsPromptEnd, availableSkillsStart, availableSkillsEnd,
+			activeSkillsStart, activeSkillsEnd, nextActiveSkillsSeparator:
+			lines[i] = `\` + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,136 @@
+package catalog
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+// BM25 parameters and per-field term weights (a simplified BM25F: a name match counts more than a tag match,
+// which counts more than a description match).
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	searchWeightName        = 3
+	searchWeightTag         = 2
+	searchWeightDescription = 1
+)
+
+// SearchHit is a search result: an INTERNAL record, its host/lifecycle definition and its relevance score
+// (> 0).
+type SearchHit struct {
+	Record  spec.ProviderSkillIndexRecord
+	Def     spec.SkillDef
+	AddedAt time.Time
+	Score   float64
+}
+
+// UserRecord returns the host-facing record of the hit.
+func (h SearchHit) UserRecord() spec.SkillRecord {
+	rec := skillRecordFrom(h.Def, h.Record)
+	rec.AddedAt = h.AddedAt
+	return rec
+}
+
+// Search ranks the records matching f by lexical relevance to query, scored with BM25 over the skill name,
+// tags and description. Records sharing no term with the query are omitted. Hits are sorted by descending
+// score, then by name and location.
+//
+// The index is built per call from the current catalog, so it never goes stale after adds, refreshes or
+// removals.
+func (c *Catalog) Search(query string, f PromptFilter) []SearchHit {
+	terms := uniqueSearchTerms(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	c.mu.RLock()
+	type doc struct {
+		idx     spec.ProviderSkillIndexRecord
+		def     spec.SkillDef
+		addedAt time.Time
+		tf      map[string]float64
+		len     float64
+	}
+	docs := make([]doc, 0, len(c.byKey))
+	var totalLen float64
+	for _, e := range c.byKey {
+		if !f.match(e) {
+			continue
+		}
+		d := doc{idx: e.idx, def: e.def, addedAt: e.addedAt, tf: map[string]float64{}}
+		add := func(text string, weight float64) {
+			for _, t := range searchTerms(text) {
+				d.tf[t] += weight
+				d.len += weight
+			}
+		}
+		add(recordName(e.idx), searchWeightName)
+		for _, tag := range e.idx.Tags {
+			add(tag, searchWeightTag)
+		}
+		add(e.idx.Description, searchWeightDescription)
+		totalLen += d.len
+		docs = append(docs, d)
+	}
+	c.mu.RUnlock()
+
+	if len(docs) == 0 {
+		return nil
+	}
+	avgLen := max(totalLen/float64(len(docs)), 1)
+
+	df := make(map[string]int, len(terms))
+	for _, d := range docs {
+		for _, t := range terms {
+			if d.tf[t] > 0 {
+				df[t]++
+			}
+		}
+	}
+
+	n := float64(len(docs))
+	var out []SearchHit
+	for _, d := range docs {
+		var score float64
+		for _, t := range terms {
+			tf := d.tf[t]
+			if tf == 0 {
+				continue
+			}
+			idf := math.Log(1 + (n-float64(df[t])+0.5)/(float64(df[t])+0.5))
+			score += idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*d.len/avgLen))
+		}
+		if score > 0 {
+			out = append(out, SearchHit{Record: d.idx, Def: d.def, AddedAt: d.addedAt, Score: score})
+		}
+	}
+	slices.SortFunc(out, func(a, b SearchHit) int {
+		return cmp.Or(
+			cmp.Compare(b.Score, a.Score),
+			cmp.Compare(a.Record.Key.Name, b.Record.Key.Name),
+			cmp.Compare(a.Record.Key.Location, b.Record.Key.Location),
+			cmp.Compare(a.Record.Key.Type, b.Record.Key.Type),
+		)
+	})
+	return out
+}
+
+// searchTerms lower-cases text and splits it into letter/digit runs, so "pdf-tools" matches "PDF tools".
+func searchTerms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func uniqueSearchTerms(text string) []string {
+	terms := searchTerms(text)
+	slices.Sort(terms)
+	return slices.Compact(terms)
+}
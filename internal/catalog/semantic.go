@@ -0,0 +1,147 @@
+package catalog
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+// SetEmbedder sets the embedder used by Recommend. Skills added or refreshed afterwards are embedded when
+// they are indexed; others are embedded on the next Recommend call.
+func (c *Catalog) SetEmbedder(e spec.Embedder) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.embedder = e
+}
+
+// HasEmbedder reports whether an embedder is set.
+func (c *Catalog) HasEmbedder() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.embedder != nil
+}
+
+// Recommend ranks the records matching f by the cosine similarity of their embedded name, description and
+// tags to the embedded query. Records with a non-positive similarity are omitted. Hits are sorted by
+// descending score, then by name and location.
+//
+// Records whose embedding is missing (the embedder failed when they were indexed) or stale are embedded
+// first, in the same batch as the query.
+func (c *Catalog) Recommend(ctx context.Context, query string, f PromptFilter) ([]SearchHit, error) {
+	c.mu.RLock()
+	emb := c.embedder
+	type pending struct {
+		key  spec.ProviderSkillKey
+		text string
+	}
+	var stale []pending
+	for k, e := range c.byKey {
+		if !f.match(e) {
+			continue
+		}
+		if text := semanticText(e.idx); e.vec == nil || e.vecText != text {
+			stale = append(stale, pending{key: k, text: text})
+		}
+	}
+	c.mu.RUnlock()
+	if emb == nil {
+		return nil, fmt.Errorf("%w: no embedder configured", spec.ErrInvalidArgument)
+	}
+
+	texts := make([]string, 0, len(stale)+1)
+	texts = append(texts, query)
+	for _, p := range stale {
+		texts = append(texts, p.text)
+	}
+	vecs, err := emb.Embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	if len(vecs) != len(texts) {
+		return nil, fmt.Errorf("embedder returned %d vectors for %d texts", len(vecs), len(texts))
+	}
+	q := vecs[0]
+	if len(q) == 0 {
+		return nil, errors.New("embedder returned an empty query vector")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Store the new embeddings unless the record changed meanwhile.
+	for i, p := range stale {
+		if e, ok := c.byKey[p.key]; ok && semanticText(e.idx) == p.text {
+			e.vec, e.vecText = vecs[i+1], p.text
+		}
+	}
+
+	var out []SearchHit
+	for _, e := range c.byKey {
+		if !f.match(e) || len(e.vec) != len(q) || e.vecText != semanticText(e.idx) {
+			continue
+		}
+		if score := cosine(q, e.vec); score > 0 {
+			out = append(out, SearchHit{Record: e.idx, Def: e.def, AddedAt: e.addedAt, Score: score})
+		}
+	}
+	slices.SortFunc(out, func(a, b SearchHit) int {
+		return cmp.Or(
+			cmp.Compare(b.Score, a.Score),
+			cmp.Compare(a.Record.Key.Name, b.Record.Key.Name),
+			cmp.Compare(a.Record.Key.Location, b.Record.Key.Location),
+			cmp.Compare(a.Record.Key.Type, b.Record.Key.Type),
+		)
+	})
+	return out, nil
+}
+
+// embedRecord embeds the semantic text of idx. Without an embedder, or if embedding fails, it returns a nil
+// vector; Recommend embeds such records later.
+func (c *Catalog) embedRecord(ctx context.Context, idx spec.ProviderSkillIndexRecord) ([]float32, string) {
+	c.mu.RLock()
+	emb := c.embedder
+	c.mu.RUnlock()
+	if emb == nil {
+		return nil, ""
+	}
+	text := semanticText(idx)
+	vecs, err := emb.Embed(ctx, []string{text})
+	if err != nil || len(vecs) != 1 || len(vecs[0]) == 0 {
+		return nil, ""
+	}
+	return vecs[0], text
+}
+
+// semanticText is the text embedded for a record: its name, description and tags.
+func semanticText(idx spec.ProviderSkillIndexRecord) string {
+	var b strings.Builder
+	b.WriteString(recordName(idx))
+	if idx.Description != "" {
+		b.WriteString("\n")
+		b.WriteString(idx.Description)
+	}
+	if len(idx.Tags) > 0 {
+		b.WriteString("\n")
+		b.WriteString(strings.Join(idx.Tags, ", "))
+	}
+	return b.String()
+}
+
+func cosine(a, b []float32) float64 {
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
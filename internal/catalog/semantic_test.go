@@ -0,0 +1,148 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+// keywordEmbedder embeds texts as keyword-presence vectors and records the texts it embedded.
+type keywordEmbedder struct {
+	keywords []string
+
+	mu       sync.Mutex
+	embedded []string
+	fail     bool
+}
+
+func (e *keywordEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.fail {
+		return nil, errors.New("embedder down")
+	}
+	out := make([][]float32, 0, len(texts))
+	for _, text := range texts {
+		e.embedded = append(e.embedded, text)
+		vec := make([]float32, len(e.keywords))
+		for i, kw := range e.keywords {
+			if strings.Contains(strings.ToLower(text), kw) {
+				vec[i] = 1
+			}
+		}
+		out = append(out, vec)
+	}
+	return out, nil
+}
+
+func (e *keywordEmbedder) calls() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	n := len(e.embedded)
+	e.embedded = nil
+	return n
+}
+
+func (e *keywordEmbedder) setFail(fail bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.fail = fail
+}
+
+func TestCatalog_Recommend(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	descriptions := map[string]string{
+		"changelog": "Summarize commits into release notes.",
+		"pdf":       "Merge PDF files.",
+		"csv":       "Clean CSV files.",
+	}
+	p := &testProvider{typ: "t"}
+	p.indexFn = func(_ context.Context, def spec.SkillDef) (spec.ProviderSkillIndexRecord, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return spec.ProviderSkillIndexRecord{
+			Key:         spec.ProviderSkillKey(def),
+			Description: descriptions[def.Name],
+			Digest:      descriptions[def.Name],
+		}, nil
+	}
+	c := New(mapResolver{"t": p})
+	if _, err := c.Recommend(t.Context(), "notes", PromptFilter{}); !errors.Is(err, spec.ErrInvalidArgument) {
+		t.Fatalf("Recommend without embedder: err = %v, want ErrInvalidArgument", err)
+	}
+
+	emb := &keywordEmbedder{keywords: []string{"release", "notes", "pdf", "csv", "files"}}
+	c.SetEmbedder(emb)
+	defs := map[string]spec.SkillDef{}
+	for name := range descriptions {
+		defs[name] = spec.SkillDef{Type: "t", Name: name, Location: "/" + name}
+	}
+	for _, name := range []string{"changelog", "pdf"} {
+		if _, err := c.Add(t.Context(), defs[name]); err != nil {
+			t.Fatalf("Add %s: %v", name, err)
+		}
+	}
+	// A skill whose embedding fails at add time is embedded by the next Recommend.
+	emb.setFail(true)
+	if _, err := c.Add(t.Context(), defs["csv"]); err != nil {
+		t.Fatalf("Add csv: %v", err)
+	}
+	emb.setFail(false)
+	if n := emb.calls(); n != 2 {
+		t.Fatalf("embedded %d texts at add, want 2", n)
+	}
+
+	names := func(hits []SearchHit) string {
+		out := make([]string, 0, len(hits))
+		for _, h := range hits {
+			out = append(out, h.Record.Key.Name)
+		}
+		return strings.Join(out, ",")
+	}
+	hits, err := c.Recommend(t.Context(), "write release notes", PromptFilter{})
+	if err != nil {
+		t.Fatalf("Recommend: %v", err)
+	}
+	if names(hits) != "changelog" || hits[0].UserRecord().Def != defs["changelog"] {
+		t.Fatalf("Recommend(release notes) = %s", names(hits))
+	}
+	if n := emb.calls(); n != 2 {
+		t.Fatalf("embedded %d texts in Recommend, want query + csv", n)
+	}
+
+	hits, err = c.Recommend(t.Context(), "files", PromptFilter{Types: []string{"t"}})
+	if err != nil || names(hits) != "csv,pdf" {
+		t.Fatalf("Recommend(files) = %s, %v", names(hits), err)
+	}
+
+	// Refresh re-embeds changed skills only; removed skills are no longer recommended.
+	emb.calls()
+	if res, err := c.Refresh(t.Context(), defs["pdf"]); err != nil || res.Status != spec.SkillRefreshStatusUnchanged {
+		t.Fatalf("Refresh unchanged = %+v, %v", res, err)
+	}
+	mu.Lock()
+	descriptions["pdf"] = "Merge PDF documents."
+	mu.Unlock()
+	if res, err := c.Refresh(t.Context(), defs["pdf"]); err != nil || res.Status != spec.SkillRefreshStatusUpdated {
+		t.Fatalf("Refresh updated = %+v, %v", res, err)
+	}
+	if n := emb.calls(); n != 1 {
+		t.Fatalf("embedded %d texts on refresh, want 1", n)
+	}
+	c.Remove(defs["csv"])
+	hits, err = c.Recommend(t.Context(), "files", PromptFilter{})
+	if err != nil || len(hits) != 0 {
+		t.Fatalf("Recommend(files) after changes = %s, %v; want none", names(hits), err)
+	}
+
+	emb.setFail(true)
+	if _, err := c.Recommend(t.Context(), "files", PromptFilter{}); err == nil {
+		t.Fatalf("Recommend with failing embedder: expected error")
+	}
+}
@@ -0,0 +1,236 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/flexigpt/agentskills-go"
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+func subscribeEvents(t *testing.T, rt *agentskills.Runtime, ctx context.Context) (<-chan spec.Event, func()) {
+	t.Helper()
+	ch := make(chan spec.Event, 64)
+	cancel, err := rt.Subscribe(ctx, func(ev spec.Event) { ch <- ev })
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	t.Cleanup(cancel)
+	return ch, cancel
+}
+
+func nextEvent(t *testing.T, ch <-chan spec.Event, want spec.EventType) spec.Event {
+	t.Helper()
+	select {
+	case ev := <-ch:
+		if ev.Type != want {
+			t.Fatalf("expected %s event, got %+v", want, ev)
+		}
+		if ev.Time.IsZero() {
+			t.Fatalf("expected event time to be set: %+v", ev)
+		}
+		return ev
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for %s event", want)
+		return spec.Event{}
+	}
+}
+
+func TestRuntime_Subscribe_SessionAndCatalogEvents(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	t.Cleanup(cancel)
+
+	rt := mustNewRuntime(t, agentskills.WithProvider(&fakeProvider{typ: "p"}))
+	events, unsubscribe := subscribeEvents(t, rt, ctx)
+
+	a := spec.SkillDef{Type: "p", Name: "a", Location: "/a"}
+	b := spec.SkillDef{Type: "p", Name: "b", Location: "/b"}
+	_ = mustAddSkill(t, rt, ctx, a)
+	_ = mustAddSkill(t, rt, ctx, b)
+	for _, d := range []spec.SkillDef{a, b} {
+		if ev := nextEvent(t, events, spec.EventTypeSkillAdded); !slices.Equal(ev.Skills, []spec.SkillDef{d}) {
+			t.Fatalf("unexpected added event: %+v", ev)
+		}
+	}
+
+	sid, _ := mustNewSession(t, rt, ctx, agentskills.WithSessionActiveSkills([]spec.SkillDef{a}))
+	if ev := nextEvent(t, events, spec.EventTypeSessionCreated); ev.SessionID != sid {
+		t.Fatalf("unexpected created event: %+v", ev)
+	}
+	ev := nextEvent(t, events, spec.EventTypeSkillsActivated)
+	if ev.SessionID != sid || !slices.Equal(ev.Skills, []spec.SkillDef{a}) || len(ev.PreviousActiveSkills) != 0 ||
+		!slices.Equal(ev.ActiveSkills, []spec.SkillDef{a}) {
+		t.Fatalf("unexpected activated event: %+v", ev)
+	}
+
+	// LLM tool calls are reported with host defs.
+	reg, err := rt.NewSessionRegistry(ctx, sid)
+	if err != nil {
+		t.Fatalf("NewSessionRegistry: %v", err)
+	}
+	in, _ := json.Marshal(spec.LoadArgs{Skills: []spec.SkillHandle{{Name: "b", Location: "/b"}}})
+	if _, err := reg.Call(ctx, spec.FuncIDSkillsLoad, in); err != nil {
+		t.Fatalf("skills-load: %v", err)
+	}
+	ev = nextEvent(t, events, spec.EventTypeSkillsDeactivated)
+	if !slices.Equal(ev.Skills, []spec.SkillDef{a}) || !slices.Equal(ev.PreviousActiveSkills, []spec.SkillDef{a}) ||
+		!slices.Equal(ev.ActiveSkills, []spec.SkillDef{b}) {
+		t.Fatalf("unexpected deactivated event: %+v", ev)
+	}
+	ev = nextEvent(t, events, spec.EventTypeSkillsActivated)
+	if !slices.Equal(ev.Skills, []spec.SkillDef{b}) || !slices.Equal(ev.ActiveSkills, []spec.SkillDef{b}) {
+		t.Fatalf("unexpected activated event: %+v", ev)
+	}
+
+	res, err := rt.RefreshSkill(ctx, a)
+	if err != nil {
+		t.Fatalf("RefreshSkill: %v", err)
+	}
+	ev = nextEvent(t, events, spec.EventTypeSkillRefreshed)
+	if ev.RefreshStatus != res.Status || !slices.Equal(ev.Skills, []spec.SkillDef{a}) {
+		t.Fatalf("unexpected refreshed event: %+v", ev)
+	}
+
+	if _, err := rt.RemoveSkill(ctx, b); err != nil {
+		t.Fatalf("RemoveSkill: %v", err)
+	}
+	if ev := nextEvent(t, events, spec.EventTypeSkillRemoved); !slices.Equal(ev.Skills, []spec.SkillDef{b}) {
+		t.Fatalf("unexpected removed event: %+v", ev)
+	}
+	ev = nextEvent(t, events, spec.EventTypeSkillsPruned)
+	if ev.SessionID != sid || !slices.Equal(ev.Skills, []spec.SkillDef{b}) {
+		t.Fatalf("unexpected pruned event: %+v", ev)
+	}
+
+	if err := rt.CloseSession(ctx, sid); err != nil {
+		t.Fatalf("CloseSession: %v", err)
+	}
+	if ev := nextEvent(t, events, spec.EventTypeSessionClosed); ev.SessionID != sid {
+		t.Fatalf("unexpected closed event: %+v", ev)
+	}
+	if err := rt.CloseSession(ctx, sid); err != nil {
+		t.Fatalf("CloseSession again: %v", err)
+	}
+
+	unsubscribe()
+	_ = mustAddSkill(t, rt, ctx, spec.SkillDef{Type: "p", Name: "c", Location: "/c"})
+	select {
+	case ev := <-events:
+		t.Fatalf("expected no events after cancel, got %+v", ev)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if _, err := rt.Subscribe(ctx, nil); !errors.Is(err, spec.ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument for nil callback, got %v", err)
+	}
+}
+
+func TestRuntime_Subscribe_FailedNewSessionPublishesNothing(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	t.Cleanup(cancel)
+
+	for _, tc := range []struct {
+		name string
+		opts []agentskills.Option
+	}{
+		{name: "memory"},
+		{name: "file", opts: []agentskills.Option{agentskills.WithFileSessionStore(t.TempDir())}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &fakeProvider{typ: "p", loadBodyFn: func(context.Context, spec.ProviderSkillKey) (string, error) {
+				return "", errors.New("boom")
+			}}
+			rt := mustNewRuntime(t, append([]agentskills.Option{agentskills.WithProvider(p)}, tc.opts...)...)
+			a := mustAddSkill(t, rt, ctx, spec.SkillDef{Type: "p", Name: "a", Location: "/a"}).Def
+			events, _ := subscribeEvents(t, rt, ctx)
+
+			if _, _, err := rt.NewSession(ctx, agentskills.WithSessionActiveSkills([]spec.SkillDef{a})); err == nil {
+				t.Fatalf("expected NewSession to fail when the initial skill cannot be loaded")
+			}
+			// The next event is the creation of the next session: the failed one published nothing.
+			sid, _ := mustNewSession(t, rt, ctx)
+			if ev := nextEvent(t, events, spec.EventTypeSessionCreated); ev.SessionID != sid {
+				t.Fatalf("unexpected created event: %+v", ev)
+			}
+		})
+	}
+}
+
+func TestRuntime_Subscribe_SessionEvictionReasons(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	t.Cleanup(cancel)
+
+	rt := mustNewRuntime(t,
+		agentskills.WithProvider(&fakeProvider{typ: "p"}),
+		agentskills.WithMaxSessions(1),
+		agentskills.WithSessionTTL(50*time.Millisecond),
+	)
+	events, _ := subscribeEvents(t, rt, ctx)
+
+	sid1, _ := mustNewSession(t, rt, ctx)
+	_ = nextEvent(t, events, spec.EventTypeSessionCreated)
+	sid2, _ := mustNewSession(t, rt, ctx)
+	_ = nextEvent(t, events, spec.EventTypeSessionCreated)
+	ev := nextEvent(t, events, spec.EventTypeSessionEvicted)
+	if ev.SessionID != sid1 || ev.EvictionReason != spec.SessionEvictionReasonLRU {
+		t.Fatalf("expected LRU eviction of %s, got %+v", sid1, ev)
+	}
+
+	time.Sleep(80 * time.Millisecond)
+	if _, err := rt.ExportSession(ctx, sid2); !errors.Is(err, spec.ErrSessionNotFound) {
+		t.Fatalf("expected expired session, got %v", err)
+	}
+	ev = nextEvent(t, events, spec.EventTypeSessionEvicted)
+	if ev.SessionID != sid2 || ev.EvictionReason != spec.SessionEvictionReasonTTL {
+		t.Fatalf("expected TTL eviction of %s, got %+v", sid2, ev)
+	}
+}
+
+func TestRuntime_Subscribe_CallbackMayCallRuntime(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	t.Cleanup(cancel)
+
+	rt := mustNewRuntime(t, agentskills.WithProvider(&fakeProvider{typ: "p"}))
+	got := make(chan int, 1)
+	subCtx, stop := context.WithCancel(ctx)
+	_, err := rt.Subscribe(subCtx, func(ev spec.Event) {
+		if ev.Type != spec.EventTypeSkillsActivated {
+			return
+		}
+		recs, err := rt.ListSkills(ctx, &agentskills.SkillListFilter{
+			SessionID: ev.SessionID,
+			Activity:  spec.SkillActivityActive,
+		})
+		if err == nil {
+			got <- len(recs)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	t.Cleanup(stop)
+
+	a := spec.SkillDef{Type: "p", Name: "a", Location: "/a"}
+	_ = mustAddSkill(t, rt, ctx, a)
+	_, _ = mustNewSession(t, rt, ctx, agentskills.WithSessionActiveSkills([]spec.SkillDef{a}))
+	select {
+	case n := <-got:
+		if n != 1 {
+			t.Fatalf("expected 1 active skill from callback, got %d", n)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for callback")
+	}
+}
@@ -259,3 +259,94 @@ func TestRuntime_FSProvider_TolerantDocumentAndTemplateWorkflow(t *testing.T) {
 		t.Fatalf("AddSkill(mismatched document): expected name validation error, got %v", err)
 	}
 }
+
+func TestRuntime_FSProvider_DiscoverSkills(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Second)
+	t.Cleanup(cancel)
+
+	tmp := t.TempDir()
+	writeSkill := func(rel, name string) {
+		t.Helper()
+		dir := filepath.Join(tmp, filepath.FromSlash(rel))
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		doc := "---\nname: " + name + "\ndescription: discovered\n---\n\nBody\n"
+		if err := os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte(doc), 0o600); err != nil {
+			t.Fatalf("write SKILL.md: %v", err)
+		}
+	}
+	writeSkill("alpha", "alpha")
+	writeSkill("beta", "not-beta")              // name mismatch => failed
+	writeSkill(".hidden-skill", "hidden-skill") // hidden => skipped
+	writeSkill("group/nested", "nested")        // depth 2
+	writeSkill("tmp-skill", "tmp-skill")        // ignored
+
+	fsp, err := fsskillprovider.New()
+	if err != nil {
+		t.Fatalf("new fs provider: %v", err)
+	}
+	rt := mustNewRuntime(t, agentskills.WithProvider(fsp), agentskills.WithProvider(&fakeProvider{typ: fakeStr}))
+
+	if _, err := rt.DiscoverSkills(ctx, fakeStr, tmp, nil); !errors.Is(err, spec.ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument for non-discoverer provider, got %v", err)
+	}
+	if _, err := rt.DiscoverSkills(ctx, missingStr, tmp, nil); !errors.Is(err, spec.ErrProviderNotFound) {
+		t.Fatalf("expected ErrProviderNotFound, got %v", err)
+	}
+
+	results, err := rt.DiscoverSkills(ctx, fsskillprovider.Type, tmp, &spec.SkillDiscoveryOptions{
+		MaxDepth: 2,
+		Ignore:   []string{"tmp-*"},
+	})
+	if err != nil {
+		t.Fatalf("DiscoverSkills: %v", err)
+	}
+
+	got := map[string]spec.SkillDiscoveryResult{}
+	for _, r := range results {
+		got[r.Def.Name] = r
+	}
+	want := map[string]spec.SkillDiscoveryStatus{
+		"alpha":         spec.SkillDiscoveryStatusAdded,
+		"beta":          spec.SkillDiscoveryStatusFailed,
+		".hidden-skill": spec.SkillDiscoveryStatusSkipped,
+		"nested":        spec.SkillDiscoveryStatusAdded,
+		"tmp-skill":     spec.SkillDiscoveryStatusSkipped,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	for name, status := range want {
+		if got[name].Status != status {
+			t.Fatalf("%s: got %+v want status %q", name, got[name], status)
+		}
+	}
+	if loc := got["nested"].Def.Location; loc != filepath.Join(tmp, "group", "nested") {
+		t.Fatalf("expected user-derived location, got %q", loc)
+	}
+	if got["beta"].Error == "" {
+		t.Fatalf("expected parse error for beta, got %+v", got["beta"])
+	}
+
+	// Second run: everything already registered is skipped.
+	again, err := rt.DiscoverSkills(ctx, fsskillprovider.Type, tmp, &spec.SkillDiscoveryOptions{MaxDepth: 2})
+	if err != nil {
+		t.Fatalf("DiscoverSkills again: %v", err)
+	}
+	for _, r := range again {
+		if r.Def.Name == "alpha" && (r.Status != spec.SkillDiscoveryStatusSkipped || r.Reason != "already registered") {
+			t.Fatalf("expected alpha to be skipped as already registered, got %+v", r)
+		}
+	}
+
+	recs, err := rt.ListSkills(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListSkills: %v", err)
+	}
+	if len(recs) != 3 {
+		t.Fatalf("expected alpha, nested and tmp-skill registered, got %+v", recs)
+	}
+}
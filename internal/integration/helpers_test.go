@@ -2,8 +2,12 @@ package integration
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/flexigpt/agentskills-go"
 	"github.com/flexigpt/agentskills-go/spec"
@@ -74,6 +78,105 @@ func (p *fakeProvider) RunScript(
 	return spec.RunScriptOut{}, spec.ErrRunScriptUnsupported
 }
 
+// memStateStore is an in-memory spec.SessionStateStore. It keeps states JSON-encoded, like a database
+// would, so runtimes sharing it never share session values.
+type memStateStore struct {
+	mu     sync.Mutex
+	states map[spec.SessionID]memStoredState
+}
+
+type memStoredState struct {
+	data      []byte
+	version   uint64
+	createdAt time.Time
+	lastUsed  time.Time
+}
+
+func newMemStateStore() *memStateStore {
+	return &memStateStore{states: map[spec.SessionID]memStoredState{}}
+}
+
+func (m *memStateStore) Create(ctx context.Context, state spec.SessionState, lastUsed time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.states[state.ID]; ok {
+		return false, nil
+	}
+	return true, m.putLocked(state, lastUsed)
+}
+
+func (m *memStateStore) Load(ctx context.Context, id spec.SessionID) (spec.SessionState, time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cur, ok := m.states[id]
+	if !ok {
+		return spec.SessionState{}, time.Time{}, fmt.Errorf("%w: %s", spec.ErrSessionNotFound, id)
+	}
+	var state spec.SessionState
+	if err := json.Unmarshal(cur.data, &state); err != nil {
+		return spec.SessionState{}, time.Time{}, err
+	}
+	return state, cur.lastUsed, nil
+}
+
+func (m *memStateStore) Update(ctx context.Context, state spec.SessionState, prevVersion uint64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cur, ok := m.states[state.ID]
+	if !ok {
+		return false, fmt.Errorf("%w: %s", spec.ErrSessionNotFound, state.ID)
+	}
+	if cur.version != prevVersion {
+		return false, nil
+	}
+	return true, m.putLocked(state, cur.lastUsed)
+}
+
+func (m *memStateStore) Touch(ctx context.Context, id spec.SessionID, lastUsed time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if cur, ok := m.states[id]; ok {
+		cur.lastUsed = lastUsed
+		m.states[id] = cur
+	}
+	return nil
+}
+
+func (m *memStateStore) Delete(ctx context.Context, id spec.SessionID, unusedSince time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cur, ok := m.states[id]
+	if !ok || (!unusedSince.IsZero() && cur.lastUsed.After(unusedSince)) {
+		return false, nil
+	}
+	delete(m.states, id)
+	return true, nil
+}
+
+func (m *memStateStore) List(ctx context.Context) ([]spec.SessionStateInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]spec.SessionStateInfo, 0, len(m.states))
+	for id, cur := range m.states {
+		out = append(out, spec.SessionStateInfo{ID: id, CreatedAt: cur.createdAt, LastUsed: cur.lastUsed})
+	}
+	return out, nil
+}
+
+func (m *memStateStore) putLocked(state spec.SessionState, lastUsed time.Time) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	m.states[state.ID] = memStoredState{
+		data:      data,
+		version:   state.Version,
+		createdAt: state.CreatedAt,
+		lastUsed:  lastUsed,
+	}
+	return nil
+}
+
 func mustNewRuntime(t *testing.T, opts ...agentskills.Option) *agentskills.Runtime {
 	t.Helper()
 	rt, err := agentskills.New(opts...)
@@ -114,3 +217,35 @@ func mustNewSession(
 	}
 	return sid, active
 }
+
+// callTool calls a session registry tool with JSON-encoded args and decodes its text output into Out.
+func callTool[Out any](
+	t *testing.T,
+	rt *agentskills.Runtime,
+	ctx context.Context,
+	sid spec.SessionID,
+	funcID llmtoolsgoSpec.FuncID,
+	args any,
+) (Out, error) {
+	t.Helper()
+	var out Out
+	reg, err := rt.NewSessionRegistry(ctx, sid)
+	if err != nil {
+		t.Fatalf("NewSessionRegistry: %v", err)
+	}
+	in, err := json.Marshal(args)
+	if err != nil {
+		t.Fatalf("marshal args: %v", err)
+	}
+	res, err := reg.Call(ctx, funcID, in)
+	if err != nil {
+		return out, err
+	}
+	if len(res) != 1 || res[0].TextItem == nil {
+		t.Fatalf("expected one text output, got %+v", res)
+	}
+	if err := json.Unmarshal([]byte(res[0].TextItem.Text), &out); err != nil {
+		t.Fatalf("decode tool output %q: %v", res[0].TextItem.Text, err)
+	}
+	return out, nil
+}
@@ -0,0 +1,196 @@
+// Package providerutil holds helpers shared by skill providers that serve SKILL.md documents and
+// resources from sources other than the local filesystem (fs.FS values, memory, archives, git, HTTP).
+package providerutil
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	llmtoolsgoSpec "github.com/flexigpt/llmtools-go/spec"
+
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+// MaxResourceBytes caps a single resource served by ReadResource.
+// It matches the read cap used by llmtools-go/fstool for filesystem resources.
+const MaxResourceBytes = 16 << 20
+
+// Digest returns the "sha256:<hex>" digest used by providers for SKILL.md and bundle contents.
+func Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// DigestReader returns the Digest of everything read from r, without buffering it.
+func DigestReader(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// NormalizeEncoding applies the default (text) and validates the requested encoding.
+func NormalizeEncoding(enc spec.ReadResourceEncoding) (spec.ReadResourceEncoding, error) {
+	if strings.TrimSpace(string(enc)) == "" {
+		return spec.ReadResourceEncodingText, nil
+	}
+	switch enc {
+	case spec.ReadResourceEncodingText, spec.ReadResourceEncodingBinary:
+		return enc, nil
+	default:
+		return "", fmt.Errorf("%w: unknown encoding: %q", spec.ErrInvalidArgument, enc)
+	}
+}
+
+// CleanLocation validates a slash-separated relative location and returns its clean form.
+//
+// Absolute paths, backslashes, NUL bytes and any ".." element are rejected, so the result can
+// safely be joined onto a skill root without escaping it. "." is returned for the root itself.
+func CleanLocation(loc string) (string, error) {
+	if strings.TrimSpace(loc) == "" {
+		return "", fmt.Errorf("%w: empty location", spec.ErrInvalidArgument)
+	}
+	if strings.ContainsRune(loc, '\x00') {
+		return "", fmt.Errorf("%w: location contains NUL byte", spec.ErrInvalidArgument)
+	}
+	if strings.ContainsRune(loc, '\\') {
+		return "", fmt.Errorf("%w: location must use forward slashes: %q", spec.ErrInvalidArgument, loc)
+	}
+	if strings.HasPrefix(loc, "/") {
+		return "", fmt.Errorf("%w: location must be relative: %q", spec.ErrInvalidArgument, loc)
+	}
+	if slices.Contains(strings.Split(loc, "/"), "..") {
+		return "", fmt.Errorf("%w: location must not contain '..': %q", spec.ErrInvalidArgument, loc)
+	}
+	return path.Clean(loc), nil
+}
+
+// ReadRegularFile reads name from fsys, refusing symlinks, non-regular files and files above limit.
+func ReadRegularFile(fsys fs.FS, name string, limit int) ([]byte, error) {
+	st, err := fs.Lstat(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	if st.Mode()&fs.ModeSymlink != 0 {
+		return nil, fmt.Errorf("%s must not be a symlink", name)
+	}
+	if !st.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s must be a regular file", name)
+	}
+	if st.Size() > int64(limit) {
+		return nil, fmt.Errorf("%s too large (max %d bytes)", name, limit)
+	}
+
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, int64(limit)+1))
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", name, err)
+	}
+	if len(data) > limit {
+		return nil, fmt.Errorf("%s too large (max %d bytes)", name, limit)
+	}
+	return data, nil
+}
+
+// ResourceInfo builds SkillResourceInfo from provider-defined locations.
+// Locations are sorted and capped at spec.MaxSkillResourceLocations; TotalCount keeps the full count.
+func ResourceInfo(locations []string) spec.SkillResourceInfo {
+	sorted := slices.Clone(locations)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+
+	info := spec.SkillResourceInfo{
+		HasResources: len(sorted) > 0,
+		TotalCount:   len(sorted),
+	}
+	if len(sorted) > spec.MaxSkillResourceLocations {
+		info.Locations = sorted[:spec.MaxSkillResourceLocations]
+		info.MoreLocations = true
+	} else if len(sorted) > 0 {
+		info.Locations = sorted
+	}
+	return info
+}
+
+// ResourceOutput converts resource bytes into skills-readresource tool output.
+//
+// Text mode requires valid UTF-8 without NUL bytes. Binary mode returns base64 data as an image
+// item for image MIME types and as a file item otherwise (same shape as llmtools-go/fstool).
+func ResourceOutput(
+	name string,
+	data []byte,
+	enc spec.ReadResourceEncoding,
+) ([]llmtoolsgoSpec.ToolOutputUnion, error) {
+	if len(data) > MaxResourceBytes {
+		return nil, fmt.Errorf("resource %q too large (max %d bytes)", name, MaxResourceBytes)
+	}
+	enc, err := NormalizeEncoding(enc)
+	if err != nil {
+		return nil, err
+	}
+
+	if enc == spec.ReadResourceEncodingText {
+		if !utf8.Valid(data) || slices.Contains(data, 0) {
+			return nil, fmt.Errorf(
+				"resource %q is not valid UTF-8 text; use encoding \"binary\" instead",
+				name,
+			)
+		}
+		return []llmtoolsgoSpec.ToolOutputUnion{
+			{
+				Kind:     llmtoolsgoSpec.ToolOutputKindText,
+				TextItem: &llmtoolsgoSpec.ToolOutputText{Text: string(data)},
+			},
+		}, nil
+	}
+
+	baseName := path.Base(name)
+	if baseName == "" || baseName == "." || baseName == "/" {
+		baseName = "file"
+	}
+	mt := mime.TypeByExtension(strings.ToLower(path.Ext(baseName)))
+	if mt == "" {
+		mt = http.DetectContentType(data)
+	}
+	encoded := base64.StdEncoding.EncodeToString(data)
+
+	if strings.HasPrefix(mt, "image/") {
+		return []llmtoolsgoSpec.ToolOutputUnion{
+			{
+				Kind: llmtoolsgoSpec.ToolOutputKindImage,
+				ImageItem: &llmtoolsgoSpec.ToolOutputImage{
+					Detail:    llmtoolsgoSpec.ImageDetailAuto,
+					ImageName: baseName,
+					ImageMIME: mt,
+					ImageData: encoded,
+				},
+			},
+		}, nil
+	}
+	return []llmtoolsgoSpec.ToolOutputUnion{
+		{
+			Kind: llmtoolsgoSpec.ToolOutputKindFile,
+			FileItem: &llmtoolsgoSpec.ToolOutputFile{
+				FileName: baseName,
+				FileMIME: mt,
+				FileData: encoded,
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,99 @@
+package providerutil
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	llmtoolsgoSpec "github.com/flexigpt/llmtools-go/spec"
+
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+func TestCleanLocation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "a/b.md", want: "a/b.md"},
+		{in: "a//b/./c", want: "a/b/c"},
+		{in: ".", want: "."},
+		{in: " ", wantErr: true},
+		{in: "/abs", wantErr: true},
+		{in: "a/../b", wantErr: true},
+		{in: "..", wantErr: true},
+		{in: "a\\b", wantErr: true},
+		{in: "a\x00b", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := CleanLocation(tt.in)
+		if tt.wantErr {
+			if !errors.Is(err, spec.ErrInvalidArgument) {
+				t.Fatalf("CleanLocation(%q): expected invalid argument, got %q, %v", tt.in, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Fatalf("CleanLocation(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestResourceInfo_SortsDedupesAndCaps(t *testing.T) {
+	t.Parallel()
+
+	if info := ResourceInfo(nil); info.HasResources || info.Locations != nil {
+		t.Fatalf("expected empty info, got %+v", info)
+	}
+
+	info := ResourceInfo([]string{"b", "a", "b"})
+	if info.TotalCount != 2 || info.Locations[0] != "a" || info.Locations[1] != "b" || info.MoreLocations {
+		t.Fatalf("unexpected info: %+v", info)
+	}
+
+	many := make([]string, 0, spec.MaxSkillResourceLocations+5)
+	for i := range spec.MaxSkillResourceLocations + 5 {
+		many = append(many, fmt.Sprintf("r%04d", i))
+	}
+	info = ResourceInfo(many)
+	if !info.MoreLocations || len(info.Locations) != spec.MaxSkillResourceLocations ||
+		info.TotalCount != len(many) {
+		t.Fatalf("expected capped locations, got total=%d len=%d more=%v",
+			info.TotalCount, len(info.Locations), info.MoreLocations)
+	}
+}
+
+func TestResourceOutput(t *testing.T) {
+	t.Parallel()
+
+	out, err := ResourceOutput("notes.txt", []byte("hi"), "")
+	if err != nil || len(out) != 1 || out[0].Kind != llmtoolsgoSpec.ToolOutputKindText {
+		t.Fatalf("unexpected text output: %+v, %v", out, err)
+	}
+	if _, err := ResourceOutput("bin", []byte{0xff, 0x00}, spec.ReadResourceEncodingText); err == nil {
+		t.Fatalf("expected error for non-text data")
+	}
+
+	out, err = ResourceOutput("data.bin", []byte{1, 2, 3}, spec.ReadResourceEncodingBinary)
+	if err != nil || len(out) != 1 || out[0].FileItem == nil || out[0].FileItem.FileData != "AQID" {
+		t.Fatalf("unexpected file output: %+v, %v", out, err)
+	}
+	out, err = ResourceOutput("img.png", []byte{1}, spec.ReadResourceEncodingBinary)
+	if err != nil || len(out) != 1 || out[0].ImageItem == nil {
+		t.Fatalf("unexpected image output: %+v, %v", out, err)
+	}
+
+	if _, err := ResourceOutput("big", make([]byte, MaxResourceBytes+1), ""); err == nil {
+		t.Fatalf("expected size error")
+	}
+	if Digest([]byte("x")) == Digest([]byte("y")) {
+		t.Fatalf("expected distinct digests")
+	}
+	if d, err := DigestReader(strings.NewReader("x")); err != nil || d != Digest([]byte("x")) {
+		t.Fatalf("DigestReader = %q, %v; want %q", d, err, Digest([]byte("x")))
+	}
+}
@@ -0,0 +1,135 @@
+// Package semver parses and compares Semantic Versioning 2.0.0 versions (https://semver.org).
+//
+// Versions are strict: MAJOR.MINOR.PATCH with optional -PRERELEASE and +BUILD parts, no "v" prefix.
+package semver
+
+import (
+	"cmp"
+	"strconv"
+	"strings"
+)
+
+// Version is a parsed semantic version.
+type Version struct {
+	Major, Minor, Patch uint64
+
+	// Prerelease holds the dot-separated pre-release identifiers (empty for a release).
+	Prerelease []string
+
+	// Build is the build metadata; it is ignored when comparing versions.
+	Build string
+}
+
+// Parse parses s as a semantic version.
+func Parse(s string) (Version, bool) {
+	var v Version
+	rest, build, hasBuild := strings.Cut(s, "+")
+	if hasBuild {
+		if !validIdentifiers(build, false) {
+			return Version{}, false
+		}
+		v.Build = build
+	}
+	core, pre, hasPre := strings.Cut(rest, "-")
+	if hasPre {
+		if !validIdentifiers(pre, true) {
+			return Version{}, false
+		}
+		v.Prerelease = strings.Split(pre, ".")
+	}
+
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return Version{}, false
+	}
+	nums := [3]*uint64{&v.Major, &v.Minor, &v.Patch}
+	for i, p := range parts {
+		if !isNumeric(p) || (len(p) > 1 && p[0] == '0') {
+			return Version{}, false
+		}
+		n, err := strconv.ParseUint(p, 10, 64)
+		if err != nil {
+			return Version{}, false
+		}
+		*nums[i] = n
+	}
+	return v, true
+}
+
+// IsValid reports whether s is a valid semantic version.
+func IsValid(s string) bool {
+	_, ok := Parse(s)
+	return ok
+}
+
+// Compare compares a and b by semantic version precedence, returning -1, 0, or +1.
+func Compare(a, b Version) int {
+	if c := cmp.Or(
+		cmp.Compare(a.Major, b.Major),
+		cmp.Compare(a.Minor, b.Minor),
+		cmp.Compare(a.Patch, b.Patch),
+	); c != 0 {
+		return c
+	}
+
+	// A release has higher precedence than any of its pre-releases.
+	switch {
+	case len(a.Prerelease) == 0 && len(b.Prerelease) == 0:
+		return 0
+	case len(a.Prerelease) == 0:
+		return 1
+	case len(b.Prerelease) == 0:
+		return -1
+	}
+	for i := range min(len(a.Prerelease), len(b.Prerelease)) {
+		if c := compareIdentifier(a.Prerelease[i], b.Prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(a.Prerelease), len(b.Prerelease))
+}
+
+// compareIdentifier compares pre-release identifiers: numeric ones numerically and below alphanumeric ones.
+func compareIdentifier(a, b string) int {
+	an, bn := isNumeric(a), isNumeric(b)
+	switch {
+	case an && bn:
+		return cmp.Or(cmp.Compare(len(a), len(b)), strings.Compare(a, b))
+	case an:
+		return -1
+	case bn:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// validIdentifiers validates dot-separated identifiers of [0-9A-Za-z-]. Numeric pre-release identifiers must
+// not have leading zeros.
+func validIdentifiers(s string, prerelease bool) bool {
+	for id := range strings.SplitSeq(s, ".") {
+		if id == "" {
+			return false
+		}
+		for _, r := range id {
+			if (r < '0' || r > '9') && (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && r != '-' {
+				return false
+			}
+		}
+		if prerelease && len(id) > 1 && id[0] == '0' && isNumeric(id) {
+			return false
+		}
+	}
+	return true
+}
+
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
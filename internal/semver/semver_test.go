@@ -0,0 +1,55 @@
+package semver
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{"0.0.0", "1.2.3", "10.20.30", "1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-0.3.7",
+		"1.0.0-x-y.z", "1.0.0+20130313144700", "1.0.0-beta+exp.sha.5114f85"} {
+		if !IsValid(s) {
+			t.Fatalf("IsValid(%q) = false, want true", s)
+		}
+	}
+	for _, s := range []string{"", "1", "1.2", "v1.2.3", "1.2.3.4", "01.2.3", "1.02.3", "1.2.3-", "1.2.3-01",
+		"1.2.3-a..b", "1.2.3+", "1.2.3-a_b", " 1.2.3", "1.2.x", "-1.2.3"} {
+		if IsValid(s) {
+			t.Fatalf("IsValid(%q) = true, want false", s)
+		}
+	}
+
+	v, _ := Parse("1.2.3-rc.1+build.5")
+	if v.Major != 1 || v.Minor != 2 || v.Patch != 3 || len(v.Prerelease) != 2 || v.Build != "build.5" {
+		t.Fatalf("Parse = %+v", v)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	// Ascending precedence, from the semver.org examples.
+	ordered := []string{"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2",
+		"1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.1.0", "2.0.0", "10.0.0"}
+	for i := range ordered {
+		for j := range ordered {
+			a, _ := Parse(ordered[i])
+			b, _ := Parse(ordered[j])
+			want := 0
+			switch {
+			case i < j:
+				want = -1
+			case i > j:
+				want = 1
+			}
+			if got := Compare(a, b); got != want {
+				t.Fatalf("Compare(%s, %s) = %d, want %d", ordered[i], ordered[j], got, want)
+			}
+		}
+	}
+
+	a, _ := Parse("1.0.0+a")
+	b, _ := Parse("1.0.0+b")
+	if Compare(a, b) != 0 {
+		t.Fatalf("build metadata must not affect precedence")
+	}
+}
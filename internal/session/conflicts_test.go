@@ -0,0 +1,171 @@
+package session
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+func TestSession_ActivateKeys_Conflicts(t *testing.T) {
+	t.Parallel()
+
+	google := spec.ProviderSkillKey{Type: "t", Name: "google-style", Location: "p1"}
+	airbnb := spec.ProviderSkillKey{Type: "t", Name: "airbnb-style", Location: "p2"}
+	other := spec.ProviderSkillKey{Type: "t", Name: "other", Location: "p3"}
+	handle := func(k spec.ProviderSkillKey) spec.SkillHandle {
+		return spec.SkillHandle{Name: k.Name, Location: k.Location}
+	}
+
+	tests := []struct {
+		name         string
+		pin          bool
+		mode         spec.LoadMode
+		wantConflict bool
+		wantActive   []spec.ProviderSkillKey
+		wantReplaced []spec.SkillHandle
+	}{
+		{
+			name:         "add rejects conflicting active skill",
+			mode:         spec.LoadModeAdd,
+			wantConflict: true,
+			wantActive:   []spec.ProviderSkillKey{airbnb, other},
+		},
+		{
+			name:         "replace-conflicting swaps out conflicting skill",
+			mode:         spec.LoadModeReplaceConflicting,
+			wantActive:   []spec.ProviderSkillKey{other, google},
+			wantReplaced: []spec.SkillHandle{handle(airbnb)},
+		},
+		{
+			name:         "replace-conflicting never swaps out pinned skills",
+			pin:          true,
+			mode:         spec.LoadModeReplaceConflicting,
+			wantConflict: true,
+			wantActive:   []spec.ProviderSkillKey{airbnb, other},
+		},
+		{
+			name:       "replace drops the conflicting skill anyway",
+			mode:       spec.LoadModeReplace,
+			wantActive: []spec.ProviderSkillKey{google},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cat := newMemCatalog()
+			for _, k := range []spec.ProviderSkillKey{google, airbnb, other} {
+				cat.add(k, "body-"+k.Name)
+			}
+			// Declared on one side only; conflicts are symmetric.
+			cat.setConflicts(google, "airbnb-style")
+			s := newSession(SessionConfig{
+				ID:        "id",
+				Catalog:   cat,
+				Providers: mapResolver{"t": &canonProvider{typ: "t"}},
+				Touch:     func() {},
+			})
+			initial := []spec.ProviderSkillKey{airbnb, other}
+			var err error
+			if tt.pin {
+				_, err = s.PinKeys(t.Context(), initial, spec.LoadModeReplace)
+			} else {
+				_, err = s.ActivateKeys(t.Context(), initial, spec.LoadModeReplace)
+			}
+			if err != nil {
+				t.Fatalf("activate initial: %v", err)
+			}
+
+			args := spec.LoadArgs{Skills: []spec.SkillHandle{handle(google)}, Mode: tt.mode}
+			out, err := s.toolLoad(t.Context(), args)
+			if tt.wantConflict {
+				var cerr *spec.SkillConflictError
+				if !errors.As(err, &cerr) || !errors.Is(err, spec.ErrSkillConflict) {
+					t.Fatalf("err = %v, want *spec.SkillConflictError", err)
+				}
+				if cerr.Skill != handle(google) || !slices.Equal(cerr.Conflicts, []spec.SkillHandle{handle(airbnb)}) {
+					t.Fatalf("conflict error = %+v", cerr)
+				}
+			} else if err != nil {
+				t.Fatalf("toolLoad: %v", err)
+			}
+			if !slices.Equal(out.ReplacedSkills, tt.wantReplaced) {
+				t.Fatalf("replaced = %+v, want %+v", out.ReplacedSkills, tt.wantReplaced)
+			}
+			keys, err := s.ActiveKeys(t.Context())
+			if err != nil || !slices.Equal(keys, tt.wantActive) {
+				t.Fatalf("active = %+v, %v; want %+v", keys, err, tt.wantActive)
+			}
+		})
+	}
+
+	t.Run("requested skills conflicting with each other", func(t *testing.T) {
+		t.Parallel()
+		cat := newMemCatalog()
+		cat.add(google, "g")
+		cat.add(airbnb, "a")
+		cat.setConflicts(airbnb, "google-style")
+		s := newSession(SessionConfig{ID: "id", Catalog: cat, Touch: func() {}})
+		_, err := s.ActivateKeys(
+			t.Context(),
+			[]spec.ProviderSkillKey{google, airbnb},
+			spec.LoadModeReplaceConflicting,
+		)
+		if !errors.Is(err, spec.ErrSkillConflict) {
+			t.Fatalf("err = %v, want ErrSkillConflict", err)
+		}
+	})
+
+	t.Run("replace-conflicting and requires", func(t *testing.T) {
+		t.Parallel()
+		a := spec.ProviderSkillKey{Type: "t", Name: "a", Location: "p4"}
+		b := spec.ProviderSkillKey{Type: "t", Name: "b", Location: "p5"}
+		x := spec.ProviderSkillKey{Type: "t", Name: "x", Location: "p6"}
+		// newTestSession has a (requiring b) active, and x conflicts with the given skill.
+		newTestSession := func(t *testing.T, conflictsWith string) *Session {
+			t.Helper()
+			cat := newMemCatalog()
+			for _, k := range []spec.ProviderSkillKey{a, b, x} {
+				cat.add(k, "body-"+k.Name)
+			}
+			cat.setRequires(a, "b")
+			cat.setConflicts(x, conflictsWith)
+			s := newSession(SessionConfig{
+				ID:        "id",
+				Catalog:   cat,
+				Providers: mapResolver{"t": &canonProvider{typ: "t"}},
+				Touch:     func() {},
+			})
+			if _, err := s.ActivateKeys(t.Context(), []spec.ProviderSkillKey{a}, spec.LoadModeReplace); err != nil {
+				t.Fatalf("ActivateKeys: %v", err)
+			}
+			return s
+		}
+		args := spec.LoadArgs{Skills: []spec.SkillHandle{handle(x)}, Mode: spec.LoadModeReplaceConflicting}
+
+		// b is required by a, which stays, so it cannot be replaced.
+		s := newTestSession(t, "b")
+		var cerr *spec.SkillConflictError
+		if _, err := s.toolLoad(t.Context(), args); !errors.As(err, &cerr) ||
+			!slices.Equal(cerr.Conflicts, []spec.SkillHandle{handle(b)}) {
+			t.Fatalf("err = %v, want *spec.SkillConflictError naming b", err)
+		}
+		if keys, err := s.ActiveKeys(t.Context()); err != nil || !slices.Equal(keys, []spec.ProviderSkillKey{b, a}) {
+			t.Fatalf("active = %+v, %v; want b, a", keys, err)
+		}
+
+		// Replacing a unloads its implicit dependency b too.
+		s = newTestSession(t, "a")
+		out, err := s.toolLoad(t.Context(), args)
+		if err != nil {
+			t.Fatalf("toolLoad: %v", err)
+		}
+		if want := []spec.SkillHandle{handle(a), handle(b)}; !slices.Equal(out.ReplacedSkills, want) {
+			t.Fatalf("replaced = %+v, want %+v", out.ReplacedSkills, want)
+		}
+		if keys, err := s.ActiveKeys(t.Context()); err != nil || !slices.Equal(keys, []spec.ProviderSkillKey{x}) {
+			t.Fatalf("active = %+v, %v; want x", keys, err)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package session
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+// eviction is the compiled form of a spec.SkillEvictionPolicy. It is immutable once the session is created.
+type eviction struct {
+	src        spec.SkillEvictionPolicy
+	priorities map[spec.SkillDef]int
+}
+
+func newEviction(p *spec.SkillEvictionPolicy) *eviction {
+	if p == nil {
+		return nil
+	}
+	e := &eviction{src: cloneEviction(*p)}
+	if len(p.Priorities) > 0 {
+		e.priorities = make(map[spec.SkillDef]int, len(p.Priorities))
+		for _, sp := range p.Priorities {
+			e.priorities[sp.Def] = sp.Priority
+		}
+	}
+	return e
+}
+
+func (e *eviction) spec() *spec.SkillEvictionPolicy {
+	if e == nil {
+		return nil
+	}
+	out := cloneEviction(e.src)
+	return &out
+}
+
+// candidates returns the keys of order that may be evicted (not pinned, not in keep), first to evict first.
+// A nil eviction policy has no candidates.
+func (e *eviction) candidates(
+	cat Catalog,
+	order []spec.ProviderSkillKey,
+	set map[spec.ProviderSkillKey]activeEntry,
+	keep map[spec.ProviderSkillKey]struct{},
+) []spec.ProviderSkillKey {
+	if e == nil {
+		return nil
+	}
+	out := make([]spec.ProviderSkillKey, 0, len(order))
+	for _, k := range order {
+		if _, ok := keep[k]; ok || set[k].pinned {
+			continue
+		}
+		out = append(out, k)
+	}
+
+	// Order is activation order, so the stable sorts break ties by oldest activation.
+	byUse := func(a, b spec.ProviderSkillKey) int { return set[a].lastUsed.Compare(set[b].lastUsed) }
+	switch e.src.Mode {
+	case spec.SkillEvictionModeOldest:
+	case spec.SkillEvictionModeLRU:
+		slices.SortStableFunc(out, byUse)
+	case spec.SkillEvictionModeLowestPriority:
+		prio := func(k spec.ProviderSkillKey) int {
+			def, _ := cat.DefForKey(k)
+			return e.priorities[def]
+		}
+		slices.SortStableFunc(out, func(a, b spec.ProviderSkillKey) int {
+			return cmp.Or(cmp.Compare(prio(a), prio(b)), byUse(a, b))
+		})
+	default:
+		return nil
+	}
+	return out
+}
+
+func cloneEviction(p spec.SkillEvictionPolicy) spec.SkillEvictionPolicy {
+	return spec.SkillEvictionPolicy{Mode: p.Mode, Priorities: slices.Clone(p.Priorities)}
+}
@@ -0,0 +1,218 @@
+package session
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+func TestSession_ActivateKeys_EvictionPolicies(t *testing.T) {
+	t.Parallel()
+
+	cat := newMemCatalog()
+	a := spec.ProviderSkillKey{Type: "t", Name: "a", Location: "p1"}
+	b := spec.ProviderSkillKey{Type: "t", Name: "b", Location: "p2"}
+	c := spec.ProviderSkillKey{Type: "t", Name: "c", Location: "p3"}
+	cat.add(a, "aaaa")
+	cat.add(b, "bb")
+	cat.add(c, "cccc")
+	handle := func(k spec.ProviderSkillKey) spec.SkillHandle {
+		return spec.SkillHandle{Name: k.Name, Location: k.Location}
+	}
+
+	// newTestSession activates a then b (max 2); b is used more recently unless a is read afterwards.
+	newTestSession := func(t *testing.T, cfg SessionConfig) *Session {
+		t.Helper()
+		cfg.ID = "id"
+		cfg.Catalog = cat
+		cfg.Providers = mapResolver{"t": &canonProvider{typ: "t"}}
+		cfg.Touch = func() {}
+		if cfg.MaxActivePerSession == 0 {
+			cfg.MaxActivePerSession = 2
+		}
+		s := newSession(cfg)
+		if _, err := s.ActivateKeys(t.Context(), []spec.ProviderSkillKey{a, b}, spec.LoadModeReplace); err != nil {
+			t.Fatalf("ActivateKeys: %v", err)
+		}
+		// Make use times deterministic regardless of clock resolution.
+		s.mu.Lock()
+		for i, k := range []spec.ProviderSkillKey{a, b} {
+			e := s.activeSet[k]
+			e.lastUsed = time.Now().Add(time.Duration(i-10) * time.Second)
+			s.activeSet[k] = e
+		}
+		s.mu.Unlock()
+		return s
+	}
+	load := func(t *testing.T, s *Session, k spec.ProviderSkillKey) (spec.LoadOut, error) {
+		t.Helper()
+		return s.toolLoad(t.Context(), spec.LoadArgs{Skills: []spec.SkillHandle{handle(k)}, Mode: spec.LoadModeAdd})
+	}
+
+	tests := []struct {
+		name        string
+		cfg         SessionConfig
+		readA       bool
+		wantEvicted []spec.ProviderSkillKey
+		wantActive  []spec.ProviderSkillKey
+	}{
+		{
+			name:        "lru evicts least recently used",
+			cfg:         SessionConfig{Eviction: &spec.SkillEvictionPolicy{Mode: spec.SkillEvictionModeLRU}},
+			readA:       true,
+			wantEvicted: []spec.ProviderSkillKey{b},
+			wantActive:  []spec.ProviderSkillKey{a, c},
+		},
+		{
+			name:        "oldest ignores use",
+			cfg:         SessionConfig{Eviction: &spec.SkillEvictionPolicy{Mode: spec.SkillEvictionModeOldest}},
+			readA:       true,
+			wantEvicted: []spec.ProviderSkillKey{a},
+			wantActive:  []spec.ProviderSkillKey{b, c},
+		},
+		{
+			name: "lowest priority first",
+			cfg: SessionConfig{Eviction: &spec.SkillEvictionPolicy{
+				Mode:       spec.SkillEvictionModeLowestPriority,
+				Priorities: []spec.SkillPriority{{Def: spec.SkillDef(a), Priority: 5}},
+			}},
+			wantEvicted: []spec.ProviderSkillKey{b},
+			wantActive:  []spec.ProviderSkillKey{a, c},
+		},
+		{
+			name: "byte budget evicts until it fits",
+			cfg: SessionConfig{
+				MaxActivePerSession: 3,
+				MaxActiveBytes:      8,
+				Eviction:            &spec.SkillEvictionPolicy{Mode: spec.SkillEvictionModeOldest},
+			},
+			wantEvicted: []spec.ProviderSkillKey{a},
+			wantActive:  []spec.ProviderSkillKey{b, c},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			s := newTestSession(t, tc.cfg)
+			if tc.readA {
+				_, _ = s.toolRead(t.Context(), spec.ReadResourceArgs{
+					SkillName:        a.Name,
+					SkillLocation:    a.Location,
+					ResourceLocation: "x",
+				})
+			}
+			out, err := load(t, s, c)
+			if err != nil {
+				t.Fatalf("skills-load: %v", err)
+			}
+			var evicted []spec.SkillHandle
+			for _, k := range tc.wantEvicted {
+				evicted = append(evicted, handle(k))
+			}
+			if !slices.Equal(out.EvictedSkills, evicted) {
+				t.Fatalf("expected evicted %+v, got %+v", evicted, out.EvictedSkills)
+			}
+			if got := activeKeysOf(t, s); !slices.Equal(got, tc.wantActive) {
+				t.Fatalf("expected active %+v, got %+v", tc.wantActive, got)
+			}
+		})
+	}
+
+	t.Run("pinned and requested skills are never evicted", func(t *testing.T) {
+		t.Parallel()
+		s := newTestSession(t, SessionConfig{Eviction: &spec.SkillEvictionPolicy{Mode: spec.SkillEvictionModeLRU}})
+		if _, err := s.PinKeys(t.Context(), []spec.ProviderSkillKey{a, b}, spec.LoadModeAdd); err != nil {
+			t.Fatalf("PinKeys: %v", err)
+		}
+		if _, err := load(t, s, c); !errors.Is(err, spec.ErrInvalidArgument) {
+			t.Fatalf("expected ErrInvalidArgument with only pinned skills, got %v", err)
+		}
+		_, err := s.ActivateKeys(t.Context(), []spec.ProviderSkillKey{a, b, c}, spec.LoadModeAdd)
+		if !errors.Is(err, spec.ErrInvalidArgument) {
+			t.Fatalf("expected ErrInvalidArgument when requested skills exceed the limit, got %v", err)
+		}
+	})
+
+	t.Run("without policy activation past the limit fails", func(t *testing.T) {
+		t.Parallel()
+		s := newTestSession(t, SessionConfig{})
+		if _, err := load(t, s, c); !errors.Is(err, spec.ErrInvalidArgument) {
+			t.Fatalf("expected ErrInvalidArgument, got %v", err)
+		}
+		if got := activeKeysOf(t, s); !slices.Equal(got, []spec.ProviderSkillKey{a, b}) {
+			t.Fatalf("expected active set unchanged, got %+v", got)
+		}
+	})
+}
+
+func TestSession_ActivateKeys_EvictionWithRequires(t *testing.T) {
+	t.Parallel()
+
+	a := spec.ProviderSkillKey{Type: "t", Name: "a", Location: "p1"}
+	b := spec.ProviderSkillKey{Type: "t", Name: "b", Location: "p2"}
+	c := spec.ProviderSkillKey{Type: "t", Name: "c", Location: "p3"}
+	handles := func(keys ...spec.ProviderSkillKey) []spec.SkillHandle {
+		out := make([]spec.SkillHandle, 0, len(keys))
+		for _, k := range keys {
+			out = append(out, spec.SkillHandle{Name: k.Name, Location: k.Location})
+		}
+		return out
+	}
+	// newTestSession is limited to 2 active skills, evicts the oldest first, and a requires b.
+	newTestSession := func(t *testing.T) *Session {
+		t.Helper()
+		cat := newMemCatalog()
+		for _, k := range []spec.ProviderSkillKey{a, b, c} {
+			cat.add(k, "body-"+k.Name)
+		}
+		cat.setRequires(a, "b")
+		return newSession(SessionConfig{
+			ID:                  "id",
+			Catalog:             cat,
+			Providers:           mapResolver{"t": &canonProvider{typ: "t"}},
+			Touch:               func() {},
+			MaxActivePerSession: 2,
+			Eviction:            &spec.SkillEvictionPolicy{Mode: spec.SkillEvictionModeOldest},
+		})
+	}
+
+	t.Run("a required skill is evicted with its dependent", func(t *testing.T) {
+		t.Parallel()
+		s := newTestSession(t)
+		if _, err := s.toolLoad(t.Context(), spec.LoadArgs{Skills: handles(a)}); err != nil {
+			t.Fatalf("skills-load a: %v", err)
+		}
+		// b is the oldest but a requires it, so a goes first and takes its implicit dependency b along.
+		out, err := s.toolLoad(t.Context(), spec.LoadArgs{Skills: handles(c), Mode: spec.LoadModeAdd})
+		if err != nil {
+			t.Fatalf("skills-load c: %v", err)
+		}
+		if !slices.Equal(out.EvictedSkills, handles(a, b)) {
+			t.Fatalf("evicted = %+v, want a, b", out.EvictedSkills)
+		}
+		if got := activeKeysOf(t, s); !slices.Equal(got, []spec.ProviderSkillKey{c}) {
+			t.Fatalf("active = %+v, want c", got)
+		}
+	})
+
+	t.Run("an explicitly loaded dependency outlives its dependent", func(t *testing.T) {
+		t.Parallel()
+		s := newTestSession(t)
+		if _, err := s.ActivateKeys(t.Context(), []spec.ProviderSkillKey{b, a}, spec.LoadModeAdd); err != nil {
+			t.Fatalf("ActivateKeys: %v", err)
+		}
+		out, err := s.toolLoad(t.Context(), spec.LoadArgs{Skills: handles(c), Mode: spec.LoadModeAdd})
+		if err != nil {
+			t.Fatalf("skills-load c: %v", err)
+		}
+		if !slices.Equal(out.EvictedSkills, handles(a)) {
+			t.Fatalf("evicted = %+v, want a", out.EvictedSkills)
+		}
+		if got := activeKeysOf(t, s); !slices.Equal(got, []spec.ProviderSkillKey{b, c}) {
+			t.Fatalf("active = %+v, want b, c", got)
+		}
+	})
+}
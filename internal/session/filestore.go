@@ -0,0 +1,402 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+const (
+	stateFileExt     = ".json"
+	tempFilePrefix   = ".tmp-"
+	lockFileName     = ".lock"
+	maxStateBytes    = 1 << 20
+	lockTimeout      = 10 * time.Second
+	lockPollInterval = 5 * time.Millisecond
+	staleLockAge     = 30 * time.Second
+
+	// lockRefreshInterval is how often a lock file held by a long operation has its modification time
+	// refreshed, so a live holder's lock never looks stale however long it is held.
+	lockRefreshInterval = staleLockAge / 3
+)
+
+// FileStateStore is a spec.SessionStateStore keeping one JSON file per session in a directory, so
+// sessions survive restarts and can be shared by several processes using the same directory.
+//
+// A session file's modification time is the session's last-used time. Files are written atomically
+// (temp file + rename) while holding a lock file in the directory.
+type FileStateStore struct {
+	dir string
+
+	// fileMu serializes in-process holders of the directory lock file.
+	fileMu sync.Mutex
+}
+
+// NewFileStateStore creates a FileStateStore in dir (created if missing).
+func NewFileStateStore(dir string) (*FileStateStore, error) {
+	if strings.TrimSpace(dir) == "" {
+		return nil, fmt.Errorf("%w: session store directory is required", spec.ErrInvalidArgument)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create session store directory: %w", err)
+	}
+	return &FileStateStore{dir: dir}, nil
+}
+
+// NewFileStore creates a PersistentStore keeping sessions in a FileStateStore in dir.
+func NewFileStore(dir string, cfg StoreConfig) (*PersistentStore, error) {
+	states, err := NewFileStateStore(dir)
+	if err != nil {
+		return nil, err
+	}
+	return NewPersistentStore(states, cfg), nil
+}
+
+func (fst *FileStateStore) Create(ctx context.Context, state State, lastUsed time.Time) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	id := string(state.ID)
+	if !validSessionID(id) {
+		return false, fmt.Errorf("%w: invalid session id %q", spec.ErrInvalidArgument, id)
+	}
+	unlock, err := fst.lock(false)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+	if _, err := os.Lstat(fst.path(id)); err == nil {
+		return false, nil
+	}
+	if err := fst.writeState(state, lastUsed); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (fst *FileStateStore) Load(ctx context.Context, id spec.SessionID) (State, time.Time, error) {
+	if err := ctx.Err(); err != nil {
+		return State{}, time.Time{}, err
+	}
+	if !validSessionID(string(id)) {
+		return State{}, time.Time{}, spec.ErrSessionNotFound
+	}
+	return fst.readState(string(id))
+}
+
+// Update replaces the session file if its version is prevVersion, keeping the file's last-used time.
+func (fst *FileStateStore) Update(ctx context.Context, state State, prevVersion uint64) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	id := string(state.ID)
+	if !validSessionID(id) {
+		return false, spec.ErrSessionNotFound
+	}
+	unlock, err := fst.lock(false)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	cur, lastUsed, err := fst.readState(id)
+	if err != nil {
+		return false, err
+	}
+	if cur.Version != prevVersion {
+		return false, nil
+	}
+	if err := fst.writeState(state, lastUsed); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Touch sets the session file's modification time. It holds the lock, so a concurrent Update or Delete never
+// loses or misses the new time.
+func (fst *FileStateStore) Touch(ctx context.Context, id spec.SessionID, lastUsed time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !validSessionID(string(id)) {
+		return nil
+	}
+	unlock, err := fst.lock(false)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := os.Chtimes(fst.path(string(id)), lastUsed, lastUsed); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (fst *FileStateStore) Delete(ctx context.Context, id spec.SessionID, unusedSince time.Time) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	if !validSessionID(string(id)) {
+		return false, nil
+	}
+	unlock, err := fst.lock(false)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	p := fst.path(string(id))
+	if !unusedSince.IsZero() {
+		info, err := os.Stat(p)
+		if err != nil || info.ModTime().After(unusedSince) {
+			return false, nil
+		}
+	}
+	if err := os.Remove(p); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// List returns the sessions in the directory with their file modification times. Only the leading createdAt
+// of each session file is read. It also removes temp files left behind by a crashed writer.
+func (fst *FileStateStore) List(ctx context.Context) ([]spec.SessionStateInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	// Listing a large directory can take a while, so the lock is kept fresh.
+	unlock, err := fst.lock(true)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	entries, err := os.ReadDir(fst.dir)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]spec.SessionStateInfo, 0, len(entries))
+	for _, de := range entries {
+		info, err := de.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		name := de.Name()
+		if strings.HasPrefix(name, tempFilePrefix) {
+			// Temp files only exist while their writer holds the lock.
+			_ = os.Remove(filepath.Join(fst.dir, name))
+			continue
+		}
+		id, ok := strings.CutSuffix(name, stateFileExt)
+		if !ok || !validSessionID(id) {
+			continue
+		}
+		out = append(out, spec.SessionStateInfo{
+			ID:        spec.SessionID(id),
+			CreatedAt: fst.readCreatedAt(id),
+			LastUsed:  info.ModTime(),
+		})
+	}
+	return out, nil
+}
+
+func (fst *FileStateStore) path(id string) string {
+	return filepath.Join(fst.dir, id+stateFileExt)
+}
+
+func (fst *FileStateStore) readState(id string) (State, time.Time, error) {
+	f, err := os.Open(fst.path(id))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return State{}, time.Time{}, fmt.Errorf("%w: %s", spec.ErrSessionNotFound, id)
+		}
+		return State{}, time.Time{}, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return State{}, time.Time{}, err
+	}
+	data, err := io.ReadAll(io.LimitReader(f, maxStateBytes+1))
+	if err != nil {
+		return State{}, time.Time{}, err
+	}
+	if len(data) > maxStateBytes {
+		return State{}, time.Time{}, fmt.Errorf("session file %s: too large", id)
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return State{}, time.Time{}, fmt.Errorf("session file %s: %w", id, err)
+	}
+	if string(state.ID) != id {
+		return State{}, time.Time{}, fmt.Errorf("session file %s: id mismatch %q", id, state.ID)
+	}
+	return state, info.ModTime(), nil
+}
+
+// readCreatedAt returns the createdAt of a session file, or the zero time if it cannot be read. State files
+// are written with createdAt right after the id, so decoding stops after the first few tokens.
+func (fst *FileStateStore) readCreatedAt(id string) time.Time {
+	f, err := os.Open(fst.path(id))
+	if err != nil {
+		return time.Time{}
+	}
+	defer f.Close()
+	dec := json.NewDecoder(io.LimitReader(f, maxStateBytes))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return time.Time{}
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return time.Time{}
+		}
+		if tok == "createdAt" {
+			var createdAt time.Time
+			if err := dec.Decode(&createdAt); err != nil {
+				return time.Time{}
+			}
+			return createdAt
+		}
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return time.Time{}
+		}
+	}
+	return time.Time{}
+}
+
+// writeState atomically replaces the session file, setting its modification time to lastUsed.
+func (fst *FileStateStore) writeState(state State, lastUsed time.Time) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(fst.dir, tempFilePrefix+"*")
+	if err != nil {
+		return fmt.Errorf("write session %s: %w", state.ID, err)
+	}
+	tmpName := tmp.Name()
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chtimes(tmpName, lastUsed, lastUsed)
+	}
+	if err == nil {
+		err = os.Rename(tmpName, fst.path(string(state.ID)))
+	}
+	if err != nil {
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("write session %s: %w", state.ID, err)
+	}
+	return nil
+}
+
+// lock acquires the directory lock file, which holds a random owner token. Locks older than staleLockAge
+// are assumed to be left behind by a crashed process and are broken; with refresh, the lock file's
+// modification time is refreshed while the lock is held, for operations that may take that long.
+// Unlocking and breaking only remove the lock file if it still holds the expected token, so a lock taken
+// over by another holder is never removed.
+func (fst *FileStateStore) lock(refresh bool) (func(), error) {
+	fst.fileMu.Lock()
+	p := filepath.Join(fst.dir, lockFileName)
+	token := uuid.NewString()
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		err := createLockFile(p, token)
+		if err == nil {
+			if !refresh {
+				return func() {
+					removeLockIfOwned(p, token)
+					fst.fileMu.Unlock()
+				}, nil
+			}
+			stop, done := make(chan struct{}), make(chan struct{})
+			go refreshLock(p, token, stop, done)
+			return func() {
+				close(stop)
+				<-done
+				removeLockIfOwned(p, token)
+				fst.fileMu.Unlock()
+			}, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			fst.fileMu.Unlock()
+			return nil, fmt.Errorf("lock session store: %w", err)
+		}
+		if info, statErr := os.Stat(p); statErr == nil && time.Since(info.ModTime()) > staleLockAge {
+			if owner, readErr := os.ReadFile(p); readErr == nil {
+				removeLockIfOwned(p, string(owner))
+			}
+			continue
+		}
+		if time.Now().After(deadline) {
+			fst.fileMu.Unlock()
+			return nil, fmt.Errorf("lock session store %s: timed out", fst.dir)
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// createLockFile exclusively creates the lock file at p holding token.
+func createLockFile(p, token string) error {
+	f, err := os.OpenFile(p, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteString(token)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(p)
+		return err
+	}
+	return nil
+}
+
+// refreshLock keeps the lock file at p fresh while it holds token, until stop is closed; it closes done
+// when it returns.
+func refreshLock(p, token string, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	t := time.NewTicker(lockRefreshInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-t.C:
+			if owner, err := os.ReadFile(p); err == nil && string(owner) == token {
+				now := time.Now()
+				_ = os.Chtimes(p, now, now)
+			}
+		}
+	}
+}
+
+// removeLockIfOwned removes the lock file at p if it holds token.
+func removeLockIfOwned(p, token string) {
+	if owner, err := os.ReadFile(p); err == nil && string(owner) == token {
+		_ = os.Remove(p)
+	}
+}
@@ -0,0 +1,518 @@
+package session
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+func newTestFileStore(t *testing.T, dir string, cat Catalog, cfg StoreConfig) *PersistentStore {
+	t.Helper()
+	cfg.Catalog = cat
+	cfg.Providers = mapResolver{}
+	if cfg.TTL == 0 {
+		cfg.TTL = 10 * time.Second
+	}
+	if cfg.MaxActivePerSession == 0 {
+		cfg.MaxActivePerSession = 8
+	}
+	st, err := NewFileStore(dir, cfg)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	return st
+}
+
+func activeKeysOf(t *testing.T, s *Session) []spec.ProviderSkillKey {
+	t.Helper()
+	keys, err := s.ActiveKeys(t.Context())
+	if err != nil {
+		t.Fatalf("ActiveKeys: %v", err)
+	}
+	return keys
+}
+
+func TestFileStore_SessionsSurviveRestart(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	cat := newMemCatalog()
+	a := spec.ProviderSkillKey{Type: "t", Name: "a", Location: "p1"}
+	b := spec.ProviderSkillKey{Type: "t", Name: "b", Location: "p2"}
+	cat.add(a, "ok")
+	cat.add(b, "ok")
+
+	st1 := newTestFileStore(t, dir, cat, StoreConfig{})
+	id, _, err := st1.NewSession(t.Context(), NewSessionParams{
+		MaxActivePerSession: 3,
+		ActiveKeys:          []spec.ProviderSkillKey{b, a},
+	})
+	if err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+
+	st2 := newTestFileStore(t, dir, cat, StoreConfig{})
+	s, ok := st2.Get(id)
+	if !ok {
+		t.Fatalf("expected session %q to be loaded from disk", id)
+	}
+	if got := activeKeysOf(t, s); len(got) != 2 || got[0] != b || got[1] != a {
+		t.Fatalf("expected restored activation order [b a], got %+v", got)
+	}
+	if s.MaxActive() != 3 || s.CreatedAt().IsZero() {
+		t.Fatalf("expected maxActive and createdAt to be restored, got %d %v", s.MaxActive(), s.CreatedAt())
+	}
+	if infos := st2.List(); len(infos) != 1 || infos[0].ID != id || !infos[0].CreatedAt.Equal(s.CreatedAt()) {
+		t.Fatalf("unexpected List: %+v", infos)
+	}
+
+	st2.Delete(id)
+	if _, err := os.Stat(filepath.Join(dir, id+stateFileExt)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected session file removed, got %v", err)
+	}
+	if _, ok := st1.Get(id); ok {
+		t.Fatalf("expected session deleted by another store to be gone")
+	}
+	for _, bad := range []string{"../" + id, "nope", ""} {
+		if _, ok := st1.Get(bad); ok {
+			t.Fatalf("expected Get(%q) to fail", bad)
+		}
+	}
+}
+
+func TestFileStore_StaleWriterGetsRetryableError(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	cat := newMemCatalog()
+	a := spec.ProviderSkillKey{Type: "t", Name: "a", Location: "p1"}
+	b := spec.ProviderSkillKey{Type: "t", Name: "b", Location: "p2"}
+	cat.add(a, "ok")
+	cat.add(b, "ok")
+
+	st1 := newTestFileStore(t, dir, cat, StoreConfig{})
+	st2 := newTestFileStore(t, dir, cat, StoreConfig{})
+	id, _, err := st1.NewSession(t.Context(), NewSessionParams{})
+	if err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+	s1, _ := st1.Get(id)
+	s2, ok := st2.Get(id)
+	if !ok {
+		t.Fatalf("expected session visible to second store")
+	}
+
+	if _, err := s1.ActivateKeys(t.Context(), []spec.ProviderSkillKey{a}, spec.LoadModeAdd); err != nil {
+		t.Fatalf("ActivateKeys: %v", err)
+	}
+	if _, err := s2.ActivateKeys(t.Context(), []spec.ProviderSkillKey{b}, spec.LoadModeAdd); !errors.Is(
+		err,
+		spec.ErrInvalidArgument,
+	) {
+		t.Fatalf("expected stale write to be rejected, got %v", err)
+	}
+
+	s2, _ = st2.Get(id)
+	if got := activeKeysOf(t, s2); len(got) != 1 || got[0] != a {
+		t.Fatalf("expected reload to see [a], got %+v", got)
+	}
+	if _, err := s2.ActivateKeys(t.Context(), []spec.ProviderSkillKey{b}, spec.LoadModeAdd); err != nil {
+		t.Fatalf("ActivateKeys after reload: %v", err)
+	}
+	s1, _ = st1.Get(id)
+	if got := activeKeysOf(t, s1); len(got) != 2 || got[0] != a || got[1] != b {
+		t.Fatalf("expected first store to see [a b], got %+v", got)
+	}
+}
+
+func TestFileStore_CatalogChangesAppliedOnLoad(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	cat := newMemCatalog()
+	a := spec.ProviderSkillKey{Type: "t", Name: "a", Location: "p1"}
+	b := spec.ProviderSkillKey{Type: "t", Name: "b", Location: "p2"}
+	b2 := spec.ProviderSkillKey{Type: "t", Name: "b", Location: "p2-canonical"}
+	c := spec.ProviderSkillKey{Type: "t", Name: "c", Location: "p3"}
+	for _, k := range []spec.ProviderSkillKey{a, b, c} {
+		cat.add(k, "ok")
+	}
+	st1 := newTestFileStore(t, dir, cat, StoreConfig{})
+	id, _, err := st1.NewSession(t.Context(), NewSessionParams{ActiveKeys: []spec.ProviderSkillKey{a, b, c}})
+	if err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+	diskKeys := func() []spec.ProviderSkillKey {
+		t.Helper()
+		state, _, err := st1.states.(*FileStateStore).readState(id)
+		if err != nil {
+			t.Fatalf("readState: %v", err)
+		}
+		var keys []spec.ProviderSkillKey
+		for _, sk := range state.Active {
+			keys = append(keys, sk.Key)
+		}
+		return keys
+	}
+
+	// The session is only on disk for st2, so its file is left alone until st2 loads it.
+	st2 := newTestFileStore(t, dir, cat, StoreConfig{})
+	cat.remove(a)
+	cat.remove(b)
+	cat.add(b2, "ok")
+	if ids := st2.PruneSkill(a); len(ids) != 0 {
+		t.Fatalf("expected no cached session to be pruned, got %v", ids)
+	}
+	st2.RekeySkill(b, b2)
+	// C was removed and registered again, so it stays active.
+	st2.PruneSkill(c)
+	if got := diskKeys(); !slices.Equal(got, []spec.ProviderSkillKey{a, b, c}) {
+		t.Fatalf("expected the session file unchanged before load, got %+v", got)
+	}
+
+	s, _, ok := st2.Peek(id)
+	if !ok {
+		t.Fatalf("expected session %q", id)
+	}
+	skills, err := s.PeekActiveSkills()
+	if err != nil {
+		t.Fatalf("PeekActiveSkills: %v", err)
+	}
+	if len(skills) != 2 || skills[0].Key != b2 || skills[1].Key != c {
+		t.Fatalf("expected [b2 c] after load, got %+v", skills)
+	}
+	if got := diskKeys(); !slices.Equal(got, []spec.ProviderSkillKey{b2, c}) {
+		t.Fatalf("expected the changes persisted, got %+v", got)
+	}
+	// The removal of c is dropped since c is in the catalog again.
+	st2.mu.Lock()
+	_, aRemoved := st2.removed[a]
+	_, cRemoved := st2.removed[c]
+	st2.mu.Unlock()
+	if !aRemoved || cRemoved {
+		t.Fatalf("expected only the removal of a to be kept, got a=%v c=%v", aRemoved, cRemoved)
+	}
+}
+
+func TestFileStore_CatalogChangesDroppedAfterTTL(t *testing.T) {
+	t.Parallel()
+
+	const ttl = 50 * time.Millisecond
+	st := newTestFileStore(t, t.TempDir(), newMemCatalog(), StoreConfig{TTL: ttl})
+	a := spec.ProviderSkillKey{Type: "t", Name: "a", Location: "p1"}
+	b := spec.ProviderSkillKey{Type: "t", Name: "b", Location: "p2"}
+	c := spec.ProviderSkillKey{Type: "t", Name: "c", Location: "p3"}
+
+	st.PruneSkill(a)
+	st.RekeySkill(b, c)
+	time.Sleep(2 * ttl)
+	st.PruneSkill(c)
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if len(st.removed) != 1 || len(st.rekeyed) != 0 {
+		t.Fatalf("expected only the latest change kept, got removed %v rekeyed %v", st.removed, st.rekeyed)
+	}
+	if _, ok := st.removed[c]; !ok {
+		t.Fatalf("expected the removal of c kept, got %v", st.removed)
+	}
+}
+
+func TestFileStore_TTLAndMaxSessionsEviction(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	st := newTestFileStore(t, dir, newMemCatalog(), StoreConfig{TTL: time.Hour, MaxSessions: 2})
+
+	ids := make([]string, 0, 3)
+	for i := range 2 {
+		id, _, err := st.NewSession(t.Context(), NewSessionParams{})
+		if err != nil {
+			t.Fatalf("NewSession: %v", err)
+		}
+		// Make last-used times distinct: ids[0] is the least recently used.
+		old := time.Now().Add(time.Duration(i-10) * time.Minute)
+		if err := os.Chtimes(filepath.Join(dir, id+stateFileExt), old, old); err != nil {
+			t.Fatalf("Chtimes: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	id3, _, err := st.NewSession(t.Context(), NewSessionParams{})
+	if err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+	ids = append(ids, id3)
+
+	if _, ok := st.Get(ids[0]); ok {
+		t.Fatalf("expected LRU session evicted over MaxSessions")
+	}
+	infos := st.List()
+	if len(infos) != 2 || infos[0].ID != ids[2] || infos[1].ID != ids[1] {
+		t.Fatalf("expected [%s %s] most recently used first, got %+v", ids[2], ids[1], infos)
+	}
+
+	expired := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, ids[1]+stateFileExt), expired, expired); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+	if _, ok := st.Get(ids[1]); ok {
+		t.Fatalf("expected expired session to be evicted")
+	}
+	if _, err := os.Stat(filepath.Join(dir, ids[1]+stateFileExt)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected expired session file removed, got %v", err)
+	}
+
+	// The ID of an expired session that was not evicted yet can be reused.
+	if err := os.Chtimes(filepath.Join(dir, ids[2]+stateFileExt), expired, expired); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+	if got, _, err := st.NewSession(t.Context(), NewSessionParams{ID: ids[2]}); err != nil || got != ids[2] {
+		t.Fatalf("NewSession with expired ID = %q, %v", got, err)
+	}
+}
+
+func TestFileStore_BreaksStaleLockAndRejectsLiveID(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	lock := filepath.Join(dir, lockFileName)
+	if err := os.WriteFile(lock, nil, 0o600); err != nil {
+		t.Fatalf("write lock: %v", err)
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(lock, old, old); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+
+	st := newTestFileStore(t, dir, newMemCatalog(), StoreConfig{})
+	const id = "0190f1e2-5b7c-7a3d-9e4f-0123456789ab"
+	if got, _, err := st.NewSession(t.Context(), NewSessionParams{ID: id}); err != nil || got != id {
+		t.Fatalf("NewSession = %q, %v", got, err)
+	}
+	if _, _, err := st.NewSession(t.Context(), NewSessionParams{ID: id}); !errors.Is(err, spec.ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument for live ID, got %v", err)
+	}
+	if _, err := os.Stat(lock); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected lock file released, got %v", err)
+	}
+	if _, err := NewFileStore(" ", StoreConfig{}); !errors.Is(err, spec.ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument for empty dir, got %v", err)
+	}
+}
+
+func TestFileStore_UnlockKeepsLockTakenOverByAnotherHolder(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	lock := filepath.Join(dir, lockFileName)
+	fst, err := NewFileStateStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStateStore: %v", err)
+	}
+
+	unlock, err := fst.lock(false)
+	if err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+	if owner, err := os.ReadFile(lock); err != nil || len(owner) == 0 {
+		t.Fatalf("expected lock file with an owner token, got %q, %v", owner, err)
+	}
+	// Another holder broke the lock and took it over.
+	if err := os.WriteFile(lock, []byte("other"), 0o600); err != nil {
+		t.Fatalf("write lock: %v", err)
+	}
+	unlock()
+	if owner, err := os.ReadFile(lock); err != nil || string(owner) != "other" {
+		t.Fatalf("expected the other holder's lock to be kept, got %q, %v", owner, err)
+	}
+
+	if err := os.Remove(lock); err != nil {
+		t.Fatalf("remove lock: %v", err)
+	}
+	unlock, err = fst.lock(true)
+	if err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+	unlock()
+	if _, err := os.Stat(lock); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected own lock file released, got %v", err)
+	}
+}
+
+func TestFileStore_TouchWaitsForLock(t *testing.T) {
+	t.Parallel()
+
+	fst, err := NewFileStateStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStateStore: %v", err)
+	}
+	const id = "0190f1e2-5b7c-7a3d-9e4f-0123456789ab"
+	created := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if ok, err := fst.Create(t.Context(), State{ID: id}, created); !ok || err != nil {
+		t.Fatalf("Create = %v, %v", ok, err)
+	}
+	lastUsed := func() time.Time {
+		t.Helper()
+		_, lu, err := fst.Load(t.Context(), id)
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		return lu
+	}
+
+	unlock, err := fst.lock(false)
+	if err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+	touched := time.Now().Truncate(time.Second)
+	done := make(chan error, 1)
+	go func() { done <- fst.Touch(t.Context(), id, touched) }()
+	time.Sleep(20 * time.Millisecond)
+	// An Update holding the lock here keeps the time it read; the Touch applies after it.
+	if got := lastUsed(); !got.Equal(created) {
+		t.Fatalf("expected Touch to wait for the lock, last used %v", got)
+	}
+	unlock()
+	if err := <-done; err != nil {
+		t.Fatalf("Touch: %v", err)
+	}
+	if got := lastUsed(); !got.Equal(touched) {
+		t.Fatalf("expected last used %v after Touch, got %v", touched, got)
+	}
+}
+
+func TestFileStore_PolicyPersistedAndEnforced(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	cat := newMemCatalog()
+	a := spec.ProviderSkillKey{Type: "t", Name: "a", Location: "p1"}
+	b := spec.ProviderSkillKey{Type: "t", Name: "b", Location: "p2"}
+	cat.add(a, "ok")
+	cat.add(b, "ok")
+
+	st1 := newTestFileStore(t, dir, cat, StoreConfig{})
+	id, _, err := st1.NewSession(t.Context(), NewSessionParams{
+		Policy: &spec.SessionSkillPolicy{DenySkills: []spec.SkillDef{{Type: "t", Name: "b", Location: "p2"}}},
+	})
+	if err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+
+	st2 := newTestFileStore(t, dir, cat, StoreConfig{})
+	s, ok := st2.Get(id)
+	if !ok {
+		t.Fatalf("expected session %q to be loaded from disk", id)
+	}
+	if p := s.Policy(); p == nil || len(p.DenySkills) != 1 {
+		t.Fatalf("expected restored policy, got %+v", p)
+	}
+	if !s.Allows(a) || s.Allows(b) {
+		t.Fatalf("unexpected Allows: a=%v b=%v", s.Allows(a), s.Allows(b))
+	}
+	if _, err := s.ActivateKeys(t.Context(), []spec.ProviderSkillKey{a, b}, spec.LoadModeAdd); !errors.Is(
+		err,
+		spec.ErrSkillNotAllowed,
+	) {
+		t.Fatalf("expected ErrSkillNotAllowed, got %v", err)
+	}
+	_, err = s.toolLoad(t.Context(), spec.LoadArgs{Skills: []spec.SkillHandle{{Name: "b", Location: "p2"}}})
+	if !errors.Is(err, spec.ErrSkillNotAllowed) {
+		t.Fatalf("skills-load: expected ErrSkillNotAllowed, got %v", err)
+	}
+	if got := activeKeysOf(t, s); len(got) != 0 {
+		t.Fatalf("expected no active skills after rejected loads, got %+v", got)
+	}
+}
+
+func TestFileStore_PinnedKeysPersisted(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	cat := newMemCatalog()
+	a := spec.ProviderSkillKey{Type: "t", Name: "a", Location: "p1"}
+	b := spec.ProviderSkillKey{Type: "t", Name: "b", Location: "p2"}
+	cat.add(a, "ok")
+	cat.add(b, "ok")
+
+	st1 := newTestFileStore(t, dir, cat, StoreConfig{})
+	id, _, err := st1.NewSession(t.Context(), NewSessionParams{
+		ActiveKeys: []spec.ProviderSkillKey{b},
+		PinnedKeys: []spec.ProviderSkillKey{a},
+	})
+	if err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+
+	st2 := newTestFileStore(t, dir, cat, StoreConfig{})
+	s, ok := st2.Get(id)
+	if !ok {
+		t.Fatalf("expected session %q to be loaded from disk", id)
+	}
+	skills, err := s.ActiveSkills(t.Context())
+	if err != nil {
+		t.Fatalf("ActiveSkills: %v", err)
+	}
+	if len(skills) != 2 || skills[0].Key != b || skills[0].Pinned || skills[1].Key != a || !skills[1].Pinned {
+		t.Fatalf("expected [b a*] restored, got %+v", skills)
+	}
+
+	out, err := s.toolUnload(t.Context(), spec.UnloadArgs{All: true})
+	if err != nil {
+		t.Fatalf("toolUnload: %v", err)
+	}
+	if len(out.ActiveSkills) != 1 || len(out.PinnedSkills) != 1 || out.PinnedSkills[0].Name != "a" {
+		t.Fatalf("expected pinned a to survive unload all, got %+v", out)
+	}
+	if _, err := s.DeactivateKeys(t.Context(), []spec.ProviderSkillKey{a}, false); err != nil {
+		t.Fatalf("DeactivateKeys: %v", err)
+	}
+	s, _ = st1.Get(id)
+	if got := activeKeysOf(t, s); len(got) != 0 {
+		t.Fatalf("expected host deactivation of pinned key persisted, got %+v", got)
+	}
+}
+
+func TestFileStore_BudgetPersisted(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	cat := newMemCatalog()
+	a := spec.ProviderSkillKey{Type: "t", Name: "a", Location: "p1"}
+	b := spec.ProviderSkillKey{Type: "t", Name: "b", Location: "p2"}
+	cat.add(a, "12345")
+	cat.add(b, "1234")
+
+	if _, _, err := newTestFileStore(t, dir, cat, StoreConfig{}).NewSession(
+		t.Context(),
+		NewSessionParams{MaxActiveTokens: 1},
+	); !errors.Is(err, spec.ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument for token budget without counter, got %v", err)
+	}
+
+	st1 := newTestFileStore(t, dir, cat, StoreConfig{MaxActiveBytesPerSession: 100})
+	id, _, err := st1.NewSession(t.Context(), NewSessionParams{MaxActiveBytes: 8})
+	if err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+
+	st2 := newTestFileStore(t, dir, cat, StoreConfig{})
+	s, ok := st2.Get(id)
+	if !ok {
+		t.Fatalf("expected session %q to be loaded from disk", id)
+	}
+	if s.MaxActiveBytes() != 8 {
+		t.Fatalf("expected restored byte budget 8, got %d", s.MaxActiveBytes())
+	}
+	if _, err := s.ActivateKeys(t.Context(), []spec.ProviderSkillKey{a, b}, spec.LoadModeReplace); !errors.Is(
+		err,
+		spec.ErrSkillBudgetExceeded,
+	) {
+		t.Fatalf("expected ErrSkillBudgetExceeded, got %v", err)
+	}
+}
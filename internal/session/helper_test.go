@@ -3,9 +3,11 @@ package session
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"sync"
 
+	"github.com/flexigpt/agentskills-go/internal/catalog"
 	"github.com/flexigpt/agentskills-go/spec"
 	llmtoolsgoSpec "github.com/flexigpt/llmtools-go/spec"
 )
@@ -82,6 +84,46 @@ func (c *memCatalog) GetIndex(key spec.ProviderSkillKey) (spec.ProviderSkillInde
 	return r, ok
 }
 
+func (c *memCatalog) DefForKey(key spec.ProviderSkillKey) (spec.SkillDef, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.indexes[key]; !ok {
+		return spec.SkillDef{}, false
+	}
+	return spec.SkillDef{Type: key.Type, Name: key.Name, Location: key.Location}, true
+}
+
+func (c *memCatalog) ResolveRequirement(from spec.ProviderSkillKey, name string) (spec.ProviderSkillKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k := range c.indexes {
+		if k != from && k.Name == name {
+			return k, nil
+		}
+	}
+	return spec.ProviderSkillKey{}, fmt.Errorf("%w: no skill named %q is registered", spec.ErrSkillNotFound, name)
+}
+
+func (c *memCatalog) SelectVersion(key spec.ProviderSkillKey, _ map[string]string) spec.ProviderSkillKey {
+	return key
+}
+
+// Search returns, in key name order, the skills whose name contains query.
+func (c *memCatalog) Search(query string, _ catalog.PromptFilter) []catalog.SearchHit {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var out []catalog.SearchHit
+	for k, idx := range c.indexes {
+		if strings.Contains(k.Name, query) {
+			out = append(out, catalog.SearchHit{Record: idx, Score: 1})
+		}
+	}
+	slices.SortFunc(out, func(a, b catalog.SearchHit) int {
+		return strings.Compare(a.Record.Key.Name, b.Record.Key.Name)
+	})
+	return out
+}
+
 func (c *memCatalog) add(k spec.ProviderSkillKey, body string) {
 	c.addWithHandle(k, spec.SkillHandle{Name: k.Name, Location: k.Location}, body)
 }
@@ -100,6 +142,39 @@ func (c *memCatalog) addWithHandle(k spec.ProviderSkillKey, h spec.SkillHandle,
 	c.handleToKey[h] = k
 }
 
+func (c *memCatalog) remove(k spec.ProviderSkillKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.indexes, k)
+	delete(c.bodies, k)
+	delete(c.handleToKey, c.handles[k])
+	delete(c.handles, k)
+}
+
+func (c *memCatalog) setConflicts(k spec.ProviderSkillKey, names ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	idx := c.indexes[k]
+	idx.Conflicts = names
+	c.indexes[k] = idx
+}
+
+func (c *memCatalog) setDigest(k spec.ProviderSkillKey, digest string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	idx := c.indexes[k]
+	idx.Digest = digest
+	c.indexes[k] = idx
+}
+
+func (c *memCatalog) setRequires(k spec.ProviderSkillKey, names ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	idx := c.indexes[k]
+	idx.Requires = names
+	c.indexes[k] = idx
+}
+
 type canonProvider struct {
 	typ string
 	// If def.Location == relStr, normalize to absStr.
@@ -0,0 +1,507 @@
+package session
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+// TouchGranularity bounds how often a session's last-used time is written to the state store.
+const touchGranularity = time.Second
+
+// PersistentStore is a SessionStore keeping sessions in a spec.SessionStateStore, so sessions survive
+// restarts and can be shared by several processes using the same state store.
+//
+// Semantics:
+//   - The state store keeps each session's last-used time. TTL and MaxSessions eviction (least recently
+//     used first) are applied when sessions are created and listed, and on lookup.
+//   - Each process caches *Session values; a lookup reloads a cached session whose stored version changed.
+//     A mutation based on a stale version fails with a retryable error instead of overwriting.
+//   - Skill removals and rekeys apply to cached sessions immediately and to other sessions when they are
+//     loaded, so catalog changes never go through every stored session.
+//   - SessionStore methods without a context call the state store with a background context.
+type PersistentStore struct {
+	states      spec.SessionStateStore
+	ttl         time.Duration
+	maxSessions int
+	cfg         StoreConfig
+
+	// Mu guards cache, removed and rekeyed. It is never held while taking a session lock or calling the
+	// state store.
+	mu    sync.Mutex
+	cache map[string]*cachedSession
+
+	// Removed and rekeyed record the catalog changes of PruneSkill and RekeySkill and when they were made;
+	// sessions that are not cached get them applied when they are loaded. A change is dropped once its key is
+	// in the catalog again, or after the TTL: by then every session stored before the change was loaded (and
+	// got the change applied) or expired, unless another process used it meanwhile.
+	removed map[spec.ProviderSkillKey]time.Time
+	rekeyed map[spec.ProviderSkillKey]rekey
+}
+
+type rekey struct {
+	to spec.ProviderSkillKey
+	at time.Time
+}
+
+type cachedSession struct {
+	s *Session
+
+	// Version is the stored state version the cached session reflects.
+	version   uint64
+	lastTouch time.Time
+}
+
+// NewPersistentStore creates a PersistentStore keeping sessions in states.
+func NewPersistentStore(states spec.SessionStateStore, cfg StoreConfig) *PersistentStore {
+	ttl := cfg.TTL
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	maxS := cfg.MaxSessions
+	if maxS <= 0 {
+		maxS = 4096
+	}
+	return &PersistentStore{
+		states:      states,
+		ttl:         ttl,
+		maxSessions: maxS,
+		cfg:         cfg,
+		cache:       map[string]*cachedSession{},
+		removed:     map[spec.ProviderSkillKey]time.Time{},
+		rekeyed:     map[spec.ProviderSkillKey]rekey{},
+	}
+}
+
+func (st *PersistentStore) NewSession(ctx context.Context, p NewSessionParams) (string, []spec.SkillHandle, error) {
+	if ctx == nil {
+		return "", nil, fmt.Errorf("%w: nil context", spec.ErrInvalidArgument)
+	}
+	if err := ctx.Err(); err != nil {
+		return "", nil, err
+	}
+	now := time.Now()
+
+	id := p.ID
+	if id == "" {
+		u, err := uuid.NewV7()
+		if err != nil {
+			return "", nil, fmt.Errorf("new session id: %w", err)
+		}
+		id = u.String()
+	} else if !validSessionID(id) {
+		return "", nil, fmt.Errorf("%w: invalid session id %q", spec.ErrInvalidArgument, id)
+	}
+	maxActive := st.cfg.MaxActivePerSession
+	if p.MaxActivePerSession > 0 {
+		maxActive = p.MaxActivePerSession
+	}
+	maxBytes, maxTokens, err := st.cfg.sessionBudget(p)
+	if err != nil {
+		return "", nil, err
+	}
+
+	state := State{
+		ID:              spec.SessionID(id),
+		CreatedAt:       now,
+		MaxActive:       maxActive,
+		MaxActiveBytes:  maxBytes,
+		MaxActiveTokens: maxTokens,
+		Policy:          p.Policy,
+		Eviction:        p.Eviction,
+		Versions:        p.Versions,
+	}
+	events := &heldEvents{notify: st.cfg.Notify}
+	s := st.newCachedSession(state, events.add)
+	created, err := st.states.Create(ctx, state, now)
+	if err == nil && !created {
+		// The ID may belong to an expired session that was not evicted yet.
+		if _, lastUsed, loadErr := st.states.Load(ctx, state.ID); loadErr == nil && st.expired(lastUsed, now) {
+			st.evictSession(ctx, id, now.Add(-st.ttl), spec.SessionEvictionReasonTTL)
+			created, err = st.states.Create(ctx, state, now)
+		}
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("create session %s: %w", id, err)
+	}
+	if !created {
+		return "", nil, fmt.Errorf("%w: session id already in use: %q", spec.ErrInvalidArgument, id)
+	}
+	st.mu.Lock()
+	if old := st.cache[id]; old != nil {
+		old.s.closed.Store(true)
+	}
+	st.cache[id] = &cachedSession{s: s, lastTouch: now}
+	st.mu.Unlock()
+
+	var active []spec.SkillHandle
+	if len(p.ActiveKeys) > 0 || len(p.PinnedKeys) > 0 {
+		res, err := s.activateInitial(ctx, p)
+		if err != nil {
+			// The caller never gets the session, so it is dropped without any event.
+			_, _ = st.states.Delete(context.Background(), state.ID, time.Time{})
+			st.forget(id)
+			return "", nil, err
+		}
+		active = res.Active
+	}
+
+	// Sessions over the limit are evicted once the new session is created, so session.created comes first.
+	events.release(id)
+	st.evict(ctx, now)
+	return id, active, nil
+}
+
+func (st *PersistentStore) Get(id string) (*Session, bool) {
+	s, _, ok := st.load(id, time.Now())
+	if !ok {
+		return nil, false
+	}
+	st.Touch(id)
+	return s, true
+}
+
+// Peek returns a live session and its info without marking it as used.
+func (st *PersistentStore) Peek(id string) (*Session, Info, bool) {
+	s, lastUsed, ok := st.load(id, time.Now())
+	if !ok {
+		return nil, Info{}, false
+	}
+	return s, Info{ID: id, CreatedAt: s.createdAt, LastUsed: lastUsed, ExpiresAt: lastUsed.Add(st.ttl)}, true
+}
+
+func (st *PersistentStore) Delete(id string) bool {
+	if !validSessionID(id) {
+		return false
+	}
+	deleted, err := st.states.Delete(context.Background(), spec.SessionID(id), time.Time{})
+	st.forget(id)
+	if err != nil || !deleted {
+		return false
+	}
+	st.notify(Event{Type: spec.EventTypeSessionClosed, SessionID: id})
+	return true
+}
+
+// PruneSkill removes the given key from the cached sessions' active lists and returns the affected session
+// IDs. Other sessions are pruned when they are loaded (see applyCatalogChanges).
+func (st *PersistentStore) PruneSkill(key spec.ProviderSkillKey) []string {
+	now := time.Now()
+	st.mu.Lock()
+	st.dropCatalogChangesLocked(now)
+	st.removed[key] = now
+	sessions := st.cachedSessionsLocked()
+	st.mu.Unlock()
+
+	var ids []string
+	for _, s := range sessions {
+		if s.pruneKey(key) {
+			ids = append(ids, s.id)
+		}
+	}
+	return ids
+}
+
+// RekeySkill replaces oldKey with newKey in the cached sessions' active lists. Other sessions are rekeyed
+// when they are loaded (see applyCatalogChanges).
+func (st *PersistentStore) RekeySkill(oldKey, newKey spec.ProviderSkillKey) {
+	if oldKey == newKey {
+		return
+	}
+	now := time.Now()
+	st.mu.Lock()
+	st.dropCatalogChangesLocked(now)
+	st.rekeyed[oldKey] = rekey{to: newKey, at: now}
+	sessions := st.cachedSessionsLocked()
+	st.mu.Unlock()
+
+	for _, s := range sessions {
+		s.replaceKey(oldKey, newKey)
+	}
+}
+
+// Touch sets the session's last-used time to now (at most once per second per session).
+func (st *PersistentStore) Touch(id string) {
+	now := time.Now()
+	st.mu.Lock()
+	e := st.cache[id]
+	if e == nil || now.Sub(e.lastTouch) < touchGranularity {
+		st.mu.Unlock()
+		return
+	}
+	e.lastTouch = now
+	st.mu.Unlock()
+
+	_ = st.states.Touch(context.Background(), spec.SessionID(id), now)
+}
+
+// List returns live sessions, most recently used first.
+func (st *PersistentStore) List() []Info {
+	return st.evict(context.Background(), time.Now())
+}
+
+// load returns the cached session for id and its last-used time, (re)loading it from the state store when
+// its version changed and then applying the recorded catalog changes. It does not mark the session as used.
+func (st *PersistentStore) load(id string, now time.Time) (*Session, time.Time, bool) {
+	if !validSessionID(id) {
+		return nil, time.Time{}, false
+	}
+	state, lastUsed, err := st.states.Load(context.Background(), spec.SessionID(id))
+	if err != nil {
+		st.forget(id)
+		return nil, time.Time{}, false
+	}
+	if st.expired(lastUsed, now) {
+		st.evictSession(context.Background(), id, now.Add(-st.ttl), spec.SessionEvictionReasonTTL)
+		return nil, time.Time{}, false
+	}
+
+	st.mu.Lock()
+	e := st.cache[id]
+	if e != nil && !e.s.isClosed() && e.version == state.Version {
+		st.mu.Unlock()
+		return e.s, lastUsed, true
+	}
+	st.mu.Unlock()
+
+	var s *Session
+	if e != nil && !e.s.isClosed() {
+		s = e.s
+	} else {
+		s = st.newCachedSession(state, st.cfg.Notify)
+	}
+	// Restore outside st.mu: session locks are never taken while holding it.
+	s.restore(state)
+
+	st.mu.Lock()
+	cur := st.cache[id]
+	switch {
+	case cur == nil || cur.s.isClosed():
+		st.cache[id] = &cachedSession{s: s, version: state.Version, lastTouch: lastUsed}
+	case cur.s == s:
+		cur.version = state.Version
+	default:
+		// Another goroutine cached the session concurrently; use that one.
+		s = cur.s
+	}
+	st.mu.Unlock()
+
+	st.applyCatalogChanges(s)
+	return s, lastUsed, true
+}
+
+// applyCatalogChanges applies the keys removed or rekeyed by PruneSkill and RekeySkill to a session loaded
+// from the state store. Keys that are in the catalog again are left alone, so a skill that was registered
+// again after its removal stays active.
+func (st *PersistentStore) applyCatalogChanges(s *Session) {
+	skills, err := s.PeekActiveSkills()
+	if err != nil {
+		return
+	}
+	type change struct {
+		from, to spec.ProviderSkillKey
+		remove   bool
+	}
+	var changes []change
+	st.mu.Lock()
+	st.dropCatalogChangesLocked(time.Now())
+	if len(st.removed) == 0 && len(st.rekeyed) == 0 {
+		st.mu.Unlock()
+		return
+	}
+	for _, a := range skills {
+		to := a.Key
+		for range len(st.rekeyed) {
+			next, ok := st.rekeyed[to]
+			if !ok {
+				break
+			}
+			to = next.to
+		}
+		_, removed := st.removed[to]
+		if removed || to != a.Key {
+			changes = append(changes, change{from: a.Key, to: to, remove: removed})
+		}
+	}
+	st.mu.Unlock()
+
+	var prune, back []spec.ProviderSkillKey
+	for _, c := range changes {
+		if _, ok := st.cfg.Catalog.GetIndex(c.from); ok {
+			back = append(back, c.from)
+			continue
+		}
+		if _, ok := st.cfg.Catalog.GetIndex(c.to); c.remove && !ok {
+			prune = append(prune, c.from)
+		} else if c.to != c.from {
+			s.replaceKey(c.from, c.to)
+		}
+	}
+	s.pruneKeys(prune)
+
+	if len(back) > 0 {
+		st.mu.Lock()
+		for _, k := range back {
+			delete(st.removed, k)
+			delete(st.rekeyed, k)
+		}
+		st.mu.Unlock()
+	}
+}
+
+// dropCatalogChangesLocked drops the recorded catalog changes older than the TTL. Callers must hold st.mu.
+func (st *PersistentStore) dropCatalogChangesLocked(now time.Time) {
+	for k, at := range st.removed {
+		if st.expired(at, now) {
+			delete(st.removed, k)
+		}
+	}
+	for k, r := range st.rekeyed {
+		if st.expired(r.at, now) {
+			delete(st.rekeyed, k)
+		}
+	}
+}
+
+// save is the Persist hook of cached sessions. It refuses to overwrite a state that changed since the
+// cached session was loaded.
+func (st *PersistentStore) save(next State) error {
+	id := string(next.ID)
+	st.mu.Lock()
+	e := st.cache[id]
+	st.mu.Unlock()
+	if e == nil {
+		return spec.ErrSessionNotFound
+	}
+
+	ok, err := st.states.Update(context.Background(), next, e.version)
+	if err != nil {
+		return fmt.Errorf("save session %s: %w", id, err)
+	}
+	if !ok {
+		return fmt.Errorf("%w: concurrent session modification; please retry", spec.ErrInvalidArgument)
+	}
+	st.mu.Lock()
+	if e := st.cache[id]; e != nil {
+		e.version = next.Version
+	}
+	st.mu.Unlock()
+	return nil
+}
+
+// newCachedSession creates the cached session for a stored state, notifying its events to notify; the active
+// skills are not restored.
+func (st *PersistentStore) newCachedSession(state State, notify func(Event)) *Session {
+	id := string(state.ID)
+	return newSession(SessionConfig{
+		ID:                  id,
+		CreatedAt:           state.CreatedAt,
+		Catalog:             st.cfg.Catalog,
+		Providers:           st.cfg.Providers,
+		MaxActivePerSession: state.MaxActive,
+		MaxActiveBytes:      state.MaxActiveBytes,
+		MaxActiveTokens:     state.MaxActiveTokens,
+		TokenCounter:        st.cfg.TokenCounter,
+		Policy:              state.Policy,
+		Eviction:            state.Eviction,
+		Versions:            state.Versions,
+		Touch:               func() { st.Touch(id) },
+		Persist:             st.save,
+		Notify:              notify,
+	})
+}
+
+// cachedSessionsLocked returns the open cached sessions. Callers must hold st.mu.
+func (st *PersistentStore) cachedSessionsLocked() []*Session {
+	out := make([]*Session, 0, len(st.cache))
+	for _, e := range st.cache {
+		if !e.s.isClosed() {
+			out = append(out, e.s)
+		}
+	}
+	return out
+}
+
+// evict removes expired sessions and the least recently used sessions over MaxSessions, and returns the
+// remaining sessions, most recently used first.
+func (st *PersistentStore) evict(ctx context.Context, now time.Time) []Info {
+	stored, err := st.states.List(ctx)
+	if err != nil {
+		return nil
+	}
+	live := make([]Info, 0, len(stored))
+	for _, info := range stored {
+		id := string(info.ID)
+		if !validSessionID(id) {
+			continue
+		}
+		if st.expired(info.LastUsed, now) {
+			st.evictSession(ctx, id, now.Add(-st.ttl), spec.SessionEvictionReasonTTL)
+			continue
+		}
+		live = append(live, Info{
+			ID:        id,
+			CreatedAt: info.CreatedAt,
+			LastUsed:  info.LastUsed,
+			ExpiresAt: info.LastUsed.Add(st.ttl),
+		})
+	}
+	slices.SortFunc(live, func(a, b Info) int {
+		return cmp.Or(b.LastUsed.Compare(a.LastUsed), strings.Compare(b.ID, a.ID))
+	})
+	if len(live) <= st.maxSessions {
+		return live
+	}
+	for _, info := range live[st.maxSessions:] {
+		st.evictSession(ctx, info.ID, info.LastUsed, spec.SessionEvictionReasonLRU)
+	}
+	return live[:st.maxSessions]
+}
+
+// evictSession removes a session unless it was used after unusedSince (e.g. by another process meanwhile)
+// and notifies its eviction.
+func (st *PersistentStore) evictSession(
+	ctx context.Context,
+	id string,
+	unusedSince time.Time,
+	reason spec.SessionEvictionReason,
+) {
+	deleted, err := st.states.Delete(ctx, spec.SessionID(id), unusedSince)
+	if err != nil || !deleted {
+		return
+	}
+	st.forget(id)
+	st.notify(Event{Type: spec.EventTypeSessionEvicted, SessionID: id, Reason: reason})
+}
+
+func (st *PersistentStore) notify(ev Event) {
+	if st.cfg.Notify != nil {
+		st.cfg.Notify(ev)
+	}
+}
+
+func (st *PersistentStore) forget(id string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if e := st.cache[id]; e != nil {
+		e.s.closed.Store(true)
+		delete(st.cache, id)
+	}
+}
+
+func (st *PersistentStore) expired(lastUsed, now time.Time) bool {
+	return now.Sub(lastUsed) > st.ttl
+}
+
+func validSessionID(id string) bool {
+	u, err := uuid.Parse(id)
+	return err == nil && u.String() == id
+}
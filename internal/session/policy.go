@@ -0,0 +1,103 @@
+package session
+
+import (
+	"slices"
+
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+// policy is the compiled form of a spec.SessionSkillPolicy. It is immutable once the session is created.
+type policy struct {
+	src spec.SessionSkillPolicy
+
+	allowSkills map[spec.SkillDef]struct{}
+	denySkills  map[spec.SkillDef]struct{}
+	allowTags   map[string]struct{}
+	denyTags    map[string]struct{}
+	allowTypes  map[string]struct{}
+	denyTypes   map[string]struct{}
+}
+
+func newPolicy(p *spec.SessionSkillPolicy) *policy {
+	if p == nil {
+		return nil
+	}
+	return &policy{
+		src:         clonePolicy(*p),
+		allowSkills: setOf(p.AllowSkills),
+		denySkills:  setOf(p.DenySkills),
+		allowTags:   setOf(p.AllowTags),
+		denyTags:    setOf(p.DenyTags),
+		allowTypes:  setOf(p.AllowTypes),
+		denyTypes:   setOf(p.DenyTypes),
+	}
+}
+
+// allows reports whether a skill (host def + SKILL.md tags) is permitted. A nil policy allows everything.
+func (p *policy) allows(def spec.SkillDef, tags []string) bool {
+	if p == nil {
+		return true
+	}
+	if _, ok := p.denySkills[def]; ok {
+		return false
+	}
+	if _, ok := p.denyTypes[def.Type]; ok {
+		return false
+	}
+	if hasAny(p.denyTags, tags) {
+		return false
+	}
+	if len(p.allowSkills) > 0 {
+		if _, ok := p.allowSkills[def]; !ok {
+			return false
+		}
+	}
+	if len(p.allowTypes) > 0 {
+		if _, ok := p.allowTypes[def.Type]; !ok {
+			return false
+		}
+	}
+	if len(p.allowTags) > 0 && !hasAny(p.allowTags, tags) {
+		return false
+	}
+	return true
+}
+
+func (p *policy) spec() *spec.SessionSkillPolicy {
+	if p == nil {
+		return nil
+	}
+	out := clonePolicy(p.src)
+	return &out
+}
+
+func clonePolicy(p spec.SessionSkillPolicy) spec.SessionSkillPolicy {
+	return spec.SessionSkillPolicy{
+		AllowSkills: slices.Clone(p.AllowSkills),
+		DenySkills:  slices.Clone(p.DenySkills),
+		AllowTags:   slices.Clone(p.AllowTags),
+		DenyTags:    slices.Clone(p.DenyTags),
+		AllowTypes:  slices.Clone(p.AllowTypes),
+		DenyTypes:   slices.Clone(p.DenyTypes),
+	}
+}
+
+func setOf[T comparable](vs []T) map[T]struct{} {
+	if len(vs) == 0 {
+		return nil
+	}
+	out := make(map[T]struct{}, len(vs))
+	for _, v := range vs {
+		out[v] = struct{}{}
+	}
+	return out
+}
+
+func hasAny(set map[string]struct{}, vs []string) bool {
+	for _, v := range vs {
+		if _, ok := set[v]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,209 @@
+package session
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+func TestSession_ActivateKeys_Requires(t *testing.T) {
+	t.Parallel()
+
+	a := spec.ProviderSkillKey{Type: "t", Name: "a", Location: "p1"}
+	b := spec.ProviderSkillKey{Type: "t", Name: "b", Location: "p2"}
+	c := spec.ProviderSkillKey{Type: "t", Name: "c", Location: "p3"}
+	d := spec.ProviderSkillKey{Type: "t", Name: "d", Location: "p4"}
+	handles := func(keys ...spec.ProviderSkillKey) []spec.SkillHandle {
+		out := make([]spec.SkillHandle, 0, len(keys))
+		for _, k := range keys {
+			out = append(out, spec.SkillHandle{Name: k.Name, Location: k.Location})
+		}
+		return out
+	}
+	newTestSession := func(t *testing.T, requires map[spec.ProviderSkillKey][]string) *Session {
+		t.Helper()
+		cat := newMemCatalog()
+		for _, k := range []spec.ProviderSkillKey{a, b, c, d} {
+			cat.add(k, "body-"+k.Name)
+			cat.setRequires(k, requires[k]...)
+		}
+		return newSession(SessionConfig{
+			ID:        "id",
+			Catalog:   cat,
+			Providers: mapResolver{"t": &canonProvider{typ: "t"}},
+			Touch:     func() {},
+		})
+	}
+
+	t.Run("transitive dependencies activate first and unload cascades", func(t *testing.T) {
+		t.Parallel()
+		s := newTestSession(t, map[spec.ProviderSkillKey][]string{a: {"b"}, b: {"c"}})
+
+		out, err := s.toolLoad(t.Context(), spec.LoadArgs{Skills: handles(a)})
+		if err != nil {
+			t.Fatalf("toolLoad: %v", err)
+		}
+		if !slices.Equal(out.ActiveSkills, handles(c, b, a)) {
+			t.Fatalf("active = %+v, want c, b, a", out.ActiveSkills)
+		}
+		if !slices.Equal(out.DependencySkills, handles(c, b)) {
+			t.Fatalf("dependencies = %+v, want c, b", out.DependencySkills)
+		}
+		skills, err := s.ActiveSkills(t.Context())
+		if err != nil {
+			t.Fatalf("ActiveSkills: %v", err)
+		}
+		for _, sk := range skills {
+			if want := sk.Key != a; sk.Implicit != want {
+				t.Fatalf("%s implicit = %v, want %v", sk.Key.Name, sk.Implicit, want)
+			}
+		}
+
+		// Already active dependencies are not reported again.
+		out, err = s.toolLoad(t.Context(), spec.LoadArgs{Skills: handles(a), Mode: spec.LoadModeAdd})
+		if err != nil {
+			t.Fatalf("toolLoad: %v", err)
+		}
+		if len(out.DependencySkills) != 0 {
+			t.Fatalf("dependencies = %+v, want none", out.DependencySkills)
+		}
+
+		un, err := s.toolUnload(t.Context(), spec.UnloadArgs{Skills: handles(a), Cascade: true})
+		if err != nil {
+			t.Fatalf("toolUnload: %v", err)
+		}
+		if len(un.ActiveSkills) != 0 {
+			t.Fatalf("active after cascade = %+v, want none", un.ActiveSkills)
+		}
+	})
+
+	t.Run("unload without cascade keeps dependencies", func(t *testing.T) {
+		t.Parallel()
+		s := newTestSession(t, map[spec.ProviderSkillKey][]string{a: {"b"}})
+		if _, err := s.ActivateKeys(t.Context(), []spec.ProviderSkillKey{a, d}, spec.LoadModeReplace); err != nil {
+			t.Fatalf("ActivateKeys: %v", err)
+		}
+		un, err := s.toolUnload(t.Context(), spec.UnloadArgs{Skills: handles(a)})
+		if err != nil {
+			t.Fatalf("toolUnload: %v", err)
+		}
+		if !slices.Equal(un.ActiveSkills, handles(b, d)) {
+			t.Fatalf("active = %+v, want b, d", un.ActiveSkills)
+		}
+	})
+
+	t.Run("explicitly loaded dependency survives cascade", func(t *testing.T) {
+		t.Parallel()
+		s := newTestSession(t, map[spec.ProviderSkillKey][]string{a: {"b"}})
+		if _, err := s.ActivateKeys(t.Context(), []spec.ProviderSkillKey{b}, spec.LoadModeReplace); err != nil {
+			t.Fatalf("ActivateKeys: %v", err)
+		}
+		if _, err := s.ActivateKeys(t.Context(), []spec.ProviderSkillKey{a}, spec.LoadModeAdd); err != nil {
+			t.Fatalf("ActivateKeys: %v", err)
+		}
+		un, err := s.toolUnload(t.Context(), spec.UnloadArgs{Skills: handles(a), Cascade: true})
+		if err != nil {
+			t.Fatalf("toolUnload: %v", err)
+		}
+		if !slices.Equal(un.ActiveSkills, handles(b)) {
+			t.Fatalf("active = %+v, want b", un.ActiveSkills)
+		}
+	})
+
+	t.Run("unloading a required skill keeps it as a dependency", func(t *testing.T) {
+		t.Parallel()
+		s := newTestSession(t, map[spec.ProviderSkillKey][]string{a: {"b"}, b: {"c"}})
+		if _, err := s.PinKeys(t.Context(), []spec.ProviderSkillKey{b}, spec.LoadModeReplace); err != nil {
+			t.Fatalf("PinKeys: %v", err)
+		}
+		if _, err := s.ActivateKeys(t.Context(), []spec.ProviderSkillKey{a, d}, spec.LoadModeAdd); err != nil {
+			t.Fatalf("ActivateKeys: %v", err)
+		}
+
+		// A still requires b, and b requires c: both stay, b now as an unpinned implicit dependency.
+		for _, deactivate := range []func() error{
+			func() error {
+				_, err := s.toolUnload(t.Context(), spec.UnloadArgs{Skills: handles(c)})
+				return err
+			},
+			func() error {
+				_, err := s.DeactivateKeys(t.Context(), []spec.ProviderSkillKey{b, c}, false)
+				return err
+			},
+		} {
+			if err := deactivate(); err != nil {
+				t.Fatalf("deactivate: %v", err)
+			}
+			if keys := activeKeysOf(t, s); !slices.Equal(keys, []spec.ProviderSkillKey{c, b, a, d}) {
+				t.Fatalf("active = %+v, want c, b, a, d", keys)
+			}
+		}
+		skills, err := s.ActiveSkills(t.Context())
+		if err != nil {
+			t.Fatalf("ActiveSkills: %v", err)
+		}
+		if sk := skills[1]; sk.Key != b || sk.Pinned || !sk.Implicit {
+			t.Fatalf("b = %+v, want unpinned implicit", sk)
+		}
+
+		// The kept dependencies go with their last dependent.
+		un, err := s.toolUnload(t.Context(), spec.UnloadArgs{Skills: handles(a), Cascade: true})
+		if err != nil {
+			t.Fatalf("toolUnload: %v", err)
+		}
+		if !slices.Equal(un.ActiveSkills, handles(d)) {
+			t.Fatalf("active after cascade = %+v, want d", un.ActiveSkills)
+		}
+	})
+
+	t.Run("replace keeps dependencies of pinned skills", func(t *testing.T) {
+		t.Parallel()
+		s := newTestSession(t, map[spec.ProviderSkillKey][]string{a: {"b"}})
+		if _, err := s.PinKeys(t.Context(), []spec.ProviderSkillKey{a}, spec.LoadModeReplace); err != nil {
+			t.Fatalf("PinKeys: %v", err)
+		}
+		out, err := s.toolLoad(t.Context(), spec.LoadArgs{Skills: handles(d)})
+		if err != nil {
+			t.Fatalf("toolLoad: %v", err)
+		}
+		if !slices.Equal(out.ActiveSkills, handles(b, a, d)) {
+			t.Fatalf("active = %+v, want b, a, d", out.ActiveSkills)
+		}
+	})
+
+	errTests := []struct {
+		name     string
+		requires map[spec.ProviderSkillKey][]string
+		wantErr  error
+		wantMsg  string
+	}{
+		{
+			name:     "cycle",
+			requires: map[spec.ProviderSkillKey][]string{a: {"b"}, b: {"c"}, c: {"b"}},
+			wantErr:  spec.ErrSkillDependencyCycle,
+			wantMsg:  "b -> c -> b",
+		},
+		{
+			name:     "missing dependency",
+			requires: map[spec.ProviderSkillKey][]string{a: {"b"}, b: {"zzz"}},
+			wantErr:  spec.ErrSkillNotFound,
+			wantMsg:  `skill "b" requires "zzz"`,
+		},
+	}
+	for _, tt := range errTests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s := newTestSession(t, tt.requires)
+			_, err := s.ActivateKeys(t.Context(), []spec.ProviderSkillKey{a}, spec.LoadModeReplace)
+			if !errors.Is(err, tt.wantErr) || !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Fatalf("err = %v, want %v containing %q", err, tt.wantErr, tt.wantMsg)
+			}
+			if keys, _ := s.ActiveKeys(t.Context()); len(keys) != 0 {
+				t.Fatalf("active = %+v, want none", keys)
+			}
+		})
+	}
+}
@@ -3,10 +3,12 @@ package session
 import (
 	"context"
 	"fmt"
+	"maps"
 	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/flexigpt/agentskills-go/internal/catalog"
 	"github.com/flexigpt/agentskills-go/spec"
@@ -21,49 +23,166 @@ type Catalog interface {
 	HandleForKey(key spec.ProviderSkillKey) (spec.SkillHandle, bool)
 	EnsureBody(ctx context.Context, key spec.ProviderSkillKey) (string, error)
 	GetIndex(key spec.ProviderSkillKey) (spec.ProviderSkillIndexRecord, bool)
+	DefForKey(key spec.ProviderSkillKey) (spec.SkillDef, bool)
+	ResolveRequirement(from spec.ProviderSkillKey, name string) (spec.ProviderSkillKey, error)
+	SelectVersion(key spec.ProviderSkillKey, pins map[string]string) spec.ProviderSkillKey
+	Search(query string, f catalog.PromptFilter) []catalog.SearchHit
 }
 
 type SessionConfig struct {
 	ID                  string
+	CreatedAt           time.Time
 	Catalog             Catalog
 	Providers           ProviderResolver
 	MaxActivePerSession int
 	Touch               func() // store-provided "touch" to keep TTL/LRU alive
+
+	// Policy optionally restricts which skills may be activated (nil allows all).
+	Policy *spec.SessionSkillPolicy
+
+	// Eviction optionally lets activations past the limits unload other skills (nil fails instead).
+	Eviction *spec.SkillEvictionPolicy
+
+	// Versions optionally pins which version of a multi-version skill (by name) LLM-facing handles resolve
+	// to in this session, overriding the catalog-level pins.
+	Versions map[string]string
+
+	// MaxActiveBytes and MaxActiveTokens optionally limit the combined SKILL.md body size of the active
+	// skills (<= 0 means unlimited). A token budget requires TokenCounter.
+	MaxActiveBytes  int
+	MaxActiveTokens int
+	TokenCounter    spec.TokenCounter
+
+	// Persist is an optional store-provided hook called with the next state before a host/tool mutation
+	// is committed; an error aborts the mutation. Internal pruning/rekeying persists best-effort.
+	Persist func(State) error
+
+	// Notify is an optional hook receiving activation events for host/tool mutations (see StoreConfig.Notify).
+	Notify func(Event)
 }
 
+// State is the serializable state of a session, used by persistent stores.
+type State = spec.SessionState
+
 type Session struct {
-	id string
+	id        string
+	createdAt time.Time
 
 	catalog   Catalog
 	providers ProviderResolver
 
+	maxActiveBytes  int
+	maxActiveTokens int
+	tokens          spec.TokenCounter
+
 	maxActive   int
+	policy      *policy
+	eviction    *eviction
+	versions    map[string]string       // immutable once the session is created
 	activeOrder []spec.ProviderSkillKey // Active skills are stored as internal keys; order is activation order.
-	activeSet   map[spec.ProviderSkillKey]struct{}
+	// ActiveSet maps active keys to the digest recorded at activation and the pinned flag.
+	activeSet map[spec.ProviderSkillKey]activeEntry
 
 	mu           sync.Mutex
 	stateVersion uint64 // stateVersion increments on every mutation; used for optimistic concurrency.
 	closed       atomic.Bool
 	touch        func()
+	persist      func(State) error
+	notify       func(Event)
 }
 
 func newSession(cfg SessionConfig) *Session {
 	return &Session{
-		id:        cfg.ID,
-		catalog:   cfg.Catalog,
-		providers: cfg.Providers,
-		maxActive: cfg.MaxActivePerSession,
-		activeSet: map[spec.ProviderSkillKey]struct{}{},
-		touch:     cfg.Touch,
+		id:              cfg.ID,
+		createdAt:       cfg.CreatedAt,
+		catalog:         cfg.Catalog,
+		providers:       cfg.Providers,
+		maxActive:       cfg.MaxActivePerSession,
+		maxActiveBytes:  cfg.MaxActiveBytes,
+		maxActiveTokens: cfg.MaxActiveTokens,
+		tokens:          cfg.TokenCounter,
+		policy:          newPolicy(cfg.Policy),
+		eviction:        newEviction(cfg.Eviction),
+		versions:        maps.Clone(cfg.Versions),
+		activeSet:       map[spec.ProviderSkillKey]activeEntry{},
+		touch:           cfg.Touch,
+		persist:         cfg.Persist,
+		notify:          cfg.Notify,
 	}
 }
 
+// ActiveSkill is an active skill key together with the provider digest recorded when it was activated.
+type ActiveSkill = spec.SessionStateSkill
+
+// activeEntry is the per-key state of an active skill.
+type activeEntry struct {
+	// Digest is the provider digest recorded when the skill was activated.
+	digest   string
+	pinned   bool
+	implicit bool
+
+	// LastUsed is tracked in memory on use and persisted with the next activation change.
+	lastUsed time.Time
+
+	// Size caches the measured body size for the budget while the catalog digest is sizeDigest (empty if
+	// not measured). It is not persisted.
+	size       budgetSize
+	sizeDigest string
+}
+
 func (s *Session) ID() string { return s.id }
 
+// CreatedAt returns the session creation time.
+func (s *Session) CreatedAt() time.Time { return s.createdAt }
+
+// MaxActive returns the session's max active skills limit (<= 0 means unlimited).
+func (s *Session) MaxActive() int { return s.maxActive }
+
+// MaxActiveBytes returns the session's active skills body byte budget (<= 0 means unlimited).
+func (s *Session) MaxActiveBytes() int { return s.maxActiveBytes }
+
+// MaxActiveTokens returns the session's active skills body token budget (<= 0 means unlimited).
+func (s *Session) MaxActiveTokens() int { return s.maxActiveTokens }
+
+// Policy returns a copy of the session's skill policy (nil if the session has none).
+func (s *Session) Policy() *spec.SessionSkillPolicy { return s.policy.spec() }
+
+// Eviction returns a copy of the session's eviction policy (nil if the session has none).
+func (s *Session) Eviction() *spec.SkillEvictionPolicy { return s.eviction.spec() }
+
+// Versions returns a copy of the session's version pins (skill name -> version; nil if none).
+func (s *Session) Versions() map[string]string { return maps.Clone(s.versions) }
+
+// Allows reports whether the session's policy permits the skill. Skills not in the catalog are not allowed.
+func (s *Session) Allows(key spec.ProviderSkillKey) bool {
+	if s.policy == nil {
+		return true
+	}
+	idx, ok := s.catalog.GetIndex(key)
+	if !ok {
+		return false
+	}
+	return s.allowsIndex(key, idx)
+}
+
 // ActiveKeys returns the session's active skill keys in activation order.
 //
 // It also prunes keys that no longer exist in the catalog so callers don't need to handle removed-skills drift.
 func (s *Session) ActiveKeys(ctx context.Context) ([]spec.ProviderSkillKey, error) {
+	skills, err := s.ActiveSkills(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]spec.ProviderSkillKey, 0, len(skills))
+	for _, a := range skills {
+		out = append(out, a.Key)
+	}
+	return out, nil
+}
+
+// ActiveSkills is like ActiveKeys but also returns the digest each skill had when it was activated and
+// whether it is pinned.
+func (s *Session) ActiveSkills(ctx context.Context) ([]ActiveSkill, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
@@ -74,18 +193,18 @@ func (s *Session) ActiveKeys(ctx context.Context) ([]spec.ProviderSkillKey, erro
 	}
 
 	s.mu.Lock()
-	order := append([]spec.ProviderSkillKey(nil), s.activeOrder...)
+	order := s.activeSkillsLocked()
 	s.mu.Unlock()
 
-	out := make([]spec.ProviderSkillKey, 0, len(order))
+	out := make([]ActiveSkill, 0, len(order))
 	missing := make([]spec.ProviderSkillKey, 0)
-	for _, k := range order {
+	for _, a := range order {
 		// If removed from catalog, prune from session.
-		if _, ok := s.catalog.HandleForKey(k); !ok {
-			missing = append(missing, k)
+		if _, ok := s.catalog.HandleForKey(a.Key); !ok {
+			missing = append(missing, a.Key)
 			continue
 		}
-		out = append(out, k)
+		out = append(out, a)
 	}
 
 	if len(missing) > 0 {
@@ -94,29 +213,151 @@ func (s *Session) ActiveKeys(ctx context.Context) ([]spec.ProviderSkillKey, erro
 	return out, nil
 }
 
+// PeekActiveSkills is like ActiveSkills but does not mark the session as used (so it does not extend its TTL)
+// and does not prune skills removed from the catalog. It is meant for host introspection.
+func (s *Session) PeekActiveSkills() ([]ActiveSkill, error) {
+	if s.isClosed() {
+		return nil, spec.ErrSessionNotFound
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.activeSkillsLocked(), nil
+}
+
+func (s *Session) activeSkillsLocked() []ActiveSkill {
+	out := make([]ActiveSkill, 0, len(s.activeOrder))
+	for _, k := range s.activeOrder {
+		e := s.activeSet[k]
+		out = append(out, ActiveSkill{
+			Key:      k,
+			Digest:   e.digest,
+			Pinned:   e.pinned,
+			Implicit: e.implicit,
+			LastUsed: e.lastUsed,
+		})
+	}
+	return out
+}
+
+// ActivateKeys activates keys in the session (see spec.LoadMode) and returns the active handles.
+//
+// Pinned keys are never dropped by mode=replace; they stay active and keep counting against maxActive.
+// If the session has a byte/token budget, the bodies of the next active set are measured after EnsureBody
+// and the activation fails with spec.ErrSkillBudgetExceeded if they exceed it.
+//
+// With an eviction policy, other non-pinned skills are unloaded (in eviction order) until the activation
+// fits the limits instead of failing. Skills a remaining skill requires are skipped, and an evicted skill's
+// implicit dependencies are unloaded with it.
+//
+// Skills named in the "requires" frontmatter of the requested skills are activated too, transitively and
+// before their dependents; they are marked implicit unless requested themselves.
+//
+// Activating skills that declare a conflict with each other or with a remaining active skill fails with a
+// *spec.SkillConflictError; mode=replace-conflicting unloads the conflicting (non-pinned) active skills and their
+// implicit dependencies instead, unless a remaining skill requires them.
 func (s *Session) ActivateKeys(
 	ctx context.Context,
 	keys []spec.ProviderSkillKey,
 	mode spec.LoadMode,
 ) ([]spec.SkillHandle, error) {
-	if err := ctx.Err(); err != nil {
+	res, err := s.activate(ctx, keys, mode, nil)
+	if err != nil {
+		return nil, err
+	}
+	return res.Active, nil
+}
+
+// PinKeys activates keys like ActivateKeys and pins them: pinned keys survive skills-load mode=replace and
+// skills-unload (including all=true); only DeactivateKeys (host) removes them. This is a host-only operation.
+func (s *Session) PinKeys(
+	ctx context.Context,
+	keys []spec.ProviderSkillKey,
+	mode spec.LoadMode,
+) ([]spec.SkillHandle, error) {
+	pin := make(map[spec.ProviderSkillKey]struct{}, len(keys))
+	for _, k := range keys {
+		pin[k] = struct{}{}
+	}
+	res, err := s.activate(ctx, keys, mode, pin)
+	if err != nil {
+		return nil, err
+	}
+	return res.Active, nil
+}
+
+// DeactivateKeys removes keys from the session's active skills (all of them if all is true), including
+// pinned ones, and returns the remaining active handles. Keys that are not active are ignored. Keys that a
+// remaining skill requires stay active as unpinned implicit dependencies, and implicitly activated
+// dependencies of the removed skills stay active.
+func (s *Session) DeactivateKeys(
+	ctx context.Context,
+	keys []spec.ProviderSkillKey,
+	all bool,
+) ([]spec.SkillHandle, error) {
+	res, err := s.deactivate(ctx, keys, all, false, false)
+	if err != nil {
 		return nil, err
 	}
+	return res.Active, nil
+}
+
+// activateInitial activates the initial keys of a new session.
+func (s *Session) activateInitial(ctx context.Context, p NewSessionParams) (activationResult, error) {
+	keys := slices.Clone(p.ActiveKeys)
+	pin := make(map[spec.ProviderSkillKey]struct{}, len(p.PinnedKeys))
+	for _, k := range p.PinnedKeys {
+		if !slices.Contains(keys, k) {
+			keys = append(keys, k)
+		}
+		pin[k] = struct{}{}
+	}
+	return s.activate(ctx, keys, spec.LoadModeReplace, pin)
+}
+
+// activationResult is the session's active state after an activation change.
+type activationResult struct {
+	// Active are all active handles in activation order; Pinned is the pinned subset.
+	Active []spec.SkillHandle
+	Pinned []spec.SkillHandle
+
+	// Evicted are the handles unloaded by the eviction policy to make room, in eviction order.
+	Evicted []spec.SkillHandle
+
+	// Dependencies are the handles activated implicitly by this change because a requested skill requires them.
+	Dependencies []spec.SkillHandle
+
+	// Replaced are the conflicting handles unloaded by mode=replace-conflicting, followed by their implicit
+	// dependencies that were unloaded with them.
+	Replaced []spec.SkillHandle
+
+	// Usage is the budget usage of the active set; nil unless the session has a byte/token budget.
+	Usage *spec.ActiveSkillsUsage
+
+	keys []spec.ProviderSkillKey
+}
+
+// activate activates keys; requested keys in pin become pinned (already pinned keys stay pinned).
+func (s *Session) activate(
+	ctx context.Context,
+	keys []spec.ProviderSkillKey,
+	mode spec.LoadMode,
+	pin map[spec.ProviderSkillKey]struct{},
+) (activationResult, error) {
+	if err := ctx.Err(); err != nil {
+		return activationResult{}, err
+	}
 
 	s.touchSession()
 	if s.isClosed() {
-		return nil, spec.ErrSessionNotFound
+		return activationResult{}, spec.ErrSessionNotFound
 	}
 
-	m := mode
-	if strings.TrimSpace(string(m)) == "" {
-		m = spec.LoadModeReplace
-	}
-	if m != spec.LoadModeReplace && m != spec.LoadModeAdd {
-		return nil, fmt.Errorf("%w: mode must be 'replace' or 'add'", spec.ErrInvalidArgument)
+	m, err := normalizeLoadMode(mode)
+	if err != nil {
+		return activationResult{}, err
 	}
 	if len(keys) == 0 {
-		return nil, fmt.Errorf("%w: keys is required", spec.ErrInvalidArgument)
+		return activationResult{}, fmt.Errorf("%w: keys is required", spec.ErrInvalidArgument)
 	}
 
 	// Validate keys exist in catalog (and dedupe).
@@ -131,88 +372,204 @@ func (s *Session) ActivateKeys(
 
 		idx, ok := s.catalog.GetIndex(k)
 		if !ok {
-			return nil, fmt.Errorf("%w: unknown skill key: %+v", spec.ErrSkillNotFound, k)
+			return activationResult{}, fmt.Errorf("%w: unknown skill key: %+v", spec.ErrSkillNotFound, k)
 		}
 		insert, _ := catalog.NormalizeSkillInsert(idx.Insert)
 		if insert != spec.SkillInsertInstructions {
-			return nil, fmt.Errorf(
+			return activationResult{}, fmt.Errorf(
 				"%w: only insert=instructions skills can be activated in a session",
 				spec.ErrInvalidArgument,
 			)
 		}
+		if !s.allowsIndex(k, idx) {
+			return activationResult{}, fmt.Errorf(
+				"%w: skill is not permitted in this session",
+				spec.ErrSkillNotAllowed,
+			)
+		}
 		seen[k] = struct{}{}
 		req = append(req, k)
 	}
+	withDeps, deps, err := s.requirements(req)
+	if err != nil {
+		return activationResult{}, err
+	}
+	keep := maps.Clone(seen)
+	maps.Copy(keep, deps)
+
 	// Progressive disclosure: ensure bodies loadable BEFORE committing state.
 	// Also handle concurrent mutations safely via a small retry loop.
 	for range 5 {
 		s.mu.Lock()
 		if s.isClosed() {
 			s.mu.Unlock()
-			return nil, spec.ErrSessionNotFound
+			return activationResult{}, spec.ErrSessionNotFound
 		}
 
 		snapVer := s.stateVersion
 		currentOrder := append([]spec.ProviderSkillKey(nil), s.activeOrder...)
-		currentSet := make(map[spec.ProviderSkillKey]struct{}, len(s.activeSet))
-		for k := range s.activeSet {
-			currentSet[k] = struct{}{}
-		}
+		currentSet := maps.Clone(s.activeSet)
 		s.mu.Unlock()
 
 		// Compute next state without holding lock.
-		nextSet := map[spec.ProviderSkillKey]struct{}{}
-		nextOrder := make([]spec.ProviderSkillKey, 0, len(currentOrder)+len(req))
+		// Added keys get their digest recorded below; carried-over keys keep the digest from their activation.
+		// Pinned keys are carried over in both modes; re-requesting a pinned key keeps it pinned. With
+		// mode=replace, active dependencies of the requested keys become implicit, and implicit keys are only
+		// kept while a remaining key (e.g. a pinned one) requires them.
+		nextSet := map[spec.ProviderSkillKey]activeEntry{}
+		nextOrder := make([]spec.ProviderSkillKey, 0, len(currentOrder)+len(withDeps))
 
-		switch m {
-		case spec.LoadModeReplace:
-			for _, k := range req {
+		for _, k := range currentOrder {
+			if _, isReq := seen[k]; isReq {
+				continue
+			}
+			e, ok := currentSet[k]
+			_, isDep := deps[k]
+			if !ok || (m == spec.LoadModeReplace && !e.pinned && !e.implicit && !isDep) {
+				continue
+			}
+			if m == spec.LoadModeReplace && !e.pinned {
+				e.implicit = true
+			}
+			nextSet[k] = e
+			nextOrder = append(nextOrder, k)
+		}
+		var now time.Time
+		if s.eviction != nil {
+			now = time.Now()
+		}
+		added := make(map[spec.ProviderSkillKey]struct{}, len(withDeps))
+		var newDeps []spec.ProviderSkillKey
+		for _, k := range withDeps {
+			if _, isReq := seen[k]; isReq {
+				_, pinned := pin[k]
+				cur := currentSet[k]
+				nextSet[k] = activeEntry{
+					pinned:     pinned || cur.pinned,
+					lastUsed:   now,
+					size:       cur.size,
+					sizeDigest: cur.sizeDigest,
+				}
+			} else if _, ok := nextSet[k]; !ok {
+				nextSet[k] = activeEntry{implicit: true, lastUsed: now}
+				newDeps = append(newDeps, k)
+			} else {
+				continue
+			}
+			added[k] = struct{}{}
+			nextOrder = append(nextOrder, k)
+		}
+		if m == spec.LoadModeReplace {
+			nextOrder = s.pruneImplicit(nextOrder, nextSet)
+		}
 
-				nextSet[k] = struct{}{}
-				nextOrder = append(nextOrder, k)
+		// Added skills must not conflict with each other or with the skills that stay active.
+		replaced, err := s.resolveConflicts(m, nextOrder, nextSet, added, keep)
+		if err != nil {
+			return activationResult{}, err
+		}
+		if len(replaced) > 0 {
+			// Implicit dependencies of the replaced skills go with them.
+			prev := slices.DeleteFunc(nextOrder, func(k spec.ProviderSkillKey) bool {
+				return slices.Contains(replaced, k)
+			})
+			nextOrder = s.pruneImplicit(slices.Clone(prev), nextSet)
+			for _, k := range prev {
+				if !slices.Contains(nextOrder, k) {
+					replaced = append(replaced, k)
+				}
 			}
-		case spec.LoadModeAdd:
-			reqSet := map[spec.ProviderSkillKey]struct{}{}
+		}
 
-			for _, k := range req {
-				reqSet[k] = struct{}{}
+		// With an eviction policy, unload other skills until the next state fits the limits. A skill that a
+		// remaining skill requires is not evicted; an evicted skill takes its implicit dependencies with it.
+		candidates := s.eviction.candidates(s.catalog, nextOrder, nextSet, keep)
+		var evicted []spec.ProviderSkillKey
+		evict := func() ([]spec.ProviderSkillKey, bool) {
+			required := s.requiredKeys(nextOrder)
+			i := slices.IndexFunc(candidates, func(k spec.ProviderSkillKey) bool {
+				_, ok := required[k]
+				return !ok
+			})
+			if i < 0 {
+				return nil, false
 			}
-			for _, k := range currentOrder {
-				if _, isReq := reqSet[k]; isReq {
-					continue
+			k := candidates[i]
+			delete(nextSet, k)
+			prev := slices.DeleteFunc(nextOrder, func(v spec.ProviderSkillKey) bool { return v == k })
+			nextOrder = s.pruneImplicit(slices.Clone(prev), nextSet)
+			out := []spec.ProviderSkillKey{k}
+			for _, v := range prev {
+				if !slices.Contains(nextOrder, v) {
+					out = append(out, v)
 				}
-				if _, ok := currentSet[k]; !ok {
-					continue
-				}
-				nextSet[k] = struct{}{}
-				nextOrder = append(nextOrder, k)
 			}
-			for _, k := range req {
-				nextSet[k] = struct{}{}
-				nextOrder = append(nextOrder, k)
+			candidates = slices.DeleteFunc(candidates, func(v spec.ProviderSkillKey) bool {
+				return slices.Contains(out, v)
+			})
+			evicted = append(evicted, out...)
+			return out, true
+		}
+		for s.maxActive > 0 && len(nextOrder) > s.maxActive {
+			if _, ok := evict(); !ok {
+				break
 			}
 		}
 
 		if s.maxActive > 0 && len(nextOrder) > s.maxActive {
-			return nil, fmt.Errorf(
-				"%w: too many active skills (%d > %d)",
+			return activationResult{}, fmt.Errorf(
+				"%w: too many active skills (%d > %d, including pinned skills)",
 				spec.ErrInvalidArgument,
 				len(nextOrder),
 				s.maxActive,
 			)
 		}
 
-		// Ensure bodies are loadable (IO) without lock.
+		// Ensure bodies are loadable (IO) without lock. Skills measured at an earlier activation are skipped.
+		bodies := make(map[spec.ProviderSkillKey]string, len(nextOrder))
 		for _, k := range nextOrder {
-			if _, err := s.catalog.EnsureBody(ctx, k); err != nil {
-				return nil, err
+			if _, ok := s.cachedSize(k, nextSet[k]); ok {
+				continue
+			}
+			body, err := s.catalog.EnsureBody(ctx, k)
+			if err != nil {
+				return activationResult{}, err
+			}
+			bodies[k] = body
+		}
+		var usage *spec.ActiveSkillsUsage
+		var sizes map[spec.ProviderSkillKey]budgetSize
+		if s.hasBudget() {
+			var err error
+			if usage, sizes, err = s.measure(ctx, nextOrder, nextSet, bodies); err != nil {
+				return activationResult{}, err
+			}
+			for s.overBudget(usage) {
+				out, ok := evict()
+				if !ok {
+					break
+				}
+				for _, k := range out {
+					usage.Skills--
+					usage.Bytes -= sizes[k].bytes
+					usage.Tokens -= sizes[k].tokens
+				}
+			}
+			if err := s.budgetError(usage, sizes, req); err != nil {
+				return activationResult{}, err
 			}
 		}
 
 		// Re-check existence just before commit (skills could have been removed concurrently).
 		for _, k := range nextOrder {
-			if _, ok := s.catalog.GetIndex(k); !ok {
-				return nil, spec.ErrSkillNotFound
+			idx, ok := s.catalog.GetIndex(k)
+			if !ok {
+				return activationResult{}, spec.ErrSkillNotFound
+			}
+			if _, isAdded := added[k]; isAdded {
+				e := nextSet[k]
+				e.digest = idx.Digest
+				nextSet[k] = e
 			}
 		}
 
@@ -220,24 +577,507 @@ func (s *Session) ActivateKeys(
 		s.mu.Lock()
 		if s.isClosed() {
 			s.mu.Unlock()
-			return nil, spec.ErrSessionNotFound
+			return activationResult{}, spec.ErrSessionNotFound
 		}
 		if s.stateVersion != snapVer {
 			// Concurrent modification detected; retry with a fresh snapshot.
 			s.mu.Unlock()
 			continue
 		}
-		s.activeSet = nextSet
-
-		s.activeOrder = nextOrder
-		s.stateVersion++
+		if err := s.commitLocked(nextOrder, nextSet); err != nil {
+			s.mu.Unlock()
+			return activationResult{}, err
+		}
 
-		handles, err := s.activeHandlesLocked()
+		res, err := s.activationResultLocked()
+		for _, k := range evicted {
+			if h, ok := s.catalog.HandleForKey(k); ok {
+				res.Evicted = append(res.Evicted, h)
+			}
+		}
+		for _, k := range newDeps {
+			if h, ok := s.catalog.HandleForKey(k); ok {
+				res.Dependencies = append(res.Dependencies, h)
+			}
+		}
+		for _, k := range replaced {
+			if h, ok := s.catalog.HandleForKey(k); ok {
+				res.Replaced = append(res.Replaced, h)
+			}
+		}
 		s.mu.Unlock()
-		return handles, err
+		res.Usage = usage
+		return res, err
 	}
 
-	return nil, fmt.Errorf("%w: concurrent session modification; please retry", spec.ErrInvalidArgument)
+	return activationResult{}, fmt.Errorf(
+		"%w: concurrent session modification; please retry",
+		spec.ErrInvalidArgument,
+	)
+}
+
+// deactivate removes keys (or all keys) from the active set. With keepPinned (skills-unload), pinned keys
+// stay active. Keys that a remaining skill requires stay active as implicit dependencies. With cascade,
+// implicit dependencies no longer required by a remaining skill are removed too.
+func (s *Session) deactivate(
+	ctx context.Context,
+	keys []spec.ProviderSkillKey,
+	all bool,
+	keepPinned bool,
+	cascade bool,
+) (activationResult, error) {
+	if err := ctx.Err(); err != nil {
+		return activationResult{}, err
+	}
+
+	s.touchSession()
+	if s.isClosed() {
+		return activationResult{}, spec.ErrSessionNotFound
+	}
+	if !all && len(keys) == 0 {
+		return activationResult{}, fmt.Errorf("%w: keys is required unless all=true", spec.ErrInvalidArgument)
+	}
+
+	rm := make(map[spec.ProviderSkillKey]struct{}, len(keys))
+	for _, k := range keys {
+		rm[k] = struct{}{}
+	}
+
+	s.mu.Lock()
+	res, err := s.deactivateLocked(rm, all, keepPinned, cascade)
+	set := make(map[spec.ProviderSkillKey]activeEntry, len(res.keys))
+	for _, k := range res.keys {
+		set[k] = s.activeSet[k]
+	}
+	s.mu.Unlock()
+	if err != nil {
+		return activationResult{}, err
+	}
+
+	// Usage is informational here (removing skills never exceeds a budget), so it is best-effort.
+	if s.hasBudget() {
+		res.Usage, _, _ = s.measure(ctx, res.keys, set, nil)
+	}
+	return res, nil
+}
+
+// budgetSize is the measured size of one active skill body.
+type budgetSize struct {
+	bytes  int
+	tokens int
+}
+
+// budgetError returns a spec.ErrSkillBudgetExceeded error naming the requested skills and their sizes if
+// usage exceeds the session budget, and nil otherwise (including nil usage).
+func (s *Session) budgetError(
+	usage *spec.ActiveSkillsUsage,
+	sizes map[spec.ProviderSkillKey]budgetSize,
+	req []spec.ProviderSkillKey,
+) error {
+	if usage == nil {
+		return nil
+	}
+	var unit string
+	var total, limit int
+	switch {
+	case s.maxActiveBytes > 0 && usage.Bytes > s.maxActiveBytes:
+		unit, total, limit = "bytes", usage.Bytes, s.maxActiveBytes
+	case s.maxActiveTokens > 0 && usage.Tokens > s.maxActiveTokens:
+		unit, total, limit = "tokens", usage.Tokens, s.maxActiveTokens
+	default:
+		return nil
+	}
+
+	names := make([]string, 0, len(req))
+	for _, k := range req {
+		h, _ := s.catalog.HandleForKey(k)
+		n := sizes[k].bytes
+		if unit == "tokens" {
+			n = sizes[k].tokens
+		}
+		names = append(names, fmt.Sprintf("%s (%s, %d %s)", h.Name, h.Location, n, unit))
+	}
+	return fmt.Errorf(
+		"%w: active skills would use %d of %d %s; requested skills: %s",
+		spec.ErrSkillBudgetExceeded,
+		total,
+		limit,
+		unit,
+		strings.Join(names, ", "),
+	)
+}
+
+func (s *Session) hasBudget() bool { return s.maxActiveBytes > 0 || s.maxActiveTokens > 0 }
+
+func (s *Session) overBudget(usage *spec.ActiveSkillsUsage) bool {
+	return (s.maxActiveBytes > 0 && usage.Bytes > s.maxActiveBytes) ||
+		(s.maxActiveTokens > 0 && usage.Tokens > s.maxActiveTokens)
+}
+
+// measure computes the budget usage of the given active keys and the size of each. Sizes cached in set are
+// reused; other keys are measured and cached in set. Bodies missing from bodies are loaded via EnsureBody;
+// tokens are only counted if the session has a token budget.
+func (s *Session) measure(
+	ctx context.Context,
+	order []spec.ProviderSkillKey,
+	set map[spec.ProviderSkillKey]activeEntry,
+	bodies map[spec.ProviderSkillKey]string,
+) (*spec.ActiveSkillsUsage, map[spec.ProviderSkillKey]budgetSize, error) {
+	usage := &spec.ActiveSkillsUsage{
+		Skills:    len(order),
+		MaxSkills: max(s.maxActive, 0),
+		MaxBytes:  max(s.maxActiveBytes, 0),
+		MaxTokens: max(s.maxActiveTokens, 0),
+	}
+	sizes := make(map[spec.ProviderSkillKey]budgetSize, len(order))
+	for _, k := range order {
+		e := set[k]
+		if sz, ok := s.cachedSize(k, e); ok {
+			usage.Bytes += sz.bytes
+			usage.Tokens += sz.tokens
+			sizes[k] = sz
+			continue
+		}
+		// The digest is read before the body, so a concurrent refresh at worst invalidates the cache.
+		idx, _ := s.catalog.GetIndex(k)
+		body, ok := bodies[k]
+		if !ok {
+			var err error
+			if body, err = s.catalog.EnsureBody(ctx, k); err != nil {
+				return nil, nil, err
+			}
+		}
+		sz := budgetSize{bytes: len(body)}
+		if s.maxActiveTokens > 0 {
+			if s.tokens == nil {
+				return nil, nil, fmt.Errorf("%w: token budget requires a token counter", spec.ErrInvalidArgument)
+			}
+			n, err := s.tokens.CountTokens(ctx, body)
+			if err != nil {
+				return nil, nil, fmt.Errorf("count tokens: %w", err)
+			}
+			sz.tokens = n
+		}
+		usage.Bytes += sz.bytes
+		usage.Tokens += sz.tokens
+		sizes[k] = sz
+		if idx.Digest != "" {
+			e.size, e.sizeDigest = sz, idx.Digest
+			set[k] = e
+		}
+	}
+	return usage, sizes, nil
+}
+
+// cachedSize returns the size cached in e if it was measured for k's current catalog digest.
+func (s *Session) cachedSize(k spec.ProviderSkillKey, e activeEntry) (budgetSize, bool) {
+	if e.sizeDigest == "" {
+		return budgetSize{}, false
+	}
+	idx, ok := s.catalog.GetIndex(k)
+	if !ok || idx.Digest != e.sizeDigest {
+		return budgetSize{}, false
+	}
+	return e.size, true
+}
+
+// deactivateLocked is the locked part of deactivate. Callers must hold s.mu.
+func (s *Session) deactivateLocked(
+	rm map[spec.ProviderSkillKey]struct{},
+	all bool,
+	keepPinned bool,
+	cascade bool,
+) (activationResult, error) {
+	if s.isClosed() {
+		return activationResult{}, spec.ErrSessionNotFound
+	}
+
+	// Filter order. Removed keys that a remaining key requires stay active as unpinned implicit dependencies,
+	// so active skills keep their dependencies active and the kept keys go with their last dependent.
+	var next []spec.ProviderSkillKey
+	var nextSet map[spec.ProviderSkillKey]activeEntry
+	kept := map[spec.ProviderSkillKey]struct{}{}
+	for {
+		next = make([]spec.ProviderSkillKey, 0, len(s.activeOrder))
+		nextSet = make(map[spec.ProviderSkillKey]activeEntry, len(s.activeSet))
+		for _, k := range s.activeOrder {
+			e, ok := s.activeSet[k]
+			if !ok {
+				continue
+			}
+			if _, ok := kept[k]; ok {
+				e.implicit, e.pinned = true, false
+			} else if _, remove := rm[k]; (remove || all) && !(keepPinned && e.pinned) {
+				continue
+			}
+			next = append(next, k)
+			nextSet[k] = e
+		}
+		n := len(kept)
+		for dep := range s.requiredKeys(next) {
+			if _, ok := nextSet[dep]; !ok {
+				if _, active := s.activeSet[dep]; active {
+					kept[dep] = struct{}{}
+				}
+			}
+		}
+		if len(kept) == n {
+			break
+		}
+	}
+	if cascade {
+		next = s.pruneImplicit(next, nextSet)
+	}
+	if err := s.commitLocked(next, nextSet); err != nil {
+		return activationResult{}, err
+	}
+	return s.activationResultLocked()
+}
+
+// resolveConflicts checks the added keys of the next active state for declared conflicts. With
+// mode=replace-conflicting, conflicting keys that are neither pinned, added, in keep, nor required by a
+// remaining key are removed from set and returned; any other conflict fails with a *spec.SkillConflictError.
+func (s *Session) resolveConflicts(
+	mode spec.LoadMode,
+	order []spec.ProviderSkillKey,
+	set map[spec.ProviderSkillKey]activeEntry,
+	added map[spec.ProviderSkillKey]struct{},
+	keep map[spec.ProviderSkillKey]struct{},
+) ([]spec.ProviderSkillKey, error) {
+	indexes := make(map[spec.ProviderSkillKey]spec.ProviderSkillIndexRecord, len(order))
+	declares := false
+	for _, k := range order {
+		idx, _ := s.catalog.GetIndex(k)
+		indexes[k] = idx
+		declares = declares || len(idx.Conflicts) > 0
+	}
+	if !declares {
+		return nil, nil
+	}
+	conflicts := func(a, b spec.ProviderSkillKey) bool {
+		ia, ib := indexes[a], indexes[b]
+		return slices.Contains(ia.Conflicts, indexName(ib)) || slices.Contains(ib.Conflicts, indexName(ia))
+	}
+
+	var replaced []spec.ProviderSkillKey
+	for _, k := range order {
+		if _, ok := added[k]; !ok {
+			continue
+		}
+		var found []spec.ProviderSkillKey
+		replaceable := mode == spec.LoadModeReplaceConflicting
+		for _, other := range order {
+			if other == k || slices.Contains(replaced, other) || !conflicts(k, other) {
+				continue
+			}
+			found = append(found, other)
+			_, isAdded := added[other]
+			_, isKept := keep[other]
+			if isAdded || isKept || set[other].pinned {
+				replaceable = false
+			}
+		}
+		if len(found) == 0 {
+			continue
+		}
+		if replaceable {
+			// A skill that a surviving skill requires cannot be replaced without breaking that skill.
+			survivors := slices.DeleteFunc(slices.Clone(order), func(v spec.ProviderSkillKey) bool {
+				return slices.Contains(replaced, v) || slices.Contains(found, v)
+			})
+			required := s.requiredKeys(survivors)
+			for _, other := range found {
+				if _, ok := required[other]; ok {
+					replaceable = false
+				}
+			}
+		}
+		if !replaceable {
+			cerr := &spec.SkillConflictError{}
+			cerr.Skill, _ = s.catalog.HandleForKey(k)
+			for _, other := range found {
+				h, _ := s.catalog.HandleForKey(other)
+				cerr.Conflicts = append(cerr.Conflicts, h)
+			}
+			return nil, cerr
+		}
+		for _, other := range found {
+			delete(set, other)
+			replaced = append(replaced, other)
+		}
+	}
+	return replaced, nil
+}
+
+// pruneImplicit removes implicit, non-pinned keys that no remaining key requires (transitively) from order
+// and set, and returns the filtered order.
+func (s *Session) pruneImplicit(
+	order []spec.ProviderSkillKey,
+	set map[spec.ProviderSkillKey]activeEntry,
+) []spec.ProviderSkillKey {
+	for {
+		required := s.requiredKeys(order)
+		n := len(order)
+		order = slices.DeleteFunc(order, func(k spec.ProviderSkillKey) bool {
+			e := set[k]
+			if _, ok := required[k]; ok || !e.implicit || e.pinned {
+				return false
+			}
+			delete(set, k)
+			return true
+		})
+		if len(order) == n {
+			return order
+		}
+	}
+}
+
+// requiredKeys returns the keys that a key of order requires directly. Since active skills have their
+// dependencies active too, this is every active key that a remaining key requires transitively.
+func (s *Session) requiredKeys(order []spec.ProviderSkillKey) map[spec.ProviderSkillKey]struct{} {
+	required := map[spec.ProviderSkillKey]struct{}{}
+	for _, k := range order {
+		idx, _ := s.catalog.GetIndex(k)
+		for _, name := range idx.Requires {
+			if dep, err := s.resolveRequirement(k, name); err == nil {
+				required[dep] = struct{}{}
+			}
+		}
+	}
+	return required
+}
+
+// requirements resolves the "requires" dependencies of req transitively. It returns req and its dependencies
+// with every dependency before its dependents, and the dependencies that are not in req.
+func (s *Session) requirements(
+	req []spec.ProviderSkillKey,
+) ([]spec.ProviderSkillKey, map[spec.ProviderSkillKey]struct{}, error) {
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[spec.ProviderSkillKey]int, len(req))
+	order := make([]spec.ProviderSkillKey, 0, len(req))
+	var stack []spec.ProviderSkillKey
+	name := func(k spec.ProviderSkillKey) string {
+		if h, ok := s.catalog.HandleForKey(k); ok {
+			return h.Name
+		}
+		return k.Name
+	}
+
+	var visit func(k spec.ProviderSkillKey) error
+	visit = func(k spec.ProviderSkillKey) error {
+		switch state[k] {
+		case visited:
+			return nil
+		case visiting:
+			chain := make([]string, 0, len(stack)+1)
+			for _, v := range stack[slices.Index(stack, k):] {
+				chain = append(chain, name(v))
+			}
+			chain = append(chain, name(k))
+			return fmt.Errorf("%w: %s", spec.ErrSkillDependencyCycle, strings.Join(chain, " -> "))
+		}
+		state[k] = visiting
+		stack = append(stack, k)
+
+		idx, _ := s.catalog.GetIndex(k)
+		for _, n := range idx.Requires {
+			dep, err := s.resolveRequirement(k, n)
+			if err != nil {
+				return fmt.Errorf("skill %q requires %q: %w", name(k), n, err)
+			}
+			depIdx, ok := s.catalog.GetIndex(dep)
+			if !ok {
+				return fmt.Errorf("%w: skill %q requires %q", spec.ErrSkillNotFound, name(k), n)
+			}
+			if insert, _ := catalog.NormalizeSkillInsert(depIdx.Insert); insert != spec.SkillInsertInstructions {
+				return fmt.Errorf(
+					"%w: skill %q requires %q, which is not an insert=instructions skill",
+					spec.ErrInvalidArgument,
+					name(k),
+					n,
+				)
+			}
+			if !s.allowsIndex(dep, depIdx) {
+				return fmt.Errorf(
+					"%w: skill %q requires %q, which is not permitted in this session",
+					spec.ErrSkillNotAllowed,
+					name(k),
+					n,
+				)
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		state[k] = visited
+		order = append(order, k)
+		return nil
+	}
+
+	for _, k := range req {
+		if err := visit(k); err != nil {
+			return nil, nil, err
+		}
+	}
+	deps := map[spec.ProviderSkillKey]struct{}{}
+	for _, k := range order {
+		if !slices.Contains(req, k) {
+			deps[k] = struct{}{}
+		}
+	}
+	return order, deps, nil
+}
+
+// resolveHandle resolves an LLM-facing handle to a key, following the session's version selection: a handle
+// of any version of a multi-version skill resolves to the selected version.
+func (s *Session) resolveHandle(h spec.SkillHandle) (spec.ProviderSkillKey, bool) {
+	k, ok := s.catalog.ResolveHandle(h)
+	if !ok {
+		return spec.ProviderSkillKey{}, false
+	}
+	return s.catalog.SelectVersion(k, s.versions), true
+}
+
+// resolveRequirement resolves a "requires" entry of from, following the session's version selection.
+func (s *Session) resolveRequirement(from spec.ProviderSkillKey, name string) (spec.ProviderSkillKey, error) {
+	k, err := s.catalog.ResolveRequirement(from, name)
+	if err != nil {
+		return spec.ProviderSkillKey{}, err
+	}
+	return s.catalog.SelectVersion(k, s.versions), nil
+}
+
+func (s *Session) activationResultLocked() (activationResult, error) {
+	handles, err := s.activeHandlesLocked()
+	if err != nil {
+		return activationResult{}, err
+	}
+	var pinned []spec.SkillHandle
+	for _, k := range s.activeOrder {
+		if !s.activeSet[k].pinned {
+			continue
+		}
+		if h, ok := s.catalog.HandleForKey(k); ok {
+			pinned = append(pinned, h)
+		}
+	}
+	return activationResult{Active: handles, Pinned: pinned, keys: slices.Clone(s.activeOrder)}, nil
+}
+
+func (s *Session) allowsIndex(key spec.ProviderSkillKey, idx spec.ProviderSkillIndexRecord) bool {
+	if s.policy == nil {
+		return true
+	}
+	def, ok := s.catalog.DefForKey(key)
+	if !ok {
+		return false
+	}
+	return s.policy.allows(def, idx.Tags)
 }
 
 func (s *Session) activeHandlesLocked() ([]spec.SkillHandle, error) {
@@ -264,12 +1104,24 @@ func (s *Session) activeHandlesLocked() ([]spec.SkillHandle, error) {
 			return ok
 		})
 		s.stateVersion++
+		s.syncLocked()
 	}
 	return out, nil
 }
 
 func (s *Session) isActiveLocked(k spec.ProviderSkillKey) bool { _, ok := s.activeSet[k]; return ok }
 
+// markUsedLocked records a use of k for eviction (if the session has an eviction policy) and reports
+// whether k is active. Callers must hold s.mu.
+func (s *Session) markUsedLocked(k spec.ProviderSkillKey) bool {
+	e, ok := s.activeSet[k]
+	if ok && s.eviction != nil {
+		e.lastUsed = time.Now()
+		s.activeSet[k] = e
+	}
+	return ok
+}
+
 func (s *Session) touchSession() {
 	if s.touch != nil {
 		s.touch()
@@ -307,23 +1159,179 @@ func (s *Session) pruneKeys(keys []spec.ProviderSkillKey) {
 		return ok
 	})
 	s.stateVersion++
+	s.syncLocked()
 }
 
-func (s *Session) pruneKey(k spec.ProviderSkillKey) {
+// pruneKey removes k from the active set and reports whether it was active.
+func (s *Session) pruneKey(k spec.ProviderSkillKey) bool {
 	if s.closed.Load() {
-		return
+		return false
 	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	if _, ok := s.activeSet[k]; !ok {
-		return
+		return false
 	}
 	delete(s.activeSet, k)
 	s.stateVersion++
 
 	// Remove from order slice.
 	s.activeOrder = slices.DeleteFunc(s.activeOrder, func(v spec.ProviderSkillKey) bool { return v == k })
+	s.syncLocked()
+	return true
+}
+
+// replaceKey swaps oldKey for newKey in place (same activation position).
+func (s *Session) replaceKey(oldKey, newKey spec.ProviderSkillKey) {
+	if s.closed.Load() {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.activeSet[oldKey]; !ok {
+		return
+	}
+	entry := s.activeSet[oldKey]
+	delete(s.activeSet, oldKey)
+	if _, dup := s.activeSet[newKey]; dup {
+		// Already active under the new key; just drop the old one.
+		s.activeOrder = slices.DeleteFunc(s.activeOrder, func(v spec.ProviderSkillKey) bool { return v == oldKey })
+	} else {
+		// The recorded digest is kept: it describes the content at activation time, not the refreshed one.
+		s.activeSet[newKey] = entry
+		for i, k := range s.activeOrder {
+			if k == oldKey {
+				s.activeOrder[i] = newKey
+			}
+		}
+	}
+	s.stateVersion++
+	s.syncLocked()
+}
+
+// commitLocked persists (if the store persists sessions) and installs the next active state, then
+// notifies activation changes. On a persist error nothing is changed. Callers must hold s.mu.
+func (s *Session) commitLocked(order []spec.ProviderSkillKey, set map[spec.ProviderSkillKey]activeEntry) error {
+	if s.persist != nil {
+		if err := s.persist(s.stateFor(order, set, s.stateVersion+1)); err != nil {
+			return err
+		}
+	}
+	prevOrder, prevSet := s.activeOrder, s.activeSet
+	s.activeOrder = order
+	s.activeSet = set
+	s.stateVersion++
+
+	if s.notify == nil {
+		return nil
+	}
+	var added, removed []spec.ProviderSkillKey
+	for _, k := range order {
+		if _, ok := prevSet[k]; !ok {
+			added = append(added, k)
+		}
+	}
+	for _, k := range prevOrder {
+		if _, ok := set[k]; !ok {
+			removed = append(removed, k)
+		}
+	}
+	prev := slices.Clone(prevOrder)
+	next := slices.Clone(order)
+	if len(removed) > 0 {
+		s.notify(Event{
+			Type:      spec.EventTypeSkillsDeactivated,
+			SessionID: s.id,
+			Keys:      removed,
+			Previous:  prev,
+			Active:    next,
+		})
+	}
+	if len(added) > 0 {
+		s.notify(Event{Type: spec.EventTypeSkillsActivated, SessionID: s.id, Keys: added, Previous: prev, Active: next})
+	}
+	return nil
+}
+
+// syncLocked persists the current state best-effort after an internal mutation. Callers must hold s.mu.
+func (s *Session) syncLocked() {
+	if s.persist != nil {
+		_ = s.persist(s.stateFor(s.activeOrder, s.activeSet, s.stateVersion))
+	}
+}
+
+// stateFor builds the persisted form of the given active state. Callers must hold s.mu.
+func (s *Session) stateFor(
+	order []spec.ProviderSkillKey,
+	set map[spec.ProviderSkillKey]activeEntry,
+	version uint64,
+) State {
+	st := State{
+		ID:              spec.SessionID(s.id),
+		CreatedAt:       s.createdAt,
+		MaxActive:       s.maxActive,
+		MaxActiveBytes:  s.maxActiveBytes,
+		MaxActiveTokens: s.maxActiveTokens,
+		Policy:          s.policy.spec(),
+		Eviction:        s.eviction.spec(),
+		Versions:        maps.Clone(s.versions),
+		Version:         version,
+		Active:          make([]ActiveSkill, 0, len(order)),
+	}
+	for _, k := range order {
+		e := set[k]
+		st.Active = append(st.Active, ActiveSkill{
+			Key:      k,
+			Digest:   e.digest,
+			Pinned:   e.pinned,
+			Implicit: e.implicit,
+			LastUsed: e.lastUsed,
+		})
+	}
+	return st
+}
+
+// restore replaces the in-memory active state with a persisted one (e.g. written by another process).
+func (s *Session) restore(st State) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order := make([]spec.ProviderSkillKey, 0, len(st.Active))
+	set := make(map[spec.ProviderSkillKey]activeEntry, len(st.Active))
+	for _, a := range st.Active {
+		if _, dup := set[a.Key]; dup {
+			continue
+		}
+		set[a.Key] = activeEntry{digest: a.Digest, pinned: a.Pinned, implicit: a.Implicit, lastUsed: a.LastUsed}
+		order = append(order, a.Key)
+	}
+	s.activeOrder = order
+	s.activeSet = set
+	s.stateVersion = st.Version
 }
 
 func (s *Session) isClosed() bool { return s.closed.Load() }
+
+// normalizeLoadMode defaults an empty mode to spec.LoadModeReplace and validates it.
+func normalizeLoadMode(mode spec.LoadMode) (spec.LoadMode, error) {
+	switch m := spec.LoadMode(strings.TrimSpace(string(mode))); m {
+	case "":
+		return spec.LoadModeReplace, nil
+	case spec.LoadModeReplace, spec.LoadModeAdd, spec.LoadModeReplaceConflicting:
+		return m, nil
+	}
+	return "", fmt.Errorf(
+		"%w: mode must be 'replace', 'add', or 'replace-conflicting'",
+		spec.ErrInvalidArgument,
+	)
+}
+
+// indexName returns the skill name of a provider index record.
+func indexName(idx spec.ProviderSkillIndexRecord) string {
+	if idx.Name != "" {
+		return idx.Name
+	}
+	return idx.Key.Name
+}
@@ -3,11 +3,13 @@ package session
 import (
 	"context"
 	"errors"
+	"maps"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/flexigpt/agentskills-go/internal/catalog"
 	"github.com/flexigpt/agentskills-go/spec"
 )
 
@@ -149,6 +151,141 @@ func TestSession_ActivateKeys_MaxActiveIsInvalidArgument(t *testing.T) {
 	}
 }
 
+func TestSession_ActivateKeys_BudgetEnforcedAfterEnsureBody(t *testing.T) {
+	cat := newMemCatalog()
+	k1 := spec.ProviderSkillKey{Type: "t", Name: "a", Location: "p1"}
+	k2 := spec.ProviderSkillKey{Type: "t", Name: "b", Location: "p2"}
+	k3 := spec.ProviderSkillKey{Type: "t", Name: "c", Location: "p3"}
+	cat.add(k1, strings.Repeat("a", 6))
+	cat.add(k2, strings.Repeat("b", 5))
+	cat.add(k3, "one two three four")
+
+	words := spec.TokenCounterFunc(func(_ context.Context, text string) (int, error) {
+		return len(strings.Fields(text)), nil
+	})
+
+	t.Run("bytes", func(t *testing.T) {
+		s := newSession(SessionConfig{ID: "id", Catalog: cat, MaxActiveBytes: 10, Touch: func() {}})
+
+		res, err := s.activate(t.Context(), []spec.ProviderSkillKey{k1}, spec.LoadModeReplace, nil)
+		if err != nil {
+			t.Fatalf("activate: %v", err)
+		}
+		if res.Usage == nil || *res.Usage != (spec.ActiveSkillsUsage{Skills: 1, Bytes: 6, MaxBytes: 10}) {
+			t.Fatalf("unexpected usage: %+v", res.Usage)
+		}
+
+		_, err = s.ActivateKeys(t.Context(), []spec.ProviderSkillKey{k2}, spec.LoadModeAdd)
+		if !errors.Is(err, spec.ErrSkillBudgetExceeded) {
+			t.Fatalf("expected ErrSkillBudgetExceeded, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "b (p2, 5 bytes)") || !strings.Contains(err.Error(), "11 of 10 bytes") {
+			t.Fatalf("expected error to name the requested skill and totals, got %v", err)
+		}
+		if got, _ := s.ActiveKeys(t.Context()); len(got) != 1 || got[0] != k1 {
+			t.Fatalf("expected state unchanged after budget error, got %+v", got)
+		}
+
+		// Replacing frees the budget.
+		if _, err := s.ActivateKeys(t.Context(), []spec.ProviderSkillKey{k2}, spec.LoadModeReplace); err != nil {
+			t.Fatalf("replace within budget: %v", err)
+		}
+		res, err = s.deactivate(t.Context(), []spec.ProviderSkillKey{k2}, false, true, false)
+		if err != nil {
+			t.Fatalf("deactivate: %v", err)
+		}
+		if res.Usage == nil || res.Usage.Skills != 0 || res.Usage.Bytes != 0 {
+			t.Fatalf("unexpected usage after deactivate: %+v", res.Usage)
+		}
+	})
+
+	t.Run("tokens", func(t *testing.T) {
+		s := newSession(SessionConfig{
+			ID:              "id",
+			Catalog:         cat,
+			MaxActiveTokens: 4,
+			TokenCounter:    words,
+			Touch:           func() {},
+		})
+		res, err := s.activate(t.Context(), []spec.ProviderSkillKey{k3}, spec.LoadModeReplace, nil)
+		if err != nil {
+			t.Fatalf("activate: %v", err)
+		}
+		if res.Usage == nil || res.Usage.Tokens != 4 || res.Usage.MaxTokens != 4 {
+			t.Fatalf("unexpected usage: %+v", res.Usage)
+		}
+		_, err = s.ActivateKeys(t.Context(), []spec.ProviderSkillKey{k1}, spec.LoadModeAdd)
+		if !errors.Is(err, spec.ErrSkillBudgetExceeded) || !strings.Contains(err.Error(), "a (p1, 1 tokens)") {
+			t.Fatalf("expected token budget error naming a, got %v", err)
+		}
+	})
+
+	t.Run("no budget reports no usage", func(t *testing.T) {
+		s := newSession(SessionConfig{ID: "id", Catalog: cat, Touch: func() {}})
+		res, err := s.activate(t.Context(), []spec.ProviderSkillKey{k1, k2, k3}, spec.LoadModeReplace, nil)
+		if err != nil {
+			t.Fatalf("activate: %v", err)
+		}
+		if res.Usage != nil {
+			t.Fatalf("expected nil usage without budget, got %+v", res.Usage)
+		}
+	})
+}
+
+func TestSession_ActivateKeys_BudgetReusesMeasuredSizes(t *testing.T) {
+	cat := newMemCatalog()
+	k1 := spec.ProviderSkillKey{Type: "t", Name: "a", Location: "p1"}
+	k2 := spec.ProviderSkillKey{Type: "t", Name: "b", Location: "p2"}
+	k3 := spec.ProviderSkillKey{Type: "t", Name: "c", Location: "p3"}
+	for _, k := range []spec.ProviderSkillKey{k1, k2, k3} {
+		cat.add(k, "body of "+k.Name)
+		cat.setDigest(k, "sha256:"+k.Name)
+	}
+
+	var mu sync.Mutex
+	counted := map[string]int{}
+	words := spec.TokenCounterFunc(func(_ context.Context, text string) (int, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		counted[text]++
+		return len(strings.Fields(text)), nil
+	})
+	s := newSession(SessionConfig{
+		ID:              "id",
+		Catalog:         cat,
+		MaxActiveTokens: 100,
+		TokenCounter:    words,
+		Touch:           func() {},
+	})
+
+	for _, k := range []spec.ProviderSkillKey{k1, k2, k3} {
+		if _, err := s.ActivateKeys(t.Context(), []spec.ProviderSkillKey{k}, spec.LoadModeAdd); err != nil {
+			t.Fatalf("ActivateKeys(%s): %v", k.Name, err)
+		}
+	}
+	res, err := s.deactivate(t.Context(), []spec.ProviderSkillKey{k2}, false, false, false)
+	if err != nil {
+		t.Fatalf("deactivate: %v", err)
+	}
+	if res.Usage == nil || res.Usage.Skills != 2 || res.Usage.Tokens != 6 {
+		t.Fatalf("unexpected usage after deactivate: %+v", res.Usage)
+	}
+	want := map[string]int{"body of a": 1, "body of b": 1, "body of c": 1}
+	if !maps.Equal(counted, want) {
+		t.Fatalf("token counts = %v, want each body counted once: %v", counted, want)
+	}
+
+	// A changed digest invalidates the cached size.
+	cat.setDigest(k1, "sha256:a2")
+	if _, err := s.ActivateKeys(t.Context(), []spec.ProviderSkillKey{k2}, spec.LoadModeAdd); err != nil {
+		t.Fatalf("ActivateKeys(b): %v", err)
+	}
+	want = map[string]int{"body of a": 2, "body of b": 2, "body of c": 1}
+	if !maps.Equal(counted, want) {
+		t.Fatalf("token counts = %v, want %v", counted, want)
+	}
+}
+
 func TestSession_ActivateKeys_RetriesOnConcurrentModification(t *testing.T) {
 	cat := newMemCatalog()
 	k1 := spec.ProviderSkillKey{Type: "t", Name: "a", Location: "p1"}
@@ -449,6 +586,27 @@ func (c *toggleCatalog) GetIndex(key spec.ProviderSkillKey) (spec.ProviderSkillI
 	return rec, ok
 }
 
+func (c *toggleCatalog) DefForKey(key spec.ProviderSkillKey) (spec.SkillDef, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.indexes[key]; !ok {
+		return spec.SkillDef{}, false
+	}
+	return spec.SkillDef{Type: key.Type, Name: key.Name, Location: key.Location}, true
+}
+
+func (c *toggleCatalog) ResolveRequirement(spec.ProviderSkillKey, string) (spec.ProviderSkillKey, error) {
+	return spec.ProviderSkillKey{}, spec.ErrSkillNotFound
+}
+
+func (c *toggleCatalog) SelectVersion(key spec.ProviderSkillKey, _ map[string]string) spec.ProviderSkillKey {
+	return key
+}
+
+func (c *toggleCatalog) Search(string, catalog.PromptFilter) []catalog.SearchHit {
+	return nil
+}
+
 func (c *toggleCatalog) put(k spec.ProviderSkillKey, body string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
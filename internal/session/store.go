@@ -12,13 +12,69 @@ import (
 	"github.com/flexigpt/agentskills-go/spec"
 )
 
+// SessionStore owns session lifecycle (creation, lookup, TTL/LRU eviction) for the runtime.
+//
+// Store (in-memory LRU+TTL) is the default implementation; PersistentStore keeps sessions in a
+// spec.SessionStateStore such as FileStateStore.
+type SessionStore interface {
+	NewSession(ctx context.Context, p NewSessionParams) (string, []spec.SkillHandle, error)
+	// Get returns a live session and marks it as used.
+	Get(id string) (*Session, bool)
+	// Peek returns a live session and its info without marking it as used.
+	Peek(id string) (*Session, Info, bool)
+	// Delete closes a session; it reports whether a live session was deleted.
+	Delete(id string) bool
+	// PruneSkill removes the given key from all sessions' active lists and returns the affected session IDs.
+	PruneSkill(key spec.ProviderSkillKey) []string
+	// RekeySkill replaces oldKey with newKey in all sessions' active lists, preserving activation order.
+	RekeySkill(oldKey, newKey spec.ProviderSkillKey)
+	// Touch marks a session as used (keeps it alive for TTL and LRU).
+	Touch(id string)
+	// List returns live sessions, most recently used first.
+	List() []Info
+}
+
+// Event is a store/session notification. It uses internal keys; the runtime converts them to host defs.
+type Event struct {
+	Type      spec.EventType
+	SessionID string
+
+	// Reason is set for session.evicted events.
+	Reason spec.SessionEvictionReason
+
+	// Keys are the activated/deactivated keys; Previous and Active are the active keys before and after.
+	Keys     []spec.ProviderSkillKey
+	Previous []spec.ProviderSkillKey
+	Active   []spec.ProviderSkillKey
+}
+
+// Info describes a live session.
+type Info struct {
+	ID        string
+	CreatedAt time.Time
+	LastUsed  time.Time
+
+	// ExpiresAt is when the session expires unless it is used again (LastUsed + TTL).
+	ExpiresAt time.Time
+}
+
 type StoreConfig struct {
 	TTL                 time.Duration
 	MaxSessions         int
 	MaxActivePerSession int
 
+	// MaxActiveBytesPerSession and MaxActiveTokensPerSession are the default active skills body budgets
+	// (<= 0 means unlimited). A token budget requires TokenCounter.
+	MaxActiveBytesPerSession  int
+	MaxActiveTokensPerSession int
+	TokenCounter              spec.TokenCounter
+
 	Catalog   Catalog
 	Providers ProviderResolver
+
+	// Notify is an optional hook receiving session lifecycle and activation events. It is called
+	// synchronously, possibly while store or session locks are held, so it must not call back into the store.
+	Notify func(Event)
 }
 
 type Store struct {
@@ -39,11 +95,45 @@ type item struct {
 }
 
 type NewSessionParams struct {
+	// Optional session ID (a UUID not in use by a live session). Empty generates a new UUIDv7.
+	ID string
+
 	// If >0 overrides store default for this session.
 	MaxActivePerSession int
 
+	// If >0 override the store default active skills body budgets for this session.
+	MaxActiveBytes  int
+	MaxActiveTokens int
+
 	// Optional initial active skill keys (activated with LoadModeReplace).
 	ActiveKeys []spec.ProviderSkillKey
+
+	// Optional initial pinned keys. Keys also in ActiveKeys keep their position; others are activated after.
+	PinnedKeys []spec.ProviderSkillKey
+
+	// Optional skill policy; initial active keys must satisfy it.
+	Policy *spec.SessionSkillPolicy
+
+	// Optional automatic eviction policy.
+	Eviction *spec.SkillEvictionPolicy
+
+	// Optional version pins (skill name -> version) overriding the catalog-level pins.
+	Versions map[string]string
+}
+
+// sessionBudget returns the active skills body budgets of a new session (params override the defaults).
+func (c StoreConfig) sessionBudget(p NewSessionParams) (maxBytes, maxTokens int, err error) {
+	maxBytes, maxTokens = c.MaxActiveBytesPerSession, c.MaxActiveTokensPerSession
+	if p.MaxActiveBytes > 0 {
+		maxBytes = p.MaxActiveBytes
+	}
+	if p.MaxActiveTokens > 0 {
+		maxTokens = p.MaxActiveTokens
+	}
+	if maxTokens > 0 && c.TokenCounter == nil {
+		return 0, 0, fmt.Errorf("%w: token budget requires a token counter", spec.ErrInvalidArgument)
+	}
+	return maxBytes, maxTokens, nil
 }
 
 func NewStore(cfg StoreConfig) *Store {
@@ -78,41 +168,63 @@ func (st *Store) NewSession(ctx context.Context, p NewSessionParams) (string, []
 	st.evictExpiredLocked(now)
 	st.evictOverLimitLocked()
 
-	u, err := uuid.NewV7()
+	maxBytes, maxTokens, err := st.cfg.sessionBudget(p)
 	if err != nil {
 		st.mu.Unlock()
-		return "", nil, fmt.Errorf("new session id: %w", err)
+		return "", nil, err
+	}
+	id, err := st.newIDLocked(p.ID)
+	if err != nil {
+		st.mu.Unlock()
+		return "", nil, err
 	}
-	id := u.String()
 	maxActive := st.cfg.MaxActivePerSession
 	if p.MaxActivePerSession > 0 {
 		maxActive = p.MaxActivePerSession
 	}
 
+	events := &heldEvents{notify: st.cfg.Notify}
 	s := newSession(SessionConfig{
 		ID:                  id,
+		CreatedAt:           now,
 		Catalog:             st.cfg.Catalog,
 		Providers:           st.cfg.Providers,
 		MaxActivePerSession: maxActive,
-		Touch:               func() { st.touch(id) },
+		MaxActiveBytes:      maxBytes,
+		MaxActiveTokens:     maxTokens,
+		TokenCounter:        st.cfg.TokenCounter,
+		Policy:              p.Policy,
+		Eviction:            p.Eviction,
+		Versions:            p.Versions,
+		Touch:               func() { st.Touch(id) },
+		Notify:              events.add,
 	})
 
 	e := st.lru.PushFront(&item{s: s, lastUsed: now})
 	st.m[id] = e
-
-	st.evictOverLimitLocked()
 	st.mu.Unlock()
 
-	if len(p.ActiveKeys) == 0 {
-		return id, nil, nil
+	var active []spec.SkillHandle
+	if len(p.ActiveKeys) > 0 || len(p.PinnedKeys) > 0 {
+		res, err := s.activateInitial(ctx, p)
+		if err != nil {
+			// The caller never gets the session, so it is dropped without any event.
+			st.mu.Lock()
+			if e := st.m[id]; e != nil {
+				st.deleteElemLocked(e)
+			}
+			st.mu.Unlock()
+			return "", nil, err
+		}
+		active = res.Active
 	}
 
-	handles, err := s.ActivateKeys(ctx, p.ActiveKeys, spec.LoadModeReplace)
-	if err != nil {
-		st.Delete(id)
-		return "", nil, err
-	}
-	return id, handles, nil
+	// Sessions over the limit are evicted once the new session is created, so session.created comes first.
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	events.release(id)
+	st.evictOverLimitLocked()
+	return id, active, nil
 }
 
 func (st *Store) Get(id string) (*Session, bool) {
@@ -139,41 +251,83 @@ func (st *Store) Get(id string) (*Session, bool) {
 	return it.s, true
 }
 
-func (st *Store) Delete(id string) {
+// Peek returns a live session and its info without marking it as used.
+func (st *Store) Peek(id string) (*Session, Info, bool) {
 	st.mu.Lock()
 	defer st.mu.Unlock()
+	st.evictExpiredLocked(time.Now())
 
-	if e := st.m[id]; e != nil {
+	e := st.m[id]
+	if e == nil {
+		return nil, Info{}, false
+	}
+	it, _ := e.Value.(*item)
+	if it == nil || it.s == nil || it.s.closed.Load() {
 		st.deleteElemLocked(e)
+		return nil, Info{}, false
 	}
+	return it.s, st.infoLocked(it), true
 }
 
-// PruneSkill removes the given key from all sessions' active lists.
-func (st *Store) PruneSkill(key spec.ProviderSkillKey) {
-	// Collect sessions under store lock, then prune outside to avoid holding the
-	// global lock while taking per-session locks.
+func (st *Store) Delete(id string) bool {
 	st.mu.Lock()
-	sessions := make([]*Session, 0, st.lru.Len())
-	for e := st.lru.Front(); e != nil; e = e.Next() {
-		it, _ := e.Value.(*item)
-		if it == nil || it.s == nil || it.s.closed.Load() {
+	defer st.mu.Unlock()
+
+	e := st.m[id]
+	if e == nil || !st.deleteElemLocked(e) {
+		return false
+	}
+	st.notify(Event{Type: spec.EventTypeSessionClosed, SessionID: id})
+	return true
+}
+
+// PruneSkill removes the given key from all sessions' active lists and returns the affected session IDs.
+func (st *Store) PruneSkill(key spec.ProviderSkillKey) []string {
+	var ids []string
+	for _, s := range st.liveSessions() {
+		if s == nil || s.closed.Load() {
 			continue
 		}
-		sessions = append(sessions, it.s)
+		if s.pruneKey(key) {
+			ids = append(ids, s.id)
+		}
 	}
-	st.mu.Unlock()
+	return ids
+}
 
-	for _, s := range sessions {
-		if s == nil || s.closed.Load() {
+// RekeySkill replaces oldKey with newKey in all sessions' active lists, preserving activation order.
+// It is used when a catalog refresh re-canonicalizes a skill's internal key.
+func (st *Store) RekeySkill(oldKey, newKey spec.ProviderSkillKey) {
+	if oldKey == newKey {
+		return
+	}
+	for _, s := range st.liveSessions() {
+		s.replaceKey(oldKey, newKey)
+	}
+}
+
+// List returns live sessions, most recently used first.
+func (st *Store) List() []Info {
+	now := time.Now()
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.evictExpiredLocked(now)
+
+	out := make([]Info, 0, st.lru.Len())
+	for e := st.lru.Front(); e != nil; e = e.Next() {
+		it, _ := e.Value.(*item)
+		if it == nil || it.s == nil || it.s.closed.Load() {
 			continue
 		}
-		s.pruneKey(key)
+		out = append(out, st.infoLocked(it))
 	}
+	return out
 }
 
-// touch updates lastUsed and MRU position for an existing session.
+// Touch updates lastUsed and MRU position for an existing session.
 // Safe to call frequently; does not allocate.
-func (st *Store) touch(id string) {
+func (st *Store) Touch(id string) {
 	now := time.Now()
 	st.mu.Lock()
 	defer st.mu.Unlock()
@@ -192,6 +346,28 @@ func (st *Store) touch(id string) {
 	st.lru.MoveToFront(e)
 }
 
+// newIDLocked validates a caller-provided session ID or generates a new one.
+func (st *Store) newIDLocked(requested string) (string, error) {
+	if requested == "" {
+		u, err := uuid.NewV7()
+		if err != nil {
+			return "", fmt.Errorf("new session id: %w", err)
+		}
+		return u.String(), nil
+	}
+	u, err := uuid.Parse(requested)
+	if err != nil || u.String() != requested {
+		return "", fmt.Errorf("%w: invalid session id %q", spec.ErrInvalidArgument, requested)
+	}
+	if e := st.m[requested]; e != nil {
+		if it, _ := e.Value.(*item); it != nil && it.s != nil && !it.s.closed.Load() {
+			return "", fmt.Errorf("%w: session id already in use: %q", spec.ErrInvalidArgument, requested)
+		}
+		st.deleteElemLocked(e)
+	}
+	return requested, nil
+}
+
 func (st *Store) evictOverLimitLocked() {
 	if st.maxSessions <= 0 {
 		return
@@ -201,7 +377,7 @@ func (st *Store) evictOverLimitLocked() {
 		if e == nil {
 			return
 		}
-		st.deleteElemLocked(e)
+		st.evictElemLocked(e, spec.SessionEvictionReasonLRU)
 	}
 }
 
@@ -220,17 +396,89 @@ func (st *Store) evictExpiredLocked(now time.Time) {
 		if now.Sub(it.lastUsed) <= st.ttl {
 			break
 		}
-		st.deleteElemLocked(e)
+		st.evictElemLocked(e, spec.SessionEvictionReasonTTL)
 		e = prev
 	}
 }
 
-func (st *Store) deleteElemLocked(e *list.Element) {
+func (st *Store) infoLocked(it *item) Info {
+	return Info{ID: it.s.id, CreatedAt: it.s.createdAt, LastUsed: it.lastUsed, ExpiresAt: it.lastUsed.Add(st.ttl)}
+}
+
+// liveSessions collects open sessions under the store lock so callers can mutate them
+// without holding the global lock while taking per-session locks.
+func (st *Store) liveSessions() []*Session {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	sessions := make([]*Session, 0, st.lru.Len())
+	for e := st.lru.Front(); e != nil; e = e.Next() {
+		it, _ := e.Value.(*item)
+		if it == nil || it.s == nil || it.s.closed.Load() {
+			continue
+		}
+		sessions = append(sessions, it.s)
+	}
+	return sessions
+}
+
+// deleteElemLocked removes an element and closes its session; it reports whether the session was live.
+func (st *Store) deleteElemLocked(e *list.Element) bool {
+	live := false
 	it, _ := e.Value.(*item)
 	if it != nil && it.s != nil {
 		delete(st.m, it.s.id)
-		it.s.closed.Store(true)
-
+		live = !it.s.closed.Swap(true)
 	}
 	st.lru.Remove(e)
+	return live
+}
+
+func (st *Store) evictElemLocked(e *list.Element, reason spec.SessionEvictionReason) {
+	it, _ := e.Value.(*item)
+	if st.deleteElemLocked(e) {
+		st.notify(Event{Type: spec.EventTypeSessionEvicted, SessionID: it.s.id, Reason: reason})
+	}
+}
+
+func (st *Store) notify(ev Event) {
+	if st.cfg.Notify != nil {
+		st.cfg.Notify(ev)
+	}
+}
+
+// heldEvents is the Notify hook of a session being created. It holds the session's events until release,
+// which notifies session.created first, so subscribers never see events of a session whose creation failed.
+type heldEvents struct {
+	notify func(Event)
+
+	mu       sync.Mutex
+	held     []Event
+	released bool
+}
+
+func (h *heldEvents) add(ev Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.released {
+		h.held = append(h.held, ev)
+		return
+	}
+	if h.notify != nil {
+		h.notify(ev)
+	}
+}
+
+// release notifies session.created for id followed by the held events, and forwards later events directly.
+func (h *heldEvents) release(id string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.released = true
+	if h.notify == nil {
+		return
+	}
+	h.notify(Event{Type: spec.EventTypeSessionCreated, SessionID: id})
+	for _, ev := range h.held {
+		h.notify(ev)
+	}
+	h.held = nil
 }
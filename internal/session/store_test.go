@@ -68,6 +68,38 @@ func TestStore_TTLTouchExtendsLifetime(t *testing.T) {
 	}
 }
 
+func TestStore_PeekDoesNotExtendLifetime(t *testing.T) {
+	t.Parallel()
+
+	ttl := 80 * time.Millisecond
+	st := NewStore(StoreConfig{
+		TTL:                 ttl,
+		MaxSessions:         100,
+		MaxActivePerSession: 8,
+		Catalog:             newMemCatalog(),
+		Providers:           mapResolver{},
+	})
+
+	id, _, err := st.NewSession(t.Context(), NewSessionParams{})
+	if err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	s, info, ok := st.Peek(id)
+	if !ok || s == nil {
+		t.Fatalf("expected session to exist")
+	}
+	if info.ID != id || info.ExpiresAt != info.LastUsed.Add(ttl) || info.CreatedAt.After(info.LastUsed) {
+		t.Fatalf("unexpected info: %+v", info)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if _, _, ok := st.Peek(id); ok {
+		t.Fatalf("expected Peek not to extend the session lifetime")
+	}
+}
+
 func TestStore_MaxSessionsAndLRU(t *testing.T) {
 	t.Parallel()
 
@@ -148,6 +180,48 @@ func TestStore_PruneSkill(t *testing.T) {
 	}
 }
 
+func TestStore_RekeySkill_PreservesActivationOrder(t *testing.T) {
+	t.Parallel()
+
+	cat := newMemCatalog()
+	a := spec.ProviderSkillKey{Type: "t", Name: "a", Location: "p1"}
+	b := spec.ProviderSkillKey{Type: "t", Name: "b", Location: "p2"}
+	a2 := spec.ProviderSkillKey{Type: "t", Name: "a", Location: "p1-canon"}
+	cat.add(a, "ok")
+	cat.add(b, "ok")
+
+	st := NewStore(StoreConfig{
+		TTL:                 10 * time.Second,
+		MaxSessions:         100,
+		MaxActivePerSession: 8,
+		Catalog:             cat,
+		Providers:           mapResolver{"t": &canonProvider{typ: "t"}},
+	})
+
+	id, _, err := st.NewSession(t.Context(), NewSessionParams{ActiveKeys: []spec.ProviderSkillKey{a, b}})
+	if err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+	s, ok := st.Get(id)
+	if !ok {
+		t.Fatalf("Get: missing session")
+	}
+
+	st.RekeySkill(a, a2)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.activeOrder) != 2 || s.activeOrder[0] != a2 || s.activeOrder[1] != b {
+		t.Fatalf("unexpected activeOrder after rekey: %+v", s.activeOrder)
+	}
+	if _, ok := s.activeSet[a]; ok {
+		t.Fatalf("old key still in activeSet")
+	}
+	if _, ok := s.activeSet[a2]; !ok {
+		t.Fatalf("new key missing from activeSet")
+	}
+}
+
 func TestStore_NewSession_InitialActiveKeys_Activated(t *testing.T) {
 	t.Parallel()
 
@@ -326,3 +400,96 @@ func TestStore_DeleteClosesSession(t *testing.T) {
 		t.Fatalf("expected session to be deleted")
 	}
 }
+
+func TestStore_NewSession_ExplicitID(t *testing.T) {
+	t.Parallel()
+
+	st := NewStore(StoreConfig{
+		TTL:                 10 * time.Second,
+		MaxSessions:         100,
+		MaxActivePerSession: 8,
+		Catalog:             newMemCatalog(),
+		Providers:           mapResolver{},
+	})
+
+	const id = "0190f1e2-5b7c-7a3d-9e4f-0123456789ab"
+	got, _, err := st.NewSession(t.Context(), NewSessionParams{ID: id})
+	if err != nil || got != id {
+		t.Fatalf("NewSession(ID) = %q, %v", got, err)
+	}
+	if _, _, err := st.NewSession(t.Context(), NewSessionParams{ID: id}); !errors.Is(err, spec.ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument for live ID, got %v", err)
+	}
+	for _, bad := range []string{"nope", "0190F1E2-5B7C-7A3D-9E4F-0123456789AB"} {
+		_, _, err := st.NewSession(t.Context(), NewSessionParams{ID: bad})
+		if !errors.Is(err, spec.ErrInvalidArgument) {
+			t.Fatalf("expected ErrInvalidArgument for ID %q, got %v", bad, err)
+		}
+	}
+
+	st.Delete(id)
+	if got, _, err := st.NewSession(t.Context(), NewSessionParams{ID: id}); err != nil || got != id {
+		t.Fatalf("expected closed ID to be reusable, got %q, %v", got, err)
+	}
+}
+
+func TestSession_ActiveSkills_RecordsDigestAtActivation(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	cat := newMemCatalog()
+	a := spec.ProviderSkillKey{Type: "t", Name: "a", Location: "p1"}
+	b := spec.ProviderSkillKey{Type: "t", Name: "b", Location: "p2"}
+	a2 := spec.ProviderSkillKey{Type: "t", Name: "a", Location: "p1-canon"}
+	cat.add(a, "ok")
+	cat.add(b, "ok")
+	setDigest := func(k spec.ProviderSkillKey, d string) {
+		cat.mu.Lock()
+		defer cat.mu.Unlock()
+		idx := cat.indexes[k]
+		idx.Digest = d
+		cat.indexes[k] = idx
+	}
+	setDigest(a, "a1")
+	setDigest(b, "b1")
+
+	st := NewStore(StoreConfig{
+		TTL:                 10 * time.Second,
+		MaxSessions:         100,
+		MaxActivePerSession: 8,
+		Catalog:             cat,
+		Providers:           mapResolver{},
+	})
+	id, _, err := st.NewSession(ctx, NewSessionParams{ActiveKeys: []spec.ProviderSkillKey{a}})
+	if err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+	s, _ := st.Get(id)
+
+	setDigest(a, "a2")
+	if _, err := s.ActivateKeys(ctx, []spec.ProviderSkillKey{b}, spec.LoadModeAdd); err != nil {
+		t.Fatalf("ActivateKeys: %v", err)
+	}
+	cat.add(a2, "ok")
+	st.RekeySkill(a, a2)
+
+	got, err := s.ActiveSkills(ctx)
+	if err != nil {
+		t.Fatalf("ActiveSkills: %v", err)
+	}
+	want := []ActiveSkill{{Key: a2, Digest: "a1"}, {Key: b, Digest: "b1"}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+
+	if _, err := s.ActivateKeys(ctx, []spec.ProviderSkillKey{a2}, spec.LoadModeAdd); err != nil {
+		t.Fatalf("ActivateKeys: %v", err)
+	}
+	got, err = s.ActiveSkills(ctx)
+	if err != nil || len(got) != 2 || got[1].Key != a2 || got[1].Digest != "" {
+		t.Fatalf("expected reactivation to record the current digest, got %+v, %v", got, err)
+	}
+	if s.MaxActive() != 8 {
+		t.Fatalf("expected MaxActive 8, got %d", s.MaxActive())
+	}
+}
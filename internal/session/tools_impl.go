@@ -8,6 +8,7 @@ import (
 	"github.com/flexigpt/llmtools-go"
 	llmtoolsgoSpec "github.com/flexigpt/llmtools-go/spec"
 
+	"github.com/flexigpt/agentskills-go/internal/catalog"
 	"github.com/flexigpt/agentskills-go/spec"
 )
 
@@ -29,6 +30,9 @@ func (s *Session) NewRegistry(opts ...llmtools.RegistryOption) (*llmtools.Regist
 	if err := llmtools.RegisterTypedAsTextTool(r, spec.SkillsRunScriptTool(), s.toolRunScript); err != nil {
 		return nil, err
 	}
+	if err := llmtools.RegisterTypedAsTextTool(r, spec.SkillsSearchTool(), s.toolSearch); err != nil {
+		return nil, err
+	}
 
 	return r, nil
 }
@@ -41,12 +45,9 @@ func (s *Session) toolLoad(ctx context.Context, args spec.LoadArgs) (spec.LoadOu
 	if s.isClosed() {
 		return spec.LoadOut{}, spec.ErrSessionNotFound
 	}
-	mode := args.Mode
-	if strings.TrimSpace(string(mode)) == "" {
-		mode = spec.LoadModeReplace
-	}
-	if mode != spec.LoadModeReplace && mode != spec.LoadModeAdd {
-		return spec.LoadOut{}, fmt.Errorf("%w: mode must be 'replace' or 'add'", spec.ErrInvalidArgument)
+	mode, err := normalizeLoadMode(args.Mode)
+	if err != nil {
+		return spec.LoadOut{}, err
 	}
 	if len(args.Skills) == 0 {
 		return spec.LoadOut{}, fmt.Errorf("%w: skills is required", spec.ErrInvalidArgument)
@@ -60,10 +61,13 @@ func (s *Session) toolLoad(ctx context.Context, args spec.LoadArgs) (spec.LoadOu
 		if strings.TrimSpace(h.Name) == "" || strings.TrimSpace(h.Location) == "" {
 			return spec.LoadOut{}, fmt.Errorf("%w: each skill requires name and location", spec.ErrInvalidArgument)
 		}
-		k, ok := s.catalog.ResolveHandle(h)
+		k, ok := s.resolveHandle(h)
 		if !ok {
 			return spec.LoadOut{}, fmt.Errorf("%w: unknown skill handle: %+v", spec.ErrSkillNotFound, h)
 		}
+		if !s.Allows(k) {
+			return spec.LoadOut{}, fmt.Errorf("%w: skill handle: %+v", spec.ErrSkillNotAllowed, h)
+		}
 		if _, ok := seen[k]; ok {
 			continue
 		}
@@ -72,11 +76,18 @@ func (s *Session) toolLoad(ctx context.Context, args spec.LoadArgs) (spec.LoadOu
 		reqKeys = append(reqKeys, k)
 	}
 
-	handles, err := s.ActivateKeys(ctx, reqKeys, mode)
+	res, err := s.activate(ctx, reqKeys, mode, nil)
 	if err != nil {
 		return spec.LoadOut{}, err
 	}
-	return spec.LoadOut{ActiveSkills: handles}, nil
+	return spec.LoadOut{
+		ActiveSkills:     res.Active,
+		PinnedSkills:     res.Pinned,
+		EvictedSkills:    res.Evicted,
+		DependencySkills: res.Dependencies,
+		ReplacedSkills:   res.Replaced,
+		Usage:            res.Usage,
+	}, nil
 }
 
 func (s *Session) toolUnload(ctx context.Context, args spec.UnloadArgs) (spec.UnloadOut, error) {
@@ -92,61 +103,97 @@ func (s *Session) toolUnload(ctx context.Context, args spec.UnloadArgs) (spec.Un
 	}
 
 	if args.All {
-		s.mu.Lock()
-		s.activeSet = map[spec.ProviderSkillKey]struct{}{}
-
-		s.activeOrder = nil
-		s.stateVersion++
-
-		handles, err := s.activeHandlesLocked()
-		s.mu.Unlock()
+		res, err := s.deactivate(ctx, nil, true, true, args.Cascade)
 		if err != nil {
 			return spec.UnloadOut{}, err
 		}
-		return spec.UnloadOut{ActiveSkills: handles}, nil
+		return spec.UnloadOut{ActiveSkills: res.Active, PinnedSkills: res.Pinned, Usage: res.Usage}, nil
 	}
 
 	// Resolve handles to keys.
-	rm := map[spec.ProviderSkillKey]struct{}{}
+	keys := make([]spec.ProviderSkillKey, 0, len(args.Skills))
 
 	for _, h := range args.Skills {
 		if strings.TrimSpace(h.Name) == "" || strings.TrimSpace(h.Location) == "" {
 			return spec.UnloadOut{}, fmt.Errorf("%w: each skill requires name and location", spec.ErrInvalidArgument)
 		}
-		k, ok := s.catalog.ResolveHandle(h)
+		k, ok := s.resolveHandle(h)
 		if !ok {
 			return spec.UnloadOut{}, fmt.Errorf("%w: unknown skill handle: %+v", spec.ErrSkillNotFound, h)
 		}
-		rm[k] = struct{}{}
+		keys = append(keys, k)
 
 	}
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	res, err := s.deactivate(ctx, keys, false, true, args.Cascade)
+	if err != nil {
+		return spec.UnloadOut{}, err
+	}
+	return spec.UnloadOut{ActiveSkills: res.Active, PinnedSkills: res.Pinned, Usage: res.Usage}, nil
+}
+
+// toolSearch ranks the skills the session may load (the ones SkillsPrompt would advertise) by relevance to
+// the query.
+func (s *Session) toolSearch(ctx context.Context, args spec.SearchArgs) (spec.SearchOut, error) {
+	if err := ctx.Err(); err != nil {
+		return spec.SearchOut{}, err
+	}
+	s.touchSession()
+	if s.isClosed() {
+		return spec.SearchOut{}, spec.ErrSessionNotFound
+	}
+	query := strings.TrimSpace(args.Query)
+	if query == "" {
+		return spec.SearchOut{}, fmt.Errorf("%w: query is required", spec.ErrInvalidArgument)
+	}
+	limit := args.Limit
+	if limit == 0 {
+		limit = spec.DefaultSearchLimit
+	}
+	if limit < 0 || limit > spec.MaxSearchLimit {
+		return spec.SearchOut{}, fmt.Errorf(
+			"%w: limit must be between 1 and %d",
+			spec.ErrInvalidArgument,
+			spec.MaxSearchLimit,
+		)
+	}
 
-	for k := range rm {
-		delete(s.activeSet, k)
+	keys, err := s.ActiveKeys(ctx)
+	if err != nil {
+		return spec.SearchOut{}, err
+	}
+	active := make(map[spec.ProviderSkillKey]struct{}, len(keys))
+	for _, k := range keys {
+		active[k] = struct{}{}
 	}
-	// Filter order.
-	next := s.activeOrder[:0]
-	for _, k := range s.activeOrder {
-		if _, remove := rm[k]; remove {
+
+	// Only instruction skills are loadable with skills-load.
+	hits := s.catalog.Search(query, catalog.PromptFilter{Inserts: []spec.SkillInsert{spec.SkillInsertInstructions}})
+	out := spec.SearchOut{Skills: []spec.SkillSearchResult{}}
+	for _, hit := range hits {
+		k := hit.Record.Key
+		if !s.Allows(k) || s.catalog.SelectVersion(k, s.versions) != k {
 			continue
 		}
-		if _, ok := s.activeSet[k]; !ok {
+		h, ok := s.catalog.HandleForKey(k)
+		if !ok {
 			continue
 		}
-		next = append(next, k)
-
-	}
-	s.activeOrder = next
-	s.stateVersion++
-
-	handles, err := s.activeHandlesLocked()
-	if err != nil {
-		return spec.UnloadOut{}, err
+		_, isActive := active[k]
+		out.Skills = append(out.Skills, spec.SkillSearchResult{
+			Name:        h.Name,
+			Location:    h.Location,
+			Description: hit.Record.Description,
+			Tags:        append([]string(nil), hit.Record.Tags...),
+			Version:     hit.Record.Version,
+			Active:      isActive,
+			Score:       hit.Score,
+		})
+		if len(out.Skills) == limit {
+			break
+		}
 	}
-	return spec.UnloadOut{ActiveSkills: handles}, nil
+	return out, nil
 }
 
 func (s *Session) toolRead(
@@ -168,13 +215,13 @@ func (s *Session) toolRead(
 	}
 
 	h := spec.SkillHandle{Name: args.SkillName, Location: args.SkillLocation}
-	k, ok := s.catalog.ResolveHandle(h)
+	k, ok := s.resolveHandle(h)
 	if !ok {
 		return nil, fmt.Errorf("%w: unknown skill handle: %+v", spec.ErrSkillNotFound, h)
 	}
 
 	s.mu.Lock()
-	active := s.isActiveLocked(k)
+	active := s.markUsedLocked(k)
 	s.mu.Unlock()
 	if !active {
 		return nil, spec.ErrSkillNotActive
@@ -209,13 +256,13 @@ func (s *Session) toolRunScript(ctx context.Context, args spec.RunScriptArgs) (s
 	}
 
 	h := spec.SkillHandle{Name: args.SkillName, Location: args.SkillLocation}
-	k, ok := s.catalog.ResolveHandle(h)
+	k, ok := s.resolveHandle(h)
 	if !ok {
 		return spec.RunScriptOut{}, fmt.Errorf("%w: unknown skill handle: %+v", spec.ErrSkillNotFound, h)
 	}
 
 	s.mu.Lock()
-	active := s.isActiveLocked(k)
+	active := s.markUsedLocked(k)
 
 	s.mu.Unlock()
 	if !active {
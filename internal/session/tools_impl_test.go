@@ -601,3 +601,68 @@ func TestTools_toolRunScript_Validations_ArgsEnvWorkDirPassed(t *testing.T) {
 		t.Fatalf("unexpected lastRunWD: %q", p.lastRunWD)
 	}
 }
+
+func TestTools_toolSearch_FiltersAndLimits(t *testing.T) {
+	t.Parallel()
+
+	cat := newMemCatalog()
+	keys := map[string]spec.ProviderSkillKey{}
+	for _, name := range []string{"pdf-merge", "pdf-split", "pdf-sign", "csv-tools"} {
+		k := spec.ProviderSkillKey{Type: "t", Name: name, Location: "/" + name}
+		keys[name] = k
+		cat.add(k, "body")
+	}
+	s := newSession(SessionConfig{
+		ID:        "id",
+		Catalog:   cat,
+		Providers: mapResolver{"t": &recordingProvider{typ: "t"}},
+		Policy: &spec.SessionSkillPolicy{
+			DenySkills: []spec.SkillDef{spec.SkillDef(keys["pdf-sign"])},
+		},
+		Touch: func() {},
+	})
+	_, err := s.ActivateKeys(t.Context(), []spec.ProviderSkillKey{keys["pdf-split"]}, spec.LoadModeReplace)
+	if err != nil {
+		t.Fatalf("ActivateKeys: %v", err)
+	}
+
+	for _, args := range []spec.SearchArgs{
+		{Query: "  "},
+		{Query: "pdf", Limit: -1},
+		{Query: "pdf", Limit: spec.MaxSearchLimit + 1},
+	} {
+		if _, err := s.toolSearch(t.Context(), args); !errors.Is(err, spec.ErrInvalidArgument) {
+			t.Fatalf("toolSearch(%+v): err = %v, want ErrInvalidArgument", args, err)
+		}
+	}
+
+	out, err := s.toolSearch(t.Context(), spec.SearchArgs{Query: "pdf"})
+	if err != nil {
+		t.Fatalf("toolSearch: %v", err)
+	}
+	// Denied skills are not found; active ones are flagged.
+	want := []spec.SkillSearchResult{
+		{Name: "pdf-merge", Location: "/pdf-merge", Description: "d-pdf-merge", Score: 1},
+		{Name: "pdf-split", Location: "/pdf-split", Description: "d-pdf-split", Active: true, Score: 1},
+	}
+	if len(out.Skills) != len(want) {
+		t.Fatalf("skills = %+v, want %+v", out.Skills, want)
+	}
+	for i := range want {
+		got := out.Skills[i]
+		if got.Name != want[i].Name || got.Location != want[i].Location || got.Description != want[i].Description ||
+			got.Active != want[i].Active || got.Score != want[i].Score {
+			t.Fatalf("skills[%d] = %+v, want %+v", i, got, want[i])
+		}
+	}
+
+	out, err = s.toolSearch(t.Context(), spec.SearchArgs{Query: "pdf", Limit: 1})
+	if err != nil || len(out.Skills) != 1 || out.Skills[0].Name != "pdf-merge" {
+		t.Fatalf("toolSearch limit=1 = %+v, %v", out, err)
+	}
+
+	out, err = s.toolSearch(t.Context(), spec.SearchArgs{Query: "nothing-matches"})
+	if err != nil || out.Skills == nil || len(out.Skills) != 0 {
+		t.Fatalf("toolSearch no match = %#v, %v; want empty non-nil", out, err)
+	}
+}
@@ -0,0 +1,373 @@
+package memskillprovider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/flexigpt/agentskills-go"
+	llmtoolsgoSpec "github.com/flexigpt/llmtools-go/spec"
+
+	"github.com/flexigpt/agentskills-go/internal/providerutil"
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+const Type = "mem"
+
+// RefreshTarget is the subset of *agentskills.Runtime notified when a stored document changes.
+type RefreshTarget interface {
+	ListSkills(ctx context.Context, filter *agentskills.SkillListFilter) ([]spec.SkillRecord, error)
+	RefreshSkill(ctx context.Context, def spec.SkillDef) (spec.SkillRefreshResult, error)
+}
+
+type storedSkill struct {
+	document  spec.SkillDocument
+	warnings  []string
+	resources map[string][]byte
+	info      spec.SkillResourceInfo
+	digest    string
+}
+
+// Provider serves skills from spec.SkillDocument values held in memory, e.g. skills materialized
+// from a database or an API.
+//
+// A skill location is an opaque host-chosen key passed to Put. Unlike the fs provider, the location
+// does not need to match the skill name. Scripts are never executed.
+type Provider struct {
+	typ string
+
+	mu     sync.RWMutex
+	skills map[string]*storedSkill
+	target RefreshTarget
+}
+
+type Option func(*Provider) error
+
+// WithType overrides the provider type key (default "mem").
+func WithType(t string) Option {
+	return func(p *Provider) error {
+		if strings.TrimSpace(t) == "" || strings.TrimSpace(t) != t {
+			return fmt.Errorf("%w: invalid provider type %q", spec.ErrInvalidArgument, t)
+		}
+		p.typ = t
+		return nil
+	}
+}
+
+// WithRefreshTarget sets the runtime notified on Put/Delete. See Attach.
+func WithRefreshTarget(target RefreshTarget) Option {
+	return func(p *Provider) error {
+		p.target = target
+		return nil
+	}
+}
+
+func New(opts ...Option) (*Provider, error) {
+	p := &Provider{typ: Type, skills: map[string]*storedSkill{}}
+	for _, o := range opts {
+		if o == nil {
+			continue
+		}
+		if err := o(p); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// Attach sets the runtime notified when a stored document changes (nil detaches).
+//
+// The provider must be registered before the runtime exists, so attaching is usually a separate step:
+//
+//	p, _ := memskillprovider.New()
+//	rt, _ := agentskills.New(agentskills.WithProvider(p))
+//	p.Attach(rt)
+func (p *Provider) Attach(target RefreshTarget) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.target = target
+}
+
+// Put stores (or replaces) the skill at location.
+//
+// The document is validated and normalized through MarshalSkillDocument/ParseSkillDocument, so the
+// indexed record matches what a SKILL.md file with the same content would produce. Resources are
+// keyed by slash-separated relative locations and copied. The digest covers the marshaled document
+// and all resource contents.
+//
+// If a refresh target is attached and the digest changed, every registered skill of this provider
+// at location is refreshed with ctx. Refresh errors are returned after the document has been stored.
+func (p *Provider) Put(
+	ctx context.Context,
+	location string,
+	document spec.SkillDocument,
+	resources map[string][]byte,
+) error {
+	if ctx == nil {
+		return fmt.Errorf("%w: nil context", spec.ErrInvalidArgument)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := validateLocation(location); err != nil {
+		return err
+	}
+	raw, err := agentskills.MarshalSkillDocument(document)
+	if err != nil {
+		return err
+	}
+	parsed, warnings, err := agentskills.ParseSkillDocument(raw, spec.ParseSkillDocumentOptions{})
+	if err != nil {
+		return fmt.Errorf("%w: %w", spec.ErrInvalidArgument, err)
+	}
+
+	parts := digestParts(nil, raw)
+	stored := make(map[string][]byte, len(resources))
+	for _, name := range slices.Sorted(maps.Keys(resources)) {
+		rel, err := providerutil.CleanLocation(name)
+		if err != nil {
+			return err
+		}
+		if rel == "." || rel == agentskills.SkillDocumentFileName || rel != name {
+			return fmt.Errorf("%w: invalid resource location %q", spec.ErrInvalidArgument, name)
+		}
+		data := resources[name]
+		if len(data) > providerutil.MaxResourceBytes {
+			return fmt.Errorf(
+				"%w: resource %q too large (max %d bytes)",
+				spec.ErrInvalidArgument,
+				name,
+				providerutil.MaxResourceBytes,
+			)
+		}
+		stored[rel] = slices.Clone(data)
+		parts = digestParts(parts, []byte(rel), data)
+	}
+	digest, err := providerutil.DigestReader(io.MultiReader(parts...))
+	if err != nil {
+		return err
+	}
+
+	s := &storedSkill{
+		document:  parsed,
+		warnings:  warnings,
+		resources: stored,
+		info:      providerutil.ResourceInfo(slices.Collect(maps.Keys(stored))),
+		digest:    digest,
+	}
+
+	p.mu.Lock()
+	prev, existed := p.skills[location]
+	p.skills[location] = s
+	target := p.target
+	p.mu.Unlock()
+
+	if existed && prev.digest == s.digest {
+		return nil
+	}
+	return p.notify(ctx, target, location)
+}
+
+// Delete removes the skill at location. It reports whether a skill was stored there.
+//
+// If a refresh target is attached, registered skills at location are refreshed with ctx, which
+// removes them from the catalog and prunes them from sessions.
+func (p *Provider) Delete(ctx context.Context, location string) (bool, error) {
+	if ctx == nil {
+		return false, fmt.Errorf("%w: nil context", spec.ErrInvalidArgument)
+	}
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	p.mu.Lock()
+	_, ok := p.skills[location]
+	delete(p.skills, location)
+	target := p.target
+	p.mu.Unlock()
+
+	if !ok {
+		return false, nil
+	}
+	return true, p.notify(ctx, target, location)
+}
+
+// Locations returns the stored locations in sorted order.
+func (p *Provider) Locations() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return slices.Sorted(maps.Keys(p.skills))
+}
+
+func (p *Provider) Type() string { return p.typ }
+
+func (p *Provider) Index(ctx context.Context, def spec.SkillDef) (spec.ProviderSkillIndexRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return spec.ProviderSkillIndexRecord{}, err
+	}
+	if def.Type != p.typ {
+		return spec.ProviderSkillIndexRecord{}, fmt.Errorf(
+			"%w: wrong provider type: %q",
+			spec.ErrInvalidArgument,
+			def.Type,
+		)
+	}
+	if strings.TrimSpace(def.Name) == "" {
+		return spec.ProviderSkillIndexRecord{}, fmt.Errorf("%w: def.name is required", spec.ErrInvalidArgument)
+	}
+	s, err := p.lookup(def.Location)
+	if err != nil {
+		return spec.ProviderSkillIndexRecord{}, err
+	}
+	document := s.document
+	if document.Name != def.Name {
+		return spec.ProviderSkillIndexRecord{}, fmt.Errorf(
+			"%w: key.name=%q does not match document name=%q",
+			spec.ErrInvalidArgument,
+			def.Name,
+			document.Name,
+		)
+	}
+
+	return spec.ProviderSkillIndexRecord{
+		Key:            spec.ProviderSkillKey{Type: def.Type, Name: def.Name, Location: def.Location},
+		Name:           document.Name,
+		Description:    document.Description,
+		DisplayName:    document.DisplayName,
+		Insert:         document.Insert,
+		Version:        document.Version,
+		Arguments:      slices.Clone(document.Arguments),
+		Tags:           slices.Clone(document.Tags),
+		Requires:       slices.Clone(document.Requires),
+		Conflicts:      slices.Clone(document.Conflicts),
+		Resources:      s.info,
+		RawFrontmatter: maps.Clone(document.RawFrontmatter),
+		Warnings:       slices.Clone(s.warnings),
+		Digest:         s.digest,
+	}, nil
+}
+
+func (p *Provider) LoadBody(ctx context.Context, key spec.ProviderSkillKey) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if key.Type != p.typ {
+		return "", fmt.Errorf("%w: wrong provider type: %q", spec.ErrInvalidArgument, key.Type)
+	}
+	s, err := p.lookup(key.Location)
+	if err != nil {
+		return "", err
+	}
+	return s.document.MarkdownBody, nil
+}
+
+func (p *Provider) ReadResource(
+	ctx context.Context,
+	key spec.ProviderSkillKey,
+	resourceLocation string,
+	encoding spec.ReadResourceEncoding,
+) ([]llmtoolsgoSpec.ToolOutputUnion, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if key.Type != p.typ {
+		return nil, fmt.Errorf("%w: wrong provider type: %q", spec.ErrInvalidArgument, key.Type)
+	}
+	enc, err := providerutil.NormalizeEncoding(encoding)
+	if err != nil {
+		return nil, err
+	}
+	s, err := p.lookup(key.Location)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := providerutil.CleanLocation(resourceLocation)
+	if err != nil {
+		return nil, err
+	}
+	data, ok := s.resources[rel]
+	if !ok {
+		return nil, fmt.Errorf("%w: resource not found: %q", spec.ErrInvalidArgument, resourceLocation)
+	}
+	return providerutil.ResourceOutput(rel, data, enc)
+}
+
+func (p *Provider) RunScript(
+	ctx context.Context,
+	key spec.ProviderSkillKey,
+	scriptLocation string,
+	args []string,
+	env map[string]string,
+	workdir string,
+) (spec.RunScriptOut, error) {
+	if err := ctx.Err(); err != nil {
+		return spec.RunScriptOut{}, err
+	}
+	if key.Type != p.typ {
+		return spec.RunScriptOut{}, fmt.Errorf("%w: wrong provider type: %q", spec.ErrInvalidArgument, key.Type)
+	}
+	return spec.RunScriptOut{}, spec.ErrRunScriptUnsupported
+}
+
+// lookup returns the stored skill. Stored values are immutable once published, so no copy is needed.
+func (p *Provider) lookup(location string) (*storedSkill, error) {
+	if err := validateLocation(location); err != nil {
+		return nil, err
+	}
+	p.mu.RLock()
+	s, ok := p.skills[location]
+	p.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: no document stored at %q", spec.ErrSkillNotFound, location)
+	}
+	return s, nil
+}
+
+// notify refreshes every registered skill of this provider stored at location.
+func (p *Provider) notify(ctx context.Context, target RefreshTarget, location string) error {
+	if target == nil {
+		return nil
+	}
+	records, err := target.ListSkills(ctx, &agentskills.SkillListFilter{
+		Types:          []string{p.typ},
+		LocationPrefix: location,
+	})
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, rec := range records {
+		if rec.Def.Location != location {
+			continue
+		}
+		if _, err := target.RefreshSkill(ctx, rec.Def); err != nil && !errors.Is(err, spec.ErrSkillNotFound) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func validateLocation(location string) error {
+	if strings.TrimSpace(location) == "" || strings.TrimSpace(location) != location {
+		return fmt.Errorf("%w: invalid location %q", spec.ErrInvalidArgument, location)
+	}
+	if strings.ContainsRune(location, '\x00') {
+		return fmt.Errorf("%w: location contains NUL byte", spec.ErrInvalidArgument)
+	}
+	return nil
+}
+
+// digestParts appends length-prefixed readers over data to parts, so digests of different splits never
+// collide. The data is not copied.
+func digestParts(parts []io.Reader, data ...[]byte) []io.Reader {
+	for _, d := range data {
+		parts = append(parts, strings.NewReader(strconv.Itoa(len(d))+":"), bytes.NewReader(d))
+	}
+	return parts
+}
@@ -0,0 +1,211 @@
+package memskillprovider
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/flexigpt/agentskills-go"
+	llmtoolsgoSpec "github.com/flexigpt/llmtools-go/spec"
+
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+const (
+	helloSkillName = "hello-skill"
+	helloLocation  = "db/skills/42"
+)
+
+func helloDocument(body string) spec.SkillDocument {
+	return spec.SkillDocument{
+		Name:         helloSkillName,
+		Description:  "stored hello",
+		Tags:         []string{"greeting"},
+		MarkdownBody: body,
+	}
+}
+
+func mustNew(t *testing.T, opts ...Option) *Provider {
+	t.Helper()
+	p, err := New(opts...)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return p
+}
+
+func TestProvider_PutIndexLoadBodyAndReadResource(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	p := mustNew(t)
+	if err := p.Put(ctx, helloLocation, helloDocument("Say hello."), map[string][]byte{
+		"references/guide.md": []byte("guide"),
+		"assets/data.bin":     {0, 1, 2},
+	}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if got := p.Locations(); len(got) != 1 || got[0] != helloLocation {
+		t.Fatalf("unexpected locations: %v", got)
+	}
+
+	rec, err := p.Index(ctx, spec.SkillDef{Type: Type, Name: helloSkillName, Location: helloLocation})
+	if err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+	if rec.Description != "stored hello" || rec.Insert != spec.SkillInsertInstructions ||
+		len(rec.Tags) != 1 || !strings.HasPrefix(rec.Digest, "sha256:") {
+		t.Fatalf("unexpected record: %+v", rec)
+	}
+	if rec.Resources.TotalCount != 2 || rec.Resources.Locations[0] != "assets/data.bin" {
+		t.Fatalf("unexpected resources: %+v", rec.Resources)
+	}
+
+	body, err := p.LoadBody(ctx, rec.Key)
+	if err != nil || strings.TrimSpace(body) != "Say hello." {
+		t.Fatalf("LoadBody = %q, %v", body, err)
+	}
+
+	out, err := p.ReadResource(ctx, rec.Key, "references/guide.md", "")
+	if err != nil || len(out) != 1 || out[0].TextItem == nil || out[0].TextItem.Text != "guide" {
+		t.Fatalf("ReadResource text = %+v, %v", out, err)
+	}
+	out, err = p.ReadResource(ctx, rec.Key, "assets/data.bin", spec.ReadResourceEncodingBinary)
+	if err != nil || len(out) != 1 || out[0].Kind != llmtoolsgoSpec.ToolOutputKindFile {
+		t.Fatalf("ReadResource binary = %+v, %v", out, err)
+	}
+	for _, loc := range []string{"missing.md", "../x", "/abs"} {
+		if _, err := p.ReadResource(ctx, rec.Key, loc, ""); err == nil {
+			t.Fatalf("expected error for resource %q", loc)
+		}
+	}
+	if _, err := p.RunScript(ctx, rec.Key, "x.sh", nil, nil, ""); !errors.Is(err, spec.ErrRunScriptUnsupported) {
+		t.Fatalf("expected ErrRunScriptUnsupported, got %v", err)
+	}
+}
+
+func TestProvider_PutValidationAndDigest(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	p := mustNew(t)
+	bad := []struct {
+		name      string
+		location  string
+		document  spec.SkillDocument
+		resources map[string][]byte
+	}{
+		{"blank location", " ", helloDocument("x"), nil},
+		{"padded location", " a", helloDocument("x"), nil},
+		{"invalid document", "a", spec.SkillDocument{Name: "Bad Name", Description: "x"}, nil},
+		{"resource traversal", "a", helloDocument("x"), map[string][]byte{"../x": nil}},
+		{"resource unclean", "a", helloDocument("x"), map[string][]byte{"a//b": nil}},
+		{"resource skill file", "a", helloDocument("x"), map[string][]byte{"SKILL.md": nil}},
+	}
+	for _, tt := range bad {
+		if err := p.Put(ctx, tt.location, tt.document, tt.resources); !errors.Is(err, spec.ErrInvalidArgument) {
+			t.Fatalf("%s: expected invalid argument, got %v", tt.name, err)
+		}
+	}
+
+	def := spec.SkillDef{Type: Type, Name: helloSkillName, Location: helloLocation}
+	digestOf := func(resources map[string][]byte) string {
+		t.Helper()
+		if err := p.Put(ctx, helloLocation, helloDocument("Body"), resources); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+		rec, err := p.Index(ctx, def)
+		if err != nil {
+			t.Fatalf("Index: %v", err)
+		}
+		return rec.Digest
+	}
+	d1 := digestOf(map[string][]byte{"a.txt": []byte("1")})
+	if d2 := digestOf(map[string][]byte{"a.txt": []byte("1")}); d1 != d2 {
+		t.Fatalf("expected stable digest, got %q vs %q", d1, d2)
+	}
+	if d3 := digestOf(map[string][]byte{"a.txt": []byte("2")}); d1 == d3 {
+		t.Fatalf("expected resource change to alter digest")
+	}
+
+	if _, err := p.Index(ctx, spec.SkillDef{Type: Type, Name: "other", Location: helloLocation}); err == nil {
+		t.Fatalf("expected name mismatch error")
+	}
+	if _, err := p.Index(ctx, spec.SkillDef{Type: Type, Name: helloSkillName, Location: "nope"}); !errors.Is(
+		err,
+		spec.ErrSkillNotFound,
+	) {
+		t.Fatalf("expected ErrSkillNotFound for unknown location, got %v", err)
+	}
+	if _, err := p.Index(ctx, spec.SkillDef{Type: "fs", Name: helloSkillName, Location: helloLocation}); !errors.Is(
+		err,
+		spec.ErrInvalidArgument,
+	) {
+		t.Fatalf("expected invalid argument for wrong type, got %v", err)
+	}
+}
+
+func TestProvider_PutAndDeleteNotifyRuntime(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	p := mustNew(t)
+	if err := p.Put(ctx, helloLocation, helloDocument("Body v1"), nil); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	rt, err := agentskills.New(agentskills.WithProvider(p))
+	if err != nil {
+		t.Fatalf("agentskills.New: %v", err)
+	}
+	p.Attach(rt)
+
+	def := spec.SkillDef{Type: Type, Name: helloSkillName, Location: helloLocation}
+	if _, err := rt.AddSkill(ctx, def); err != nil {
+		t.Fatalf("AddSkill: %v", err)
+	}
+	sid, _, err := rt.NewSession(ctx, agentskills.WithSessionActiveSkills([]spec.SkillDef{def}))
+	if err != nil {
+		t.Fatalf("NewSession: %v", err)
+	}
+	activePrompt := func() string {
+		t.Helper()
+		prompt, err := rt.SkillsPrompt(ctx, &agentskills.SkillFilter{SessionID: sid, Activity: spec.SkillActivityActive})
+		if err != nil {
+			t.Fatalf("SkillsPrompt: %v", err)
+		}
+		return prompt
+	}
+	if !strings.Contains(activePrompt(), "Body v1") {
+		t.Fatalf("expected v1 body in prompt")
+	}
+
+	if err := p.Put(ctx, helloLocation, helloDocument("Body v2"), nil); err != nil {
+		t.Fatalf("Put v2: %v", err)
+	}
+	if prompt := activePrompt(); !strings.Contains(prompt, "Body v2") {
+		t.Fatalf("expected refreshed body in prompt, got:\n%s", prompt)
+	}
+
+	ok, err := p.Delete(ctx, helloLocation)
+	if err != nil || !ok {
+		t.Fatalf("Delete = %v, %v", ok, err)
+	}
+	recs, err := rt.ListSkills(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListSkills: %v", err)
+	}
+	if len(recs) != 0 {
+		t.Fatalf("expected deleted skill to leave the catalog, got %+v", recs)
+	}
+	if ok, err := p.Delete(ctx, helloLocation); ok || err != nil {
+		t.Fatalf("second Delete = %v, %v", ok, err)
+	}
+
+	// Refreshes run with the caller's context.
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := p.Put(canceled, helloLocation, helloDocument("Body v3"), nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Put with canceled context: expected context.Canceled, got %v", err)
+	}
+}
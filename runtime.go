@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"maps"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -13,6 +14,7 @@ import (
 	"github.com/flexigpt/llmtools-go"
 
 	"github.com/flexigpt/agentskills-go/internal/catalog"
+	"github.com/flexigpt/agentskills-go/internal/semver"
 	"github.com/flexigpt/agentskills-go/internal/session"
 	"github.com/flexigpt/agentskills-go/spec"
 )
@@ -24,7 +26,8 @@ type Runtime struct {
 	providers map[string]spec.SkillProvider
 
 	catalog  *catalog.Catalog
-	sessions *session.Store
+	sessions session.SessionStore
+	events   *eventBus
 }
 
 type runtimeOptions struct {
@@ -36,6 +39,16 @@ type runtimeOptions struct {
 	maxActivePerSession int
 	sessionTTL          time.Duration
 	maxSessions         int
+	sessionStoreDir     string
+	sessionStore        spec.SessionStateStore
+
+	maxActiveBytesPerSession  int
+	maxActiveTokensPerSession int
+	tokenCounter              spec.TokenCounter
+
+	skillVersions map[string]string
+
+	embedder spec.Embedder
 }
 
 type Option func(*runtimeOptions) error
@@ -74,6 +87,36 @@ func WithMaxActivePerSession(n int) Option {
 	}
 }
 
+// WithMaxActiveBytesPerSession sets the default budget for the combined SKILL.md body size, in bytes, of a
+// session's active skills (<= 0 means unlimited, the default). Activations that would exceed it fail with
+// spec.ErrSkillBudgetExceeded, and skills-load/skills-unload report the session's usage.
+func WithMaxActiveBytesPerSession(n int) Option {
+	return func(o *runtimeOptions) error {
+		o.maxActiveBytesPerSession = n
+		return nil
+	}
+}
+
+// WithMaxActiveTokensPerSession is like WithMaxActiveBytesPerSession but budgets tokens as counted by the
+// token counter set with WithTokenCounter, which is required when n > 0.
+func WithMaxActiveTokensPerSession(n int) Option {
+	return func(o *runtimeOptions) error {
+		o.maxActiveTokensPerSession = n
+		return nil
+	}
+}
+
+// WithTokenCounter sets the token counter used for session token budgets.
+func WithTokenCounter(tc spec.TokenCounter) Option {
+	return func(o *runtimeOptions) error {
+		if tc == nil {
+			return fmt.Errorf("%w: nil token counter", spec.ErrInvalidArgument)
+		}
+		o.tokenCounter = tc
+		return nil
+	}
+}
+
 func WithSessionTTL(ttl time.Duration) Option {
 	return func(o *runtimeOptions) error {
 		o.sessionTTL = ttl
@@ -88,6 +131,68 @@ func WithMaxSessions(maxSessions int) Option {
 	}
 }
 
+// WithFileSessionStore persists sessions as one JSON file per session in dir (created if missing),
+// so sessions survive restarts and can be shared by runtimes in several processes using the same dir.
+// TTL (WithSessionTTL) and MaxSessions (WithMaxSessions) eviction apply as for the default in-memory store.
+//
+// Every runtime sharing dir must register the same skills; sessions reference skills by provider keys.
+func WithFileSessionStore(dir string) Option {
+	return func(o *runtimeOptions) error {
+		if strings.TrimSpace(dir) == "" || strings.TrimSpace(dir) != dir {
+			return fmt.Errorf("%w: invalid session store directory %q", spec.ErrInvalidArgument, dir)
+		}
+		o.sessionStoreDir = dir
+		return nil
+	}
+}
+
+// WithSessionStore persists sessions in store, so sessions survive restarts and can be shared by runtimes
+// in several processes using the same store (e.g. a database). TTL (WithSessionTTL) and MaxSessions
+// (WithMaxSessions) eviction apply as for the default in-memory store, using the store's last-used times.
+//
+// Every runtime sharing the store must register the same skills; sessions reference skills by provider keys.
+// It cannot be combined with WithFileSessionStore.
+func WithSessionStore(store spec.SessionStateStore) Option {
+	return func(o *runtimeOptions) error {
+		if store == nil {
+			return fmt.Errorf("%w: nil session store", spec.ErrInvalidArgument)
+		}
+		o.sessionStore = store
+		return nil
+	}
+}
+
+// WithSkillVersions pins, per skill name, which version LLM-facing handles resolve to when several versions
+// of the skill are registered (skill name -> semantic version from SKILL.md "version"). Without a pin, the
+// highest release version is selected (or the highest pre-release if there is no release). Pins naming
+// versions that are not registered are ignored. Sessions can override pins with WithSessionSkillVersions.
+func WithSkillVersions(pins map[string]string) Option {
+	snap := maps.Clone(pins)
+	return func(o *runtimeOptions) error {
+		if err := validateSkillVersionPins(snap); err != nil {
+			return err
+		}
+		if o.skillVersions == nil {
+			o.skillVersions = map[string]string{}
+		}
+		maps.Copy(o.skillVersions, snap)
+		return nil
+	}
+}
+
+// WithEmbedder enables semantic skill discovery: Runtime.RecommendSkills, and ranking by SearchQuery in
+// SkillsPrompt search mode. Each skill's name, description and tags are embedded when it is added or
+// refreshed. See package hashembedder for a deterministic embedder that needs no model.
+func WithEmbedder(e spec.Embedder) Option {
+	return func(o *runtimeOptions) error {
+		if e == nil {
+			return fmt.Errorf("%w: nil embedder", spec.ErrInvalidArgument)
+		}
+		o.embedder = e
+		return nil
+	}
+}
+
 type providerResolver struct {
 	m map[string]spec.SkillProvider
 }
@@ -117,6 +222,12 @@ func New(opts ...Option) (*Runtime, error) {
 	if cfg.logger == nil {
 		cfg.logger = slog.Default()
 	}
+	if cfg.maxActiveTokensPerSession > 0 && cfg.tokenCounter == nil {
+		return nil, fmt.Errorf("%w: token budget requires a token counter", spec.ErrInvalidArgument)
+	}
+	if cfg.sessionStoreDir != "" && cfg.sessionStore != nil {
+		return nil, fmt.Errorf("%w: both a file session store and a session store configured", spec.ErrInvalidArgument)
+	}
 
 	// Build immutable providers map.
 	providers := map[string]spec.SkillProvider{}
@@ -151,20 +262,44 @@ func New(opts ...Option) (*Runtime, error) {
 
 	res := providerResolver{m: providers}
 	cat := catalog.New(res)
+	cat.SetVersionPins(cfg.skillVersions)
+	if cfg.embedder != nil {
+		cat.SetEmbedder(cfg.embedder)
+	}
+	bus := newEventBus()
 
-	st := session.NewStore(session.StoreConfig{
+	storeCfg := session.StoreConfig{
 		TTL:                 cfg.sessionTTL,
 		MaxSessions:         cfg.maxSessions,
 		MaxActivePerSession: cfg.maxActivePerSession,
 		Catalog:             cat,
 		Providers:           res,
-	})
+		Notify:              sessionEventPublisher(bus, cat),
+
+		MaxActiveBytesPerSession:  cfg.maxActiveBytesPerSession,
+		MaxActiveTokensPerSession: cfg.maxActiveTokensPerSession,
+		TokenCounter:              cfg.tokenCounter,
+	}
+	var st session.SessionStore
+	switch {
+	case cfg.sessionStore != nil:
+		st = session.NewPersistentStore(cfg.sessionStore, storeCfg)
+	case cfg.sessionStoreDir != "":
+		fst, err := session.NewFileStore(cfg.sessionStoreDir, storeCfg)
+		if err != nil {
+			return nil, err
+		}
+		st = fst
+	default:
+		st = session.NewStore(storeCfg)
+	}
 
 	rt := &Runtime{
 		logger:    cfg.logger,
 		providers: providers,
 		catalog:   cat,
 		sessions:  st,
+		events:    bus,
 	}
 	return rt, nil
 }
@@ -206,7 +341,12 @@ func (r *Runtime) AddSkill(ctx context.Context, def spec.SkillDef) (spec.SkillRe
 		)
 	}
 
-	return r.catalog.Add(ctx, def)
+	rec, err := r.catalog.Add(ctx, def)
+	if err != nil {
+		return spec.SkillRecord{}, err
+	}
+	r.events.publish(spec.Event{Type: spec.EventTypeSkillAdded, Skills: []spec.SkillDef{def}})
+	return rec, nil
 }
 
 // RemoveSkill removes a skill from the catalog (and prunes it from all sessions).
@@ -240,11 +380,168 @@ func (r *Runtime) RemoveSkill(ctx context.Context, def spec.SkillDef) (spec.Skil
 		return spec.SkillRecord{}, spec.ErrSkillNotFound
 	}
 
+	r.events.publish(spec.Event{Type: spec.EventTypeSkillRemoved, Skills: []spec.SkillDef{def}})
+
 	// Prune using canonical/internal key.
-	r.sessions.PruneSkill(canonKey)
+	r.events.publishPruned(def, r.sessions.PruneSkill(canonKey))
 	return rec, nil
 }
 
+// RefreshSkill re-indexes a registered skill and updates the catalog in place.
+//
+// IMPORTANT CONTRACT:
+//   - This is a HOST/LIFECYCLE API; def must be the exact user-provided definition that was added.
+//   - Updated skills stay active in every session that had them loaded; the cached body is invalidated
+//     so the next prompt/activation sees the new content.
+//   - Skills the provider reports as missing are removed from the catalog and pruned from all sessions.
+//   - Provider failures are reported in the result (Status=failed) and leave the previous entry intact.
+func (r *Runtime) RefreshSkill(ctx context.Context, def spec.SkillDef) (spec.SkillRefreshResult, error) {
+	if ctx == nil {
+		return spec.SkillRefreshResult{}, fmt.Errorf("%w: nil context", spec.ErrInvalidArgument)
+	}
+	if err := ctx.Err(); err != nil {
+		return spec.SkillRefreshResult{}, err
+	}
+	if r == nil {
+		return spec.SkillRefreshResult{}, fmt.Errorf("%w: nil runtime receiver", spec.ErrInvalidArgument)
+	}
+
+	if strings.TrimSpace(def.Type) != def.Type ||
+		strings.TrimSpace(def.Name) != def.Name ||
+		strings.TrimSpace(def.Location) != def.Location {
+		return spec.SkillRefreshResult{}, fmt.Errorf(
+			"%w: def fields must not contain leading/trailing whitespace",
+			spec.ErrInvalidArgument,
+		)
+	}
+
+	return r.refreshSkill(ctx, def)
+}
+
+// RefreshAll re-indexes every registered skill and returns one result per skill,
+// sorted by def name then location.
+//
+// On context cancellation the results gathered so far are returned along with the context error.
+func (r *Runtime) RefreshAll(ctx context.Context) ([]spec.SkillRefreshResult, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("%w: nil context", spec.ErrInvalidArgument)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, fmt.Errorf("%w: nil runtime receiver", spec.ErrInvalidArgument)
+	}
+
+	entries := r.catalog.ListUserEntries(catalog.UserFilter{})
+	out := make([]spec.SkillRefreshResult, 0, len(entries))
+	for _, e := range entries {
+		res, err := r.refreshSkill(ctx, e.Record.Def)
+		if err != nil {
+			if errors.Is(err, spec.ErrSkillNotFound) {
+				// Removed concurrently by the host; nothing to report.
+				continue
+			}
+			return out, err
+		}
+		out = append(out, res)
+	}
+	return out, nil
+}
+
+// DiscoverSkills enumerates candidate skills below rootLocation using the provider registered for
+// skillType and registers every candidate in one call.
+//
+// IMPORTANT CONTRACT:
+//   - This is a HOST/LIFECYCLE API; the provider must implement spec.SkillDiscoverer.
+//   - Candidate defs use locations derived from the user-provided rootLocation (no canonicalization leakage).
+//   - Per-location outcomes (added/skipped/failed) are reported in the results; already registered
+//     skills are reported as skipped. The returned error is non-nil only for argument, provider, or
+//     context errors.
+func (r *Runtime) DiscoverSkills(
+	ctx context.Context,
+	skillType string,
+	rootLocation string,
+	opts *spec.SkillDiscoveryOptions,
+) ([]spec.SkillDiscoveryResult, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("%w: nil context", spec.ErrInvalidArgument)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, fmt.Errorf("%w: nil runtime receiver", spec.ErrInvalidArgument)
+	}
+
+	if strings.TrimSpace(skillType) != skillType || strings.TrimSpace(rootLocation) != rootLocation {
+		return nil, fmt.Errorf(
+			"%w: type and root location must not contain leading/trailing whitespace",
+			spec.ErrInvalidArgument,
+		)
+	}
+	if skillType == "" || rootLocation == "" {
+		return nil, fmt.Errorf("%w: type and root location are required", spec.ErrInvalidArgument)
+	}
+
+	p, ok := r.providers[skillType]
+	if !ok {
+		return nil, errors.Join(spec.ErrProviderNotFound, fmt.Errorf("unknown provider type: %q", skillType))
+	}
+	d, ok := p.(spec.SkillDiscoverer)
+	if !ok {
+		return nil, fmt.Errorf("%w: provider %q does not support discovery", spec.ErrInvalidArgument, skillType)
+	}
+
+	var o spec.SkillDiscoveryOptions
+	if opts != nil {
+		o = *opts
+		o.Ignore = append([]string(nil), opts.Ignore...)
+	}
+	if o.MaxDepth <= 0 {
+		o.MaxDepth = 1
+	}
+
+	candidates, err := d.Discover(ctx, rootLocation, o)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]spec.SkillDiscoveryResult, 0, len(candidates))
+	for _, c := range candidates {
+		if err := ctx.Err(); err != nil {
+			return out, err
+		}
+		res := spec.SkillDiscoveryResult{Def: c.Def}
+		switch {
+		case c.SkipReason != "":
+			res.Status = spec.SkillDiscoveryStatusSkipped
+			res.Reason = c.SkipReason
+		case c.Def.Type != skillType:
+			res.Status = spec.SkillDiscoveryStatusFailed
+			res.Error = fmt.Sprintf("provider returned def with type %q", c.Def.Type)
+		default:
+			rec, addErr := r.AddSkill(ctx, c.Def)
+			switch {
+			case addErr == nil:
+				res.Status = spec.SkillDiscoveryStatusAdded
+				res.Record = rec
+			case errors.Is(addErr, spec.ErrSkillAlreadyExists):
+				res.Status = spec.SkillDiscoveryStatusSkipped
+				res.Reason = "already registered"
+			default:
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					return out, ctxErr
+				}
+				res.Status = spec.SkillDiscoveryStatusFailed
+				res.Error = addErr.Error()
+			}
+		}
+		out = append(out, res)
+	}
+	return out, nil
+}
+
 // ListSkills lists skills for HOST/LIFECYCLE usage.
 //
 // IMPORTANT CONTRACT:
@@ -261,6 +558,42 @@ func (r *Runtime) ListSkills(ctx context.Context, filter *SkillListFilter) ([]sp
 		return nil, fmt.Errorf("%w: nil runtime receiver", spec.ErrInvalidArgument)
 	}
 
+	f, keep, err := r.skillListScope(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	entries, _ := r.catalog.ListUserPage(f, catalog.UserPageQuery{Keep: keep})
+	out := make([]spec.SkillRecord, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, e.Record)
+	}
+	return out, nil
+}
+
+// ListSkillDefs returns the user-provided definitions of the registered skills of providerType (all skills
+// if it is empty). It is a HOST/LIFECYCLE API, e.g. for watchers that only need the definitions.
+func (r *Runtime) ListSkillDefs(ctx context.Context, providerType string) ([]spec.SkillDef, error) {
+	var filter *SkillListFilter
+	if providerType != "" {
+		filter = &SkillListFilter{Types: []string{providerType}}
+	}
+	recs, err := r.ListSkills(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]spec.SkillDef, 0, len(recs))
+	for _, rec := range recs {
+		out = append(out, rec.Def)
+	}
+	return out, nil
+}
+
+// skillListScope validates filter and converts it to a catalog filter plus an optional session-activity key
+// filter.
+func (r *Runtime) skillListScope(
+	ctx context.Context,
+	filter *SkillListFilter,
+) (catalog.UserFilter, func(spec.ProviderSkillKey) bool, error) {
 	cfg := normalizeSkillListFilter(filter)
 
 	// Validate activity/session constraints early.
@@ -269,60 +602,77 @@ func (r *Runtime) ListSkills(ctx context.Context, filter *SkillListFilter) ([]sp
 		// OK.
 	case spec.SkillActivityActive:
 		if cfg.SessionID == "" {
-			return nil, fmt.Errorf("%w: activity=active requires sessionID", spec.ErrInvalidArgument)
+			return catalog.UserFilter{}, nil, fmt.Errorf(
+				"%w: activity=active requires sessionID",
+				spec.ErrInvalidArgument,
+			)
 		}
 	default:
-		return nil, fmt.Errorf("%w: invalid activity %q", spec.ErrInvalidArgument, cfg.Activity)
+		return catalog.UserFilter{}, nil, fmt.Errorf("%w: invalid activity %q", spec.ErrInvalidArgument, cfg.Activity)
 	}
-
-	entries := r.catalog.ListUserEntries(toCatalogUserFilter(&cfg))
+	if err := validateTagMatch(cfg.TagMatch); err != nil {
+		return catalog.UserFilter{}, nil, err
+	}
+	uf := toCatalogUserFilter(&cfg)
 
 	// No session => no active skills exist; "inactive" behaves like "all".
 	if cfg.SessionID == "" {
-		out := make([]spec.SkillRecord, 0, len(entries))
-		for _, e := range entries {
-			out = append(out, e.Record)
-		}
-		return out, nil
+		return uf, nil, nil
 	}
 
 	// Session-scoped filtering.
 	s, ok := r.sessions.Get(string(cfg.SessionID))
 	if !ok {
-		return nil, spec.ErrSessionNotFound
+		return catalog.UserFilter{}, nil, spec.ErrSessionNotFound
 	}
 	keys, err := s.ActiveKeys(ctx)
 	if err != nil {
-		return nil, err
+		return catalog.UserFilter{}, nil, err
+	}
+	if cfg.Activity == spec.SkillActivityAny {
+		return uf, nil, nil
 	}
 	activeSet := make(map[spec.ProviderSkillKey]struct{}, len(keys))
 	for _, k := range keys {
 		activeSet[k] = struct{}{}
 	}
+	wantActive := cfg.Activity == spec.SkillActivityActive
+	return uf, func(k spec.ProviderSkillKey) bool {
+		_, isActive := activeSet[k]
+		return isActive == wantActive
+	}, nil
+}
 
-	if cfg.Activity == spec.SkillActivityAny {
-		out := make([]spec.SkillRecord, 0, len(entries))
-		for _, e := range entries {
-			out = append(out, e.Record)
-		}
-		return out, nil
+// RecommendSkills returns up to k registered skills most semantically similar to query, most similar first.
+// It requires an embedder (WithEmbedder); without one it fails with spec.ErrInvalidArgument.
+//
+// IMPORTANT CONTRACT:
+//   - This is a HOST/LIFECYCLE API; results carry user-provided skill definitions in Record.Def.
+func (r *Runtime) RecommendSkills(ctx context.Context, query string, k int) ([]spec.SkillRecommendation, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("%w: nil context", spec.ErrInvalidArgument)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, fmt.Errorf("%w: nil runtime receiver", spec.ErrInvalidArgument)
+	}
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("%w: query is required", spec.ErrInvalidArgument)
+	}
+	if k <= 0 {
+		return nil, fmt.Errorf("%w: k must be positive, got %d", spec.ErrInvalidArgument, k)
 	}
 
-	out := make([]spec.SkillRecord, 0, len(entries))
-	for _, e := range entries {
-		_, isActive := activeSet[e.Key]
-		switch cfg.Activity {
-		case spec.SkillActivityActive:
-			if isActive {
-				out = append(out, e.Record)
-			}
-		case spec.SkillActivityInactive:
-			if !isActive {
-				out = append(out, e.Record)
-			}
-		default:
-			// Any already handled.
-		}
+	hits, err := r.catalog.Recommend(ctx, query, catalog.PromptFilter{})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]spec.SkillRecommendation, 0, min(k, len(hits)))
+	for _, hit := range hits[:min(k, len(hits))] {
+		out = append(out, spec.SkillRecommendation{Record: hit.UserRecord(), Score: hit.Score})
 	}
 	return out, nil
 }
@@ -330,9 +680,23 @@ func (r *Runtime) ListSkills(ctx context.Context, filter *SkillListFilter) ([]sp
 type newSessionOptions struct {
 	// If >0 overrides runtime/store default.
 	maxActivePerSession int
+	maxActiveBytes      int
+	maxActiveTokens     int
 
 	// Optional initial active set (HOST/LIFECYCLE definitions).
 	activeDefs []spec.SkillDef
+
+	// Optional initial pinned skills (HOST/LIFECYCLE definitions).
+	pinnedDefs []spec.SkillDef
+
+	// Optional skill policy.
+	policy *spec.SessionSkillPolicy
+
+	// Optional automatic eviction policy.
+	eviction *spec.SkillEvictionPolicy
+
+	// Optional version pins (skill name -> version).
+	skillVersions map[string]string
 }
 
 // SessionOption configures Runtime.NewSession.
@@ -347,6 +711,24 @@ func WithSessionMaxActivePerSession(n int) SessionOption {
 	}
 }
 
+// WithSessionMaxActiveBytes overrides the active skills body byte budget (see WithMaxActiveBytesPerSession)
+// for this session only. If n <= 0, it is ignored (defaults apply).
+func WithSessionMaxActiveBytes(n int) SessionOption {
+	return func(o *newSessionOptions) error {
+		o.maxActiveBytes = n
+		return nil
+	}
+}
+
+// WithSessionMaxActiveTokens overrides the active skills body token budget (see WithMaxActiveTokensPerSession)
+// for this session only. If n <= 0, it is ignored (defaults apply). It requires a runtime token counter.
+func WithSessionMaxActiveTokens(n int) SessionOption {
+	return func(o *newSessionOptions) error {
+		o.maxActiveTokens = n
+		return nil
+	}
+}
+
 // WithSessionActiveSkills sets the initial active skills for the new session (host/lifecycle defs).
 // These are activated during session creation.
 func WithSessionActiveSkills(defs []spec.SkillDef) SessionOption {
@@ -357,6 +739,96 @@ func WithSessionActiveSkills(defs []spec.SkillDef) SessionOption {
 	}
 }
 
+// WithSessionPinnedSkills sets initial pinned skills for the new session (host/lifecycle defs).
+// Pinned skills are activated during session creation and stay active for the whole session: skills-load
+// with mode=replace and skills-unload (even with all=true) keep them. Only DeactivateSkills removes them.
+//
+// Pinned skills count against the session's max active skills limit. Defs also passed to
+// WithSessionActiveSkills keep their position; others are activated after the active skills.
+func WithSessionPinnedSkills(defs []spec.SkillDef) SessionOption {
+	snap := append([]spec.SkillDef(nil), defs...)
+	return func(o *newSessionOptions) error {
+		o.pinnedDefs = snap
+		return nil
+	}
+}
+
+// WithSessionSkillPolicy restricts which skills the session may activate (host or skills-load) and which
+// skills SkillsPrompt advertises for the session. Activating a skill outside the policy fails with
+// spec.ErrSkillNotAllowed. See spec.SessionSkillPolicy for matching rules.
+//
+// It replaces any policy set by earlier options.
+func WithSessionSkillPolicy(p spec.SessionSkillPolicy) SessionOption {
+	snap := spec.SessionSkillPolicy{
+		AllowSkills: append([]spec.SkillDef(nil), p.AllowSkills...),
+		DenySkills:  append([]spec.SkillDef(nil), p.DenySkills...),
+		AllowTags:   append([]string(nil), p.AllowTags...),
+		DenyTags:    append([]string(nil), p.DenyTags...),
+		AllowTypes:  append([]string(nil), p.AllowTypes...),
+		DenyTypes:   append([]string(nil), p.DenyTypes...),
+	}
+	return func(o *newSessionOptions) error {
+		if err := validateSessionSkillPolicy(&snap); err != nil {
+			return err
+		}
+		p := snap
+		o.policy = &p
+		return nil
+	}
+}
+
+// WithSessionAllowedSkills restricts the session to an explicit allowlist of skill defs (host/lifecycle defs).
+// It sets AllowSkills of the session policy (see WithSessionSkillPolicy); defs need not be registered yet.
+func WithSessionAllowedSkills(defs []spec.SkillDef) SessionOption {
+	snap := append([]spec.SkillDef(nil), defs...)
+	return func(o *newSessionOptions) error {
+		if len(snap) == 0 {
+			return fmt.Errorf("%w: allowed skills must not be empty", spec.ErrInvalidArgument)
+		}
+		if o.policy == nil {
+			o.policy = &spec.SessionSkillPolicy{}
+		}
+		o.policy.AllowSkills = snap
+		return validateSessionSkillPolicy(o.policy)
+	}
+}
+
+// WithSessionEvictionPolicy opts the session into automatic eviction: when an activation (host or
+// skills-load) would exceed the session's max active skills limit or body budget, other non-pinned skills
+// are unloaded in the policy's order instead of failing the activation. skills-load reports them in
+// evictedSkills. See spec.SkillEvictionPolicy.
+func WithSessionEvictionPolicy(p spec.SkillEvictionPolicy) SessionOption {
+	snap := spec.SkillEvictionPolicy{
+		Mode:       p.Mode,
+		Priorities: append([]spec.SkillPriority(nil), p.Priorities...),
+	}
+	return func(o *newSessionOptions) error {
+		if err := validateSkillEvictionPolicy(&snap); err != nil {
+			return err
+		}
+		p := snap
+		o.eviction = &p
+		return nil
+	}
+}
+
+// WithSessionSkillVersions pins skill versions for this session only (see WithSkillVersions); they take
+// precedence over the runtime pins for the same names. The session's skills-load, skills-unload and
+// SkillsPrompt resolve handles of a multi-version skill to the selected version.
+func WithSessionSkillVersions(pins map[string]string) SessionOption {
+	snap := maps.Clone(pins)
+	return func(o *newSessionOptions) error {
+		if err := validateSkillVersionPins(snap); err != nil {
+			return err
+		}
+		if o.skillVersions == nil {
+			o.skillVersions = map[string]string{}
+		}
+		maps.Copy(o.skillVersions, snap)
+		return nil
+	}
+}
+
 // NewSession creates a new session.
 //
 // IMPORTANT CONTRACT:
@@ -385,37 +857,40 @@ func (r *Runtime) NewSession(ctx context.Context, opts ...SessionOption) (spec.S
 	}
 
 	// Resolve host defs -> canonical/internal keys (in order), without exposing canonicalization.
-	var activeKeys []spec.ProviderSkillKey
-	if len(cfg.activeDefs) > 0 {
-		seen := map[spec.SkillDef]struct{}{}
-		activeKeys = make([]spec.ProviderSkillKey, 0, len(cfg.activeDefs))
-		for _, d := range cfg.activeDefs {
-			if _, dup := seen[d]; dup {
-				return "", nil, fmt.Errorf("%w: duplicate active skill def: %+v", spec.ErrInvalidArgument, d)
-			}
-			seen[d] = struct{}{}
-
-			k, ok := r.catalog.ResolveDef(d)
-			if !ok {
-				return "", nil, fmt.Errorf("%w: unknown skill def: %+v", spec.ErrSkillNotFound, d)
-			}
-			activeKeys = append(activeKeys, k)
-		}
+	activeKeys, err := r.resolveInitialDefs(cfg.activeDefs, "active")
+	if err != nil {
+		return "", nil, err
+	}
+	pinnedKeys, err := r.resolveInitialDefs(cfg.pinnedDefs, "pinned")
+	if err != nil {
+		return "", nil, err
 	}
 
 	id, _, err := r.sessions.NewSession(ctx, session.NewSessionParams{
 		MaxActivePerSession: cfg.maxActivePerSession,
+		MaxActiveBytes:      cfg.maxActiveBytes,
+		MaxActiveTokens:     cfg.maxActiveTokens,
 		ActiveKeys:          activeKeys,
+		PinnedKeys:          pinnedKeys,
+		Policy:              cfg.policy,
+		Eviction:            cfg.eviction,
+		Versions:            cfg.skillVersions,
 	})
 	if err != nil {
 		return "", nil, err
 	}
 
 	// Return exactly what the host provided (no computed handles / no canonicalization leakage).
-	if len(cfg.activeDefs) == 0 {
+	if len(cfg.activeDefs) == 0 && len(cfg.pinnedDefs) == 0 {
 		return spec.SessionID(id), nil, nil
 	}
-	return spec.SessionID(id), append([]spec.SkillDef(nil), cfg.activeDefs...), nil
+	out := append([]spec.SkillDef(nil), cfg.activeDefs...)
+	for _, d := range cfg.pinnedDefs {
+		if !slices.Contains(cfg.activeDefs, d) {
+			out = append(out, d)
+		}
+	}
+	return spec.SessionID(id), out, nil
 }
 
 func (r *Runtime) CloseSession(ctx context.Context, sid spec.SessionID) error {
@@ -435,6 +910,225 @@ func (r *Runtime) CloseSession(ctx context.Context, sid spec.SessionID) error {
 	return nil
 }
 
+// ListSessions returns all live sessions, most recently used first.
+//
+// Listing does not mark sessions as used, so it does not extend their TTL.
+//
+// IMPORTANT CONTRACT:
+//   - This is a HOST/LIFECYCLE API; active skills are reported as user-provided skill definitions.
+func (r *Runtime) ListSessions(ctx context.Context) ([]spec.SessionInfo, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("%w: nil context", spec.ErrInvalidArgument)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, fmt.Errorf("%w: nil runtime receiver", spec.ErrInvalidArgument)
+	}
+
+	infos := r.sessions.List()
+	out := make([]spec.SessionInfo, 0, len(infos))
+	for _, info := range infos {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		s, cur, ok := r.sessions.Peek(info.ID)
+		if !ok {
+			// Closed or evicted concurrently.
+			continue
+		}
+		si, err := r.sessionInfo(s, cur)
+		if err != nil {
+			if errors.Is(err, spec.ErrSessionNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		out = append(out, si)
+	}
+	return out, nil
+}
+
+// GetSession returns a live session's info. It does not mark the session as used.
+//
+// IMPORTANT CONTRACT:
+//   - This is a HOST/LIFECYCLE API; active skills are reported as user-provided skill definitions.
+func (r *Runtime) GetSession(ctx context.Context, sid spec.SessionID) (spec.SessionInfo, error) {
+	if ctx == nil {
+		return spec.SessionInfo{}, fmt.Errorf("%w: nil context", spec.ErrInvalidArgument)
+	}
+	if err := ctx.Err(); err != nil {
+		return spec.SessionInfo{}, err
+	}
+	if r == nil {
+		return spec.SessionInfo{}, fmt.Errorf("%w: nil runtime receiver", spec.ErrInvalidArgument)
+	}
+
+	s, info, ok := r.sessions.Peek(string(sid))
+	if !ok {
+		return spec.SessionInfo{}, spec.ErrSessionNotFound
+	}
+	return r.sessionInfo(s, info)
+}
+
+// ExportSession returns a serializable snapshot of a session: its active skills (host definitions, in
+// activation order, with the digest each had when it was activated) and its limits.
+//
+// IMPORTANT CONTRACT:
+//   - This is a HOST/LIFECYCLE API; the snapshot contains only user-provided skill definitions.
+//   - Skills removed from the catalog are pruned from the session and not exported.
+func (r *Runtime) ExportSession(ctx context.Context, sid spec.SessionID) (spec.SessionSnapshot, error) {
+	if ctx == nil {
+		return spec.SessionSnapshot{}, fmt.Errorf("%w: nil context", spec.ErrInvalidArgument)
+	}
+	if err := ctx.Err(); err != nil {
+		return spec.SessionSnapshot{}, err
+	}
+	if r == nil {
+		return spec.SessionSnapshot{}, fmt.Errorf("%w: nil runtime receiver", spec.ErrInvalidArgument)
+	}
+
+	s, ok := r.sessions.Get(string(sid))
+	if !ok {
+		return spec.SessionSnapshot{}, spec.ErrSessionNotFound
+	}
+	active, err := s.ActiveSkills(ctx)
+	if err != nil {
+		return spec.SessionSnapshot{}, err
+	}
+
+	out := spec.SessionSnapshot{
+		SessionID:           sid,
+		MaxActivePerSession: s.MaxActive(),
+		MaxActiveBytes:      s.MaxActiveBytes(),
+		MaxActiveTokens:     s.MaxActiveTokens(),
+		ActiveSkills:        make([]spec.SessionSkillSnapshot, 0, len(active)),
+		Policy:              s.Policy(),
+		Eviction:            s.Eviction(),
+		SkillVersions:       s.Versions(),
+	}
+	for _, a := range active {
+		def, ok := r.catalog.DefForKey(a.Key)
+		if !ok {
+			// Removed concurrently.
+			continue
+		}
+		out.ActiveSkills = append(out.ActiveSkills, spec.SessionSkillSnapshot{
+			Def:      def,
+			Digest:   a.Digest,
+			Pinned:   a.Pinned,
+			Implicit: a.Implicit,
+		})
+	}
+	return out, nil
+}
+
+// ImportSession recreates a session from a snapshot returned by ExportSession.
+//
+// The snapshot session ID is reused when set (it must not belong to a live session); otherwise a new ID
+// is generated. Snapshot skills that are no longer registered are skipped and reported in MissingSkills.
+// Skills whose current digest differs from the snapshot digest are restored and reported in ChangedSkills.
+//
+// IMPORTANT CONTRACT:
+//   - This is a HOST/LIFECYCLE API; it accepts and returns only user-provided skill definitions.
+func (r *Runtime) ImportSession(
+	ctx context.Context,
+	snapshot spec.SessionSnapshot,
+) (spec.SessionImportResult, error) {
+	if ctx == nil {
+		return spec.SessionImportResult{}, fmt.Errorf("%w: nil context", spec.ErrInvalidArgument)
+	}
+	if err := ctx.Err(); err != nil {
+		return spec.SessionImportResult{}, err
+	}
+	if r == nil {
+		return spec.SessionImportResult{}, fmt.Errorf("%w: nil runtime receiver", spec.ErrInvalidArgument)
+	}
+
+	if snapshot.Policy != nil {
+		if err := validateSessionSkillPolicy(snapshot.Policy); err != nil {
+			return spec.SessionImportResult{}, err
+		}
+	}
+	if snapshot.Eviction != nil {
+		if err := validateSkillEvictionPolicy(snapshot.Eviction); err != nil {
+			return spec.SessionImportResult{}, err
+		}
+	}
+	if err := validateSkillVersionPins(snapshot.SkillVersions); err != nil {
+		return spec.SessionImportResult{}, err
+	}
+
+	var res spec.SessionImportResult
+	var pinned []spec.ProviderSkillKey
+	changed := map[spec.ProviderSkillKey]spec.SessionSkillChange{}
+	keys := make([]spec.ProviderSkillKey, 0, len(snapshot.ActiveSkills))
+	seen := map[spec.SkillDef]struct{}{}
+	for _, a := range snapshot.ActiveSkills {
+		if _, dup := seen[a.Def]; dup {
+			return spec.SessionImportResult{}, fmt.Errorf(
+				"%w: duplicate active skill def: %+v",
+				spec.ErrInvalidArgument,
+				a.Def,
+			)
+		}
+		seen[a.Def] = struct{}{}
+
+		k, ok := r.catalog.ResolveDef(a.Def)
+		if !ok {
+			res.MissingSkills = append(res.MissingSkills, a.Def)
+			continue
+		}
+		idx, ok := r.catalog.GetIndex(k)
+		if !ok {
+			res.MissingSkills = append(res.MissingSkills, a.Def)
+			continue
+		}
+		if a.Digest != "" && idx.Digest != a.Digest {
+			changed[k] = spec.SessionSkillChange{Def: a.Def, SnapshotDigest: a.Digest, CurrentDigest: idx.Digest}
+		}
+		if !a.Implicit {
+			keys = append(keys, k)
+		}
+		if a.Pinned {
+			pinned = append(pinned, k)
+		}
+	}
+
+	id, handles, err := r.sessions.NewSession(ctx, session.NewSessionParams{
+		ID:                  string(snapshot.SessionID),
+		MaxActivePerSession: snapshot.MaxActivePerSession,
+		MaxActiveBytes:      snapshot.MaxActiveBytes,
+		MaxActiveTokens:     snapshot.MaxActiveTokens,
+		ActiveKeys:          keys,
+		PinnedKeys:          pinned,
+		Policy:              snapshot.Policy,
+		Eviction:            snapshot.Eviction,
+		Versions:            snapshot.SkillVersions,
+	})
+	if err != nil {
+		return spec.SessionImportResult{}, err
+	}
+	res.SessionID = spec.SessionID(id)
+
+	// Implicit skills are only restored if a restored skill still requires them, so the result reports what
+	// the session actually activated.
+	for _, h := range handles {
+		k, ok := r.catalog.ResolveHandle(h)
+		if !ok {
+			continue
+		}
+		if d, ok := r.catalog.DefForKey(k); ok {
+			res.ActiveSkills = append(res.ActiveSkills, d)
+		}
+		if c, ok := changed[k]; ok {
+			res.ChangedSkills = append(res.ChangedSkills, c)
+		}
+	}
+	return res, nil
+}
+
 func (r *Runtime) NewSessionRegistry(
 	ctx context.Context,
 	sid spec.SessionID,
@@ -455,3 +1149,157 @@ func (r *Runtime) NewSessionRegistry(
 	}
 	return s.NewRegistry(opts...)
 }
+
+// sessionInfo builds the info of a peeked session. It must not mark the session as used.
+func (r *Runtime) sessionInfo(s *session.Session, info session.Info) (spec.SessionInfo, error) {
+	active, err := s.PeekActiveSkills()
+	if err != nil {
+		return spec.SessionInfo{}, err
+	}
+	out := spec.SessionInfo{
+		SessionID:           spec.SessionID(info.ID),
+		CreatedAt:           info.CreatedAt,
+		LastUsedAt:          info.LastUsed,
+		TTLRemaining:        max(time.Until(info.ExpiresAt), 0),
+		MaxActivePerSession: s.MaxActive(),
+		MaxActiveBytes:      s.MaxActiveBytes(),
+		MaxActiveTokens:     s.MaxActiveTokens(),
+		Policy:              s.Policy(),
+		Eviction:            s.Eviction(),
+		SkillVersions:       s.Versions(),
+	}
+	for _, a := range active {
+		def, ok := r.catalog.DefForKey(a.Key)
+		if !ok {
+			continue
+		}
+		out.ActiveSkills = append(out.ActiveSkills, def)
+		if a.Pinned {
+			out.PinnedSkills = append(out.PinnedSkills, def)
+		}
+	}
+	return out, nil
+}
+
+// resolveInitialDefs maps NewSession host defs to internal keys (in order); duplicates are rejected.
+func (r *Runtime) resolveInitialDefs(defs []spec.SkillDef, what string) ([]spec.ProviderSkillKey, error) {
+	if len(defs) == 0 {
+		return nil, nil
+	}
+	seen := map[spec.SkillDef]struct{}{}
+	keys := make([]spec.ProviderSkillKey, 0, len(defs))
+	for _, d := range defs {
+		if _, dup := seen[d]; dup {
+			return nil, fmt.Errorf("%w: duplicate %s skill def: %+v", spec.ErrInvalidArgument, what, d)
+		}
+		seen[d] = struct{}{}
+
+		k, ok := r.catalog.ResolveDef(d)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown skill def: %+v", spec.ErrSkillNotFound, d)
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// validateSessionSkillPolicy rejects policy entries with surrounding whitespace or empty tags/types.
+func validateSessionSkillPolicy(p *spec.SessionSkillPolicy) error {
+	for _, defs := range [][]spec.SkillDef{p.AllowSkills, p.DenySkills} {
+		for _, d := range defs {
+			if strings.TrimSpace(d.Type) != d.Type ||
+				strings.TrimSpace(d.Name) != d.Name ||
+				strings.TrimSpace(d.Location) != d.Location {
+				return fmt.Errorf(
+					"%w: policy def fields must not contain leading/trailing whitespace",
+					spec.ErrInvalidArgument,
+				)
+			}
+		}
+	}
+	for _, vs := range [][]string{p.AllowTags, p.DenyTags, p.AllowTypes, p.DenyTypes} {
+		for _, v := range vs {
+			if v == "" || strings.TrimSpace(v) != v {
+				return fmt.Errorf("%w: invalid policy tag/type %q", spec.ErrInvalidArgument, v)
+			}
+		}
+	}
+	return nil
+}
+
+func validateSkillEvictionPolicy(p *spec.SkillEvictionPolicy) error {
+	switch p.Mode {
+	case spec.SkillEvictionModeLRU, spec.SkillEvictionModeOldest, spec.SkillEvictionModeLowestPriority:
+	default:
+		return fmt.Errorf("%w: invalid eviction mode %q", spec.ErrInvalidArgument, p.Mode)
+	}
+	seen := map[spec.SkillDef]struct{}{}
+	for _, sp := range p.Priorities {
+		d := sp.Def
+		if strings.TrimSpace(d.Type) != d.Type ||
+			strings.TrimSpace(d.Name) != d.Name ||
+			strings.TrimSpace(d.Location) != d.Location {
+			return fmt.Errorf(
+				"%w: eviction priority def fields must not contain leading/trailing whitespace",
+				spec.ErrInvalidArgument,
+			)
+		}
+		if _, dup := seen[d]; dup {
+			return fmt.Errorf("%w: duplicate eviction priority def: %+v", spec.ErrInvalidArgument, d)
+		}
+		seen[d] = struct{}{}
+	}
+	return nil
+}
+
+// validateSkillVersionPins rejects pins with an empty or padded skill name or a value that is not a semantic
+// version.
+func validateSkillVersionPins(pins map[string]string) error {
+	for name, v := range pins {
+		if name == "" || strings.TrimSpace(name) != name {
+			return fmt.Errorf("%w: invalid version pin skill name %q", spec.ErrInvalidArgument, name)
+		}
+		if !semver.IsValid(v) {
+			return fmt.Errorf(
+				"%w: version pin %q for skill %q is not a semantic version",
+				spec.ErrInvalidArgument,
+				v,
+				name,
+			)
+		}
+	}
+	return nil
+}
+
+func (r *Runtime) refreshSkill(ctx context.Context, def spec.SkillDef) (spec.SkillRefreshResult, error) {
+	res, err := r.catalog.Refresh(ctx, def)
+	if err != nil {
+		return spec.SkillRefreshResult{}, err
+	}
+
+	r.events.publish(spec.Event{
+		Type:          spec.EventTypeSkillRefreshed,
+		Skills:        []spec.SkillDef{def},
+		RefreshStatus: res.Status,
+	})
+	switch res.Status {
+	case spec.SkillRefreshStatusRemoved:
+		r.events.publishPruned(def, r.sessions.PruneSkill(res.OldKey))
+	case spec.SkillRefreshStatusUpdated:
+		r.sessions.RekeySkill(res.OldKey, res.NewKey)
+	case spec.SkillRefreshStatusUnchanged, spec.SkillRefreshStatusFailed:
+		// Nothing to propagate to sessions.
+	}
+
+	out := spec.SkillRefreshResult{
+		Def:            def,
+		Status:         res.Status,
+		Record:         res.Record,
+		PreviousDigest: res.PreviousDigest,
+	}
+	if res.Err != nil {
+		out.Error = res.Err.Error()
+		r.logger.Debug("skill refresh", "name", def.Name, "status", res.Status, "error", res.Err)
+	}
+	return out, nil
+}
@@ -0,0 +1,153 @@
+package agentskills
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/flexigpt/agentskills-go/internal/catalog"
+	"github.com/flexigpt/agentskills-go/spec"
+)
+
+const (
+	// DefaultSkillListPageSize is the page size of ListSkillsPage when PageSize is unset.
+	DefaultSkillListPageSize = 100
+
+	// MaxSkillListPageSize is the largest page size ListSkillsPage accepts.
+	MaxSkillListPageSize = 1000
+)
+
+// SkillListPageParams selects the order and window of Runtime.ListSkillsPage.
+type SkillListPageParams struct {
+	// SortBy defaults to spec.SkillSortByName. Ties are broken by name, location and type.
+	SortBy     spec.SkillSortKey
+	Descending bool
+
+	// PageSize defaults to DefaultSkillListPageSize and must not exceed MaxSkillListPageSize.
+	PageSize int
+
+	// Cursor is the NextCursor of the previous page (empty for the first page). It is only valid with the
+	// SortBy and Descending it was issued for.
+	Cursor string
+
+	// Lite omits Resources.Locations and RawFrontmatter from the returned records. Resource counts are kept.
+	Lite bool
+}
+
+// SkillListPage is a page of Runtime.ListSkillsPage.
+type SkillListPage struct {
+	Skills []spec.SkillRecord `json:"skills"`
+
+	// NextCursor fetches the next page; it is empty on the last page.
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// ListSkillsPage lists one page of skills for HOST/LIFECYCLE usage, e.g. for a skill browser over a large
+// catalog. It filters like ListSkills, then sorts and pages.
+//
+// Pagination is keyset-based: the cursor records the sort position of the last returned skill, so skills
+// added or removed between calls never cause duplicates or gaps among the skills that remain.
+func (r *Runtime) ListSkillsPage(
+	ctx context.Context,
+	filter *SkillListFilter,
+	params *SkillListPageParams,
+) (SkillListPage, error) {
+	if ctx == nil {
+		return SkillListPage{}, fmt.Errorf("%w: nil context", spec.ErrInvalidArgument)
+	}
+	if err := ctx.Err(); err != nil {
+		return SkillListPage{}, err
+	}
+	if r == nil {
+		return SkillListPage{}, fmt.Errorf("%w: nil runtime receiver", spec.ErrInvalidArgument)
+	}
+
+	var p SkillListPageParams
+	if params != nil {
+		p = *params
+	}
+	p.SortBy = spec.SkillSortKey(strings.TrimSpace(string(p.SortBy)))
+	switch p.SortBy {
+	case "":
+		p.SortBy = spec.SkillSortByName
+	case spec.SkillSortByName, spec.SkillSortByLocation, spec.SkillSortByType, spec.SkillSortByAddedAt:
+	default:
+		return SkillListPage{}, fmt.Errorf("%w: invalid sort key %q", spec.ErrInvalidArgument, p.SortBy)
+	}
+	if p.PageSize == 0 {
+		p.PageSize = DefaultSkillListPageSize
+	}
+	if p.PageSize < 0 || p.PageSize > MaxSkillListPageSize {
+		return SkillListPage{}, fmt.Errorf(
+			"%w: page size must be between 1 and %d, got %d",
+			spec.ErrInvalidArgument,
+			MaxSkillListPageSize,
+			p.PageSize,
+		)
+	}
+	var after *catalog.PagePosition
+	if p.Cursor != "" {
+		pos, err := decodeSkillListCursor(p.Cursor, p.SortBy, p.Descending)
+		if err != nil {
+			return SkillListPage{}, err
+		}
+		after = &pos
+	}
+
+	f, keep, err := r.skillListScope(ctx, filter)
+	if err != nil {
+		return SkillListPage{}, err
+	}
+	entries, more := r.catalog.ListUserPage(f, catalog.UserPageQuery{
+		SortBy:     p.SortBy,
+		Descending: p.Descending,
+		After:      after,
+		Limit:      p.PageSize,
+		Lite:       p.Lite,
+		Keep:       keep,
+	})
+
+	out := SkillListPage{Skills: make([]spec.SkillRecord, 0, len(entries))}
+	for _, e := range entries {
+		out.Skills = append(out.Skills, e.Record)
+	}
+	if more && len(entries) > 0 {
+		out.NextCursor = encodeSkillListCursor(entries[len(entries)-1].Position(), p.SortBy, p.Descending)
+	}
+	return out, nil
+}
+
+// skillListCursor is the decoded form of a ListSkillsPage cursor: the sort it was issued for and the position
+// of the last skill returned.
+type skillListCursor struct {
+	SortBy     spec.SkillSortKey `json:"s"`
+	Descending bool              `json:"d,omitempty"`
+	Def        spec.SkillDef     `json:"def"`
+	AddedAt    time.Time         `json:"a"`
+}
+
+func encodeSkillListCursor(pos catalog.PagePosition, sortBy spec.SkillSortKey, desc bool) string {
+	raw, _ := json.Marshal(skillListCursor{SortBy: sortBy, Descending: desc, Def: pos.Def, AddedAt: pos.AddedAt})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeSkillListCursor(cursor string, sortBy spec.SkillSortKey, desc bool) (catalog.PagePosition, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return catalog.PagePosition{}, fmt.Errorf("%w: invalid cursor", spec.ErrInvalidArgument)
+	}
+	var c skillListCursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return catalog.PagePosition{}, fmt.Errorf("%w: invalid cursor", spec.ErrInvalidArgument)
+	}
+	if c.SortBy != sortBy || c.Descending != desc {
+		return catalog.PagePosition{}, fmt.Errorf(
+			"%w: cursor was issued for a different sort order",
+			spec.ErrInvalidArgument,
+		)
+	}
+	return catalog.PagePosition{Def: c.Def, AddedAt: c.AddedAt}, nil
+}
@@ -1,9 +1,11 @@
 package agentskills
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/flexigpt/agentskills-go/internal/catalog"
@@ -11,15 +13,16 @@ import (
 )
 
 const (
-	skillsPromptStart = "<<<SKILLS_PROMPT>>>"
-	skillsPromptEnd   = "<<<END_SKILLS_PROMPT>>>"
+	// DefaultSkillsPromptSearchTopN is the number of skills listed in search mode when SearchTopN is unset.
+	DefaultSkillsPromptSearchTopN = 5
 )
 
 // SkillFilter is an optional filter for listing/prompting skills (LLM/prompt-facing).
 //
 // Semantics:
-//   - Types/NamePrefix/LocationPrefix/AllowSkills always apply.
-//   - SessionID (optional) allows filtering/annotating by "active in this session".
+//   - Types/NamePrefix/LocationPrefix/AllowSkills/Tags/ExcludeTags always apply.
+//   - SessionID (optional) allows filtering/annotating by "active in this session", and restricts
+//     to skills permitted by the session's skill policy.
 //   - Activity controls whether to include active, inactive, or both.
 //
 // Defaults:
@@ -42,11 +45,37 @@ type SkillFilter struct {
 	// AllowSkills restricts to an explicit allowlist of host/lifecycle skill defs. Empty means "all".
 	AllowSkills []spec.SkillDef
 
+	// Tags restricts to skills with any of these SKILL.md tags (all of them with TagMatch=all). Empty means
+	// "all". Tags match exactly.
+	Tags []string
+
+	// TagMatch defaults to spec.TagMatchAny.
+	TagMatch spec.TagMatch
+
+	// ExcludeTags drops skills with any of these tags, e.g. ["experimental"].
+	ExcludeTags []string
+
 	// SessionID optionally scopes active/inactive filtering.
 	SessionID spec.SessionID
 
 	// Activity defaults to spec.SkillActivityAny.
 	Activity spec.SkillActivity
+
+	// PromptMode defaults to spec.SkillsPromptModeList. In spec.SkillsPromptModeSearch the available section
+	// lists at most SearchTopN skills and notes how many more the skills-search tool can find.
+	PromptMode spec.SkillsPromptMode
+
+	// SearchTopN is the number of skills listed in search mode (<= 0 means DefaultSkillsPromptSearchTopN).
+	SearchTopN int
+
+	// SearchQuery optionally ranks the skills listed in search mode, e.g. by the latest user message: skills
+	// matching it come first, the rest follow in name order. With a runtime embedder (WithEmbedder) skills
+	// are ranked by semantic similarity, otherwise by lexical relevance.
+	SearchQuery string
+
+	// Format defaults to spec.SkillsPromptFormatText. spec.SkillsPromptFormatJSON and spec.SkillsPromptFormatXML
+	// render the same sections as a JSON object or XML elements.
+	Format spec.SkillsPromptFormat
 }
 
 // SkillListFilter is a HOST/LIFECYCLE listing filter.
@@ -60,6 +89,11 @@ type SkillListFilter struct {
 
 	Inserts []spec.SkillInsert
 
+	// Tags, TagMatch and ExcludeTags filter by SKILL.md tags as in SkillFilter.
+	Tags        []string
+	TagMatch    spec.TagMatch
+	ExcludeTags []string
+
 	SessionID spec.SessionID
 	Activity  spec.SkillActivity
 }
@@ -72,6 +106,8 @@ type SkillListFilter struct {
 //     <<<SKILLS_PROMPT>>>
 //     ...
 //     <<<END_SKILLS_PROMPT>>>
+//     (in the JSON format: one object with both members; in the XML format: a <skills_prompt> element).
+//   - Skill names, descriptions and bodies are escaped for the format, so they cannot break its structure.
 func (r *Runtime) SkillsPrompt(ctx context.Context, f *SkillFilter) (string, error) {
 	if ctx == nil {
 		return "", fmt.Errorf("%w: nil context", spec.ErrInvalidArgument)
@@ -96,6 +132,19 @@ func (r *Runtime) SkillsPrompt(ctx context.Context, f *SkillFilter) (string, err
 	default:
 		return "", fmt.Errorf("%w: invalid activity %q", spec.ErrInvalidArgument, cfg.Activity)
 	}
+	switch cfg.PromptMode {
+	case spec.SkillsPromptModeList, spec.SkillsPromptModeSearch:
+	default:
+		return "", fmt.Errorf("%w: invalid prompt mode %q", spec.ErrInvalidArgument, cfg.PromptMode)
+	}
+	switch cfg.Format {
+	case spec.SkillsPromptFormatText, spec.SkillsPromptFormatJSON, spec.SkillsPromptFormatXML:
+	default:
+		return "", fmt.Errorf("%w: invalid prompt format %q", spec.ErrInvalidArgument, cfg.Format)
+	}
+	if err := validateTagMatch(cfg.TagMatch); err != nil {
+		return "", err
+	}
 
 	// Base catalog filtering (Types/NamePrefix/LocationPrefix/AllowSkills).
 	records := r.catalog.ListPromptIndexRecords(toCatalogPromptFilter(&cfg))
@@ -103,11 +152,16 @@ func (r *Runtime) SkillsPrompt(ctx context.Context, f *SkillFilter) (string, err
 	// Resolve session + active set (optional).
 	var activeOrder []spec.ProviderSkillKey
 	activeSet := map[spec.ProviderSkillKey]struct{}{}
+	var versionPins map[string]string
 	if cfg.SessionID != "" {
 		s, ok := r.sessions.Get(string(cfg.SessionID))
 		if !ok {
 			return "", spec.ErrSessionNotFound
 		}
+		versionPins = s.Versions()
+		// Only advertise skills the session's policy permits.
+		records = slices.DeleteFunc(records, func(rec spec.ProviderSkillIndexRecord) bool { return !s.Allows(rec.Key) })
+
 		keys, err := s.ActiveKeys(ctx)
 		if err != nil {
 			return "", err
@@ -121,8 +175,9 @@ func (r *Runtime) SkillsPrompt(ctx context.Context, f *SkillFilter) (string, err
 	includeActive := cfg.Activity == spec.SkillActivityAny || cfg.Activity == spec.SkillActivityActive
 	includeAvailable := cfg.Activity == spec.SkillActivityAny || cfg.Activity == spec.SkillActivityInactive
 
+	var doc catalog.SkillsPromptDoc
+
 	// Active section: preserve session active order while respecting the filtered catalog view.
-	var activePrompt string
 
 	if includeActive && cfg.SessionID != "" {
 		// Build membership set for "records" (filtered catalog view), so active section
@@ -161,16 +216,20 @@ func (r *Runtime) SkillsPrompt(ctx context.Context, f *SkillFilter) (string, err
 			})
 		}
 
-		activePrompt = catalog.ActiveSkillsPrompt(items)
+		doc.Active = &catalog.ActiveSkillsSection{Items: items}
 	} else if cfg.Activity == spec.SkillActivityActive {
-		activePrompt = catalog.ActiveSkillsPrompt(nil)
+		doc.Active = &catalog.ActiveSkillsSection{}
 	}
 
 	// Available section: prompt-visible metadata only. With SessionID set, "available" means inactive.
-	var availablePrompt string
+	// Of a skill registered in several versions only the selected version is advertised.
 	if includeAvailable {
+		unselected := r.catalog.UnselectedVersions(versionPins)
 		items := make([]catalog.AvailableSkillItem, 0, len(records))
 		for _, rec := range records {
+			if _, ok := unselected[rec.Key]; ok {
+				continue
+			}
 			if cfg.SessionID != "" {
 				if _, isActive := activeSet[rec.Key]; isActive {
 					// With session + any/inactive, "available" means inactive.
@@ -185,24 +244,70 @@ func (r *Runtime) SkillsPrompt(ctx context.Context, f *SkillFilter) (string, err
 				Name:        h.Name,
 				Description: rec.Description,
 				Location:    h.Location,
+				Version:     rec.Version,
 				Resources:   rec.Resources,
 			})
 		}
 
-		availablePrompt = catalog.AvailableSkillsPrompt(items)
-
+		section := &catalog.AvailableSkillsSection{Items: items, Total: len(items)}
+		if cfg.PromptMode == spec.SkillsPromptModeSearch {
+			top, err := r.searchAvailableSkills(ctx, &cfg, items)
+			if err != nil {
+				return "", err
+			}
+			section.Items = top
+		} else {
+			slices.SortFunc(section.Items, func(a, b catalog.AvailableSkillItem) int {
+				return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.Location, b.Location))
+			})
+		}
+		doc.Available = section
 	}
 
-	// If only one section is requested, return it as the root (backward-compatible structure).
-	if strings.TrimSpace(activePrompt) == "" && strings.TrimSpace(availablePrompt) != "" {
-		return availablePrompt, nil
-	}
-	if strings.TrimSpace(availablePrompt) == "" && strings.TrimSpace(activePrompt) != "" {
-		return activePrompt, nil
-	}
+	// A single section is rendered as the root (backward-compatible structure); both are wrapped into one
+	// well-formed document.
+	return catalog.RenderSkillsPrompt(cfg.Format, doc)
+}
 
-	// Otherwise wrap both sections into one well-formed document.
-	return wrapSkillsPrompt(availablePrompt, activePrompt), nil
+// searchAvailableSkills returns the skills listed in search mode: the top SearchTopN of items, ranked by
+// SearchQuery, then by name.
+func (r *Runtime) searchAvailableSkills(
+	ctx context.Context,
+	cfg *SkillFilter,
+	items []catalog.AvailableSkillItem,
+) ([]catalog.AvailableSkillItem, error) {
+	rank := map[spec.SkillHandle]int{}
+	if cfg.SearchQuery != "" {
+		var hits []catalog.SearchHit
+		if r.catalog.HasEmbedder() {
+			var err error
+			if hits, err = r.catalog.Recommend(ctx, cfg.SearchQuery, toCatalogPromptFilter(cfg)); err != nil {
+				return nil, err
+			}
+		} else {
+			hits = r.catalog.Search(cfg.SearchQuery, toCatalogPromptFilter(cfg))
+		}
+		for i, hit := range hits {
+			if h, ok := r.catalog.HandleForKey(hit.Record.Key); ok {
+				rank[h] = i
+			}
+		}
+	}
+	sorted := slices.Clone(items)
+	slices.SortFunc(sorted, func(a, b catalog.AvailableSkillItem) int {
+		ra, aok := rank[spec.SkillHandle{Name: a.Name, Location: a.Location}]
+		rb, bok := rank[spec.SkillHandle{Name: b.Name, Location: b.Location}]
+		switch {
+		case aok && bok:
+			return cmp.Compare(ra, rb)
+		case aok:
+			return -1
+		case bok:
+			return 1
+		}
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.Location, b.Location))
+	})
+	return sorted[:min(cfg.SearchTopN, len(sorted))], nil
 }
 
 type RenderSkillParams struct {
@@ -290,7 +395,7 @@ func (r *Runtime) RenderSkill(ctx context.Context, p RenderSkillParams) (spec.Re
 
 func normalizeSkillListFilter(f *SkillListFilter) SkillListFilter {
 	if f == nil {
-		return SkillListFilter{Activity: spec.SkillActivityAny}
+		return SkillListFilter{Activity: spec.SkillActivityAny, TagMatch: spec.TagMatchAny}
 	}
 	types := make([]string, 0, len(f.Types))
 	seenT := map[string]struct{}{}
@@ -328,6 +433,9 @@ func normalizeSkillListFilter(f *SkillListFilter) SkillListFilter {
 		LocationPrefix: f.LocationPrefix,
 		AllowSkills:    allow,
 		Inserts:        inserts,
+		Tags:           normalizeTagFilter(f.Tags),
+		TagMatch:       normalizeTagMatch(f.TagMatch),
+		ExcludeTags:    normalizeTagFilter(f.ExcludeTags),
 		SessionID:      spec.SessionID(strings.TrimSpace(string(f.SessionID))),
 		Activity:       act,
 	}
@@ -343,12 +451,19 @@ func toCatalogUserFilter(f *SkillListFilter) catalog.UserFilter {
 		LocationPrefix: f.LocationPrefix,
 		AllowDefs:      append([]spec.SkillDef(nil), f.AllowSkills...),
 		Inserts:        append([]spec.SkillInsert(nil), f.Inserts...),
+		Tags:           toCatalogTagFilter(f.Tags, f.TagMatch, f.ExcludeTags),
 	}
 }
 
 func normalizeSkillsPromptFilter(f *SkillFilter) SkillFilter {
 	if f == nil {
-		return SkillFilter{Activity: spec.SkillActivityAny}
+		return SkillFilter{
+			Activity:   spec.SkillActivityAny,
+			TagMatch:   spec.TagMatchAny,
+			PromptMode: spec.SkillsPromptModeList,
+			SearchTopN: DefaultSkillsPromptSearchTopN,
+			Format:     spec.SkillsPromptFormatText,
+		}
 	}
 
 	types := make([]string, 0, len(f.Types))
@@ -383,14 +498,71 @@ func normalizeSkillsPromptFilter(f *SkillFilter) SkillFilter {
 	if act == "" {
 		act = spec.SkillActivityAny
 	}
+	mode := spec.SkillsPromptMode(strings.TrimSpace(string(f.PromptMode)))
+	if mode == "" {
+		mode = spec.SkillsPromptModeList
+	}
+	topN := f.SearchTopN
+	if topN <= 0 {
+		topN = DefaultSkillsPromptSearchTopN
+	}
+	format := spec.SkillsPromptFormat(strings.TrimSpace(string(f.Format)))
+	if format == "" {
+		format = spec.SkillsPromptFormatText
+	}
 
 	return SkillFilter{
 		Types:          types,
 		NamePrefix:     f.NamePrefix,
 		LocationPrefix: f.LocationPrefix,
 		AllowSkills:    allow,
+		Tags:           normalizeTagFilter(f.Tags),
+		TagMatch:       normalizeTagMatch(f.TagMatch),
+		ExcludeTags:    normalizeTagFilter(f.ExcludeTags),
 		SessionID:      spec.SessionID(strings.TrimSpace(string(f.SessionID))),
 		Activity:       act,
+		PromptMode:     mode,
+		SearchTopN:     topN,
+		SearchQuery:    strings.TrimSpace(f.SearchQuery),
+		Format:         format,
+	}
+}
+
+// normalizeTagFilter drops empty and duplicate tags. Tags are otherwise matched exactly.
+func normalizeTagFilter(in []string) []string {
+	out := make([]string, 0, len(in))
+	for _, t := range in {
+		t = strings.TrimSpace(t)
+		if t == "" || slices.Contains(out, t) {
+			continue
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+func normalizeTagMatch(m spec.TagMatch) spec.TagMatch {
+	m = spec.TagMatch(strings.TrimSpace(string(m)))
+	if m == "" {
+		return spec.TagMatchAny
+	}
+	return m
+}
+
+func validateTagMatch(m spec.TagMatch) error {
+	switch m {
+	case spec.TagMatchAny, spec.TagMatchAll:
+		return nil
+	default:
+		return fmt.Errorf("%w: invalid tag match %q", spec.ErrInvalidArgument, m)
+	}
+}
+
+func toCatalogTagFilter(include []string, match spec.TagMatch, exclude []string) catalog.TagFilter {
+	return catalog.TagFilter{
+		Include: append([]string(nil), include...),
+		All:     match == spec.TagMatchAll,
+		Exclude: append([]string(nil), exclude...),
 	}
 }
 
@@ -416,25 +588,6 @@ func cloneSkillResourceInfo(in spec.SkillResourceInfo) spec.SkillResourceInfo {
 	return in
 }
 
-func wrapSkillsPrompt(parts ...string) string {
-	var b strings.Builder
-	b.WriteString(skillsPromptStart)
-	wrote := false
-	for _, p := range parts {
-		if strings.TrimSpace(p) == "" {
-			continue
-		}
-		wrote = true
-		b.WriteByte('\n')
-		b.WriteString(p)
-	}
-	if wrote {
-		b.WriteByte('\n')
-	}
-	b.WriteString(skillsPromptEnd)
-	return b.String()
-}
-
 func toCatalogPromptFilter(f *SkillFilter) catalog.PromptFilter {
 	if f == nil {
 		return catalog.PromptFilter{}
@@ -449,5 +602,6 @@ func toCatalogPromptFilter(f *SkillFilter) catalog.PromptFilter {
 		LocationPrefix: f.LocationPrefix,
 		AllowDefs:      append([]spec.SkillDef(nil), f.AllowSkills...),
 		Inserts:        inserts,
+		Tags:           toCatalogTagFilter(f.Tags, f.TagMatch, f.ExcludeTags),
 	}
 }
@@ -0,0 +1,33 @@
+package spec
+
+import "context"
+
+// TokenCounter counts the tokens of a skill body for session token budgets.
+//
+// Implementations typically wrap the tokenizer of the model the host talks to. CountTokens must be safe
+// for concurrent use.
+type TokenCounter interface {
+	CountTokens(ctx context.Context, text string) (int, error)
+}
+
+// TokenCounterFunc adapts a function to TokenCounter.
+type TokenCounterFunc func(ctx context.Context, text string) (int, error)
+
+func (f TokenCounterFunc) CountTokens(ctx context.Context, text string) (int, error) {
+	return f(ctx, text)
+}
+
+// ActiveSkillsUsage reports how much of a session's active skills budget is in use.
+//
+// Bytes and Tokens measure the SKILL.md bodies of the active skills. Max* fields are zero when the
+// corresponding limit is not set; Tokens is only counted when a token budget is set.
+type ActiveSkillsUsage struct {
+	Skills    int `json:"skills"`
+	MaxSkills int `json:"maxSkills,omitempty"`
+
+	Bytes    int `json:"bytes"`
+	MaxBytes int `json:"maxBytes,omitempty"`
+
+	Tokens    int `json:"tokens,omitempty"`
+	MaxTokens int `json:"maxTokens,omitempty"`
+}
@@ -3,17 +3,22 @@ package spec
 const (
 	skillsRulesCommon = `
 Rules:
-1) Only use skills that are listed in the provided skills prompt.
+1) Only use skills that are listed in the provided skills prompt or returned by skills-search.
 2) Prefer reading advertised skill resource locations with skills-readresource before running scripts.
 3) After calling skills-load or skills-unload, rely on the updated skills context in subsequent turns.`
 
 	skillsToolsBase = `You have access to "skills" tools:
 - skills-load
 - skills-unload
-- skills-readresource`
+- skills-readresource
+- skills-search`
 
 	skillsToolsLoadOnly = `You have access to tool "skills-load". After you load at least one skill, more skills tools may be available.`
 
+	skillsToolsSearch = `You have access to tools "skills-search" and "skills-load". ` +
+		`The skills prompt lists only a few skills; call skills-search with keywords describing the task ` +
+		`to find others. After you load at least one skill, more skills tools may be available.`
+
 	skillsToolsAllWithRunScript = skillsToolsBase + "\n" + "- skills-runscript"
 )
 
@@ -24,6 +29,9 @@ const (
 
 	SkillsRulesPromptAll = skillsToolsAllWithRunScript + "\n" + skillsRulesCommon
 
+	// SkillsRulesPromptSearch pairs with SkillsPrompt in search mode (spec.SkillsPromptModeSearch).
+	SkillsRulesPromptSearch = skillsToolsSearch + "\n" + skillsRulesCommon
+
 	toolTagSkills  = "skills"
 	toolVersionOne = "v1.0.0"
 )
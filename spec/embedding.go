@@ -0,0 +1,26 @@
+package spec
+
+import "context"
+
+// Embedder maps texts to embedding vectors for semantic skill discovery (Runtime.RecommendSkills).
+//
+// Embed returns one vector per text, in order; all vectors must have the same dimension. Implementations
+// typically wrap a local or hosted embedding model. Embed must be safe for concurrent use.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// EmbedderFunc adapts a function to Embedder.
+type EmbedderFunc func(ctx context.Context, texts []string) ([][]float32, error)
+
+func (f EmbedderFunc) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return f(ctx, texts)
+}
+
+// SkillRecommendation is a skill returned by Runtime.RecommendSkills.
+type SkillRecommendation struct {
+	Record SkillRecord `json:"record"`
+
+	// Score is the cosine similarity between the query and the skill's name, description and tags.
+	Score float64 `json:"score"`
+}
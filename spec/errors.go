@@ -1,6 +1,10 @@
 package spec
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 // Package-level sentinel errors returned by catalog/session/provider operations.
 var (
@@ -27,4 +31,43 @@ var (
 
 	// ErrSkillNotAllowed indicates the requested skill is not permitted by the session allowlist.
 	ErrSkillNotAllowed = errors.New("skill not allowed")
+
+	// ErrSkillBudgetExceeded indicates an activation would exceed the session's active skills byte/token budget.
+	ErrSkillBudgetExceeded = errors.New("skill budget exceeded")
+
+	// ErrSkillDependencyCycle indicates the "requires" dependencies of a skill form a cycle.
+	ErrSkillDependencyCycle = errors.New("skill dependency cycle")
+
+	// ErrSkillConflict indicates an activation would leave skills that declare a conflict active together.
+	// The returned error is a *SkillConflictError.
+	ErrSkillConflict = errors.New("skill conflict")
+
+	// ErrSkillStale indicates the skill's source changed since it was indexed; RefreshSkill re-indexes it.
+	ErrSkillStale = errors.New("skill changed since indexing")
 )
+
+// SkillConflictError reports the active skills an activated skill conflicts with (SKILL.md "conflicts").
+// It matches ErrSkillConflict with errors.Is.
+type SkillConflictError struct {
+	// Skill is the skill being activated.
+	Skill SkillHandle `json:"skill"`
+
+	// Conflicts are the skills that conflict with Skill and could not be unloaded in its favor.
+	Conflicts []SkillHandle `json:"conflicts"`
+}
+
+func (e *SkillConflictError) Error() string {
+	names := make([]string, 0, len(e.Conflicts))
+	for _, h := range e.Conflicts {
+		names = append(names, fmt.Sprintf("%s (%s)", h.Name, h.Location))
+	}
+	return fmt.Sprintf(
+		"%s: skill %s (%s) conflicts with %s",
+		ErrSkillConflict,
+		e.Skill.Name,
+		e.Skill.Location,
+		strings.Join(names, ", "),
+	)
+}
+
+func (e *SkillConflictError) Unwrap() error { return ErrSkillConflict }
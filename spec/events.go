@@ -0,0 +1,77 @@
+package spec
+
+import "time"
+
+// EventType identifies a runtime event delivered to Runtime.Subscribe callbacks.
+type EventType string
+
+const (
+	// EventTypeSessionCreated is emitted when a session is created (NewSession/ImportSession), once its initial
+	// skills are active. ActiveSkills is empty; initial skills are reported by a following skills-activated
+	// event. A session whose initial activation fails publishes no events.
+	EventTypeSessionCreated EventType = "session.created"
+
+	// EventTypeSessionClosed is emitted when a session is closed by the host.
+	EventTypeSessionClosed EventType = "session.closed"
+
+	// EventTypeSessionEvicted is emitted when the session store evicts a session; see EvictionReason.
+	EventTypeSessionEvicted EventType = "session.evicted"
+
+	// EventTypeSkillsActivated is emitted when skills become active in a session (host or skills-load).
+	EventTypeSkillsActivated EventType = "session.skills.activated"
+
+	// EventTypeSkillsDeactivated is emitted when skills stop being active in a session (skills-unload, or
+	// replaced by a load with mode=replace).
+	EventTypeSkillsDeactivated EventType = "session.skills.deactivated"
+
+	// EventTypeSkillsPruned is emitted per session when skills were dropped because they were removed
+	// from the catalog (RemoveSkill, or a refresh that found the skill missing). With a file session store,
+	// it is only emitted for sessions the process has loaded; other sessions are pruned when loaded.
+	EventTypeSkillsPruned EventType = "session.skills.pruned"
+
+	// EventTypeSkillAdded is emitted when a skill is added to the catalog.
+	EventTypeSkillAdded EventType = "catalog.skill.added"
+
+	// EventTypeSkillRemoved is emitted when the host removes a skill from the catalog.
+	EventTypeSkillRemoved EventType = "catalog.skill.removed"
+
+	// EventTypeSkillRefreshed is emitted for every refreshed skill; see RefreshStatus.
+	EventTypeSkillRefreshed EventType = "catalog.skill.refreshed"
+)
+
+// SessionEvictionReason explains why the session store evicted a session.
+type SessionEvictionReason string
+
+const (
+	// SessionEvictionReasonTTL means the session was unused for longer than the session TTL.
+	SessionEvictionReasonTTL SessionEvictionReason = "ttl"
+
+	// SessionEvictionReasonLRU means the session was the least recently used one over MaxSessions.
+	SessionEvictionReasonLRU SessionEvictionReason = "lru"
+)
+
+// Event is a runtime notification about session or catalog changes.
+//
+// Events carry only host/lifecycle skill definitions, never provider-canonical keys or LLM handles.
+type Event struct {
+	Type EventType `json:"type"`
+	Time time.Time `json:"time"`
+
+	// SessionID is set for session events.
+	SessionID SessionID `json:"sessionID,omitempty"`
+
+	// EvictionReason is set for session.evicted events.
+	EvictionReason SessionEvictionReason `json:"evictionReason,omitempty"`
+
+	// Skills are the skills the event is about: the activated/deactivated/pruned skills of a session event,
+	// or the added/removed/refreshed skill of a catalog event.
+	Skills []SkillDef `json:"skills,omitempty"`
+
+	// PreviousActiveSkills and ActiveSkills are the session's active skills (activation order) before and
+	// after an activation change. They are set for skills activated/deactivated events.
+	PreviousActiveSkills []SkillDef `json:"previousActiveSkills,omitempty"`
+	ActiveSkills         []SkillDef `json:"activeSkills,omitempty"`
+
+	// RefreshStatus is set for catalog.skill.refreshed events.
+	RefreshStatus SkillRefreshStatus `json:"refreshStatus,omitempty"`
+}
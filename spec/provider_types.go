@@ -12,6 +12,10 @@ type LoadMode string
 const (
 	LoadModeReplace LoadMode = "replace"
 	LoadModeAdd     LoadMode = "add"
+
+	// LoadModeReplaceConflicting adds to the active list like LoadModeAdd, but unloads active skills that
+	// conflict with the loaded ones instead of failing with a *SkillConflictError.
+	LoadModeReplaceConflicting LoadMode = "replace-conflicting"
 )
 
 type LoadArgs struct {
@@ -21,15 +25,74 @@ type LoadArgs struct {
 
 type LoadOut struct {
 	ActiveSkills []SkillHandle `json:"activeSkills"`
+
+	// PinnedSkills are the active skills pinned by the host; they cannot be unloaded or replaced.
+	PinnedSkills []SkillHandle `json:"pinnedSkills,omitempty"`
+
+	// EvictedSkills are the skills unloaded automatically by the session's eviction policy to make room.
+	EvictedSkills []SkillHandle `json:"evictedSkills,omitempty"`
+
+	// DependencySkills are the skills activated implicitly because a requested skill requires them.
+	DependencySkills []SkillHandle `json:"dependencySkills,omitempty"`
+
+	// ReplacedSkills are the conflicting skills unloaded by mode=replace-conflicting, followed by the implicitly
+	// activated dependencies unloaded with them.
+	ReplacedSkills []SkillHandle `json:"replacedSkills,omitempty"`
+
+	// Usage is the session's active skills budget usage; set when the session has a byte or token budget.
+	Usage *ActiveSkillsUsage `json:"usage,omitempty"`
 }
 
 type UnloadArgs struct {
 	Skills []SkillHandle `json:"skills,omitempty"`
 	All    bool          `json:"all,omitempty"`
+
+	// Cascade also unloads skills that were only activated as dependencies and are no longer required.
+	Cascade bool `json:"cascade,omitempty"`
 }
 
 type UnloadOut struct {
 	ActiveSkills []SkillHandle `json:"activeSkills"`
+
+	// PinnedSkills are the active skills pinned by the host; they stay active even if unload requested them.
+	PinnedSkills []SkillHandle `json:"pinnedSkills,omitempty"`
+
+	// Usage is the session's active skills budget usage; set when the session has a byte or token budget.
+	Usage *ActiveSkillsUsage `json:"usage,omitempty"`
+}
+
+// Limits of skills-search results.
+const (
+	DefaultSearchLimit = 10
+	MaxSearchLimit     = 50
+)
+
+type SearchArgs struct {
+	// Query is matched against skill names, tags and descriptions.
+	Query string `json:"query"`
+
+	// Limit is the maximum number of results (default DefaultSearchLimit, at most MaxSearchLimit).
+	Limit int `json:"limit,omitempty"`
+}
+
+type SearchOut struct {
+	// Skills are the matching skills, most relevant first.
+	Skills []SkillSearchResult `json:"skills"`
+}
+
+// SkillSearchResult is one skills-search result. Name and Location form the handle to pass to skills-load.
+type SkillSearchResult struct {
+	Name        string   `json:"name"`
+	Location    string   `json:"location"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Version     string   `json:"version,omitempty"`
+
+	// Active is true if the skill is already active in the session.
+	Active bool `json:"active,omitempty"`
+
+	// Score is the relevance score; only the order of scores within one result is meaningful.
+	Score float64 `json:"score"`
 }
 
 type ReadResourceEncoding string
@@ -95,10 +158,20 @@ type ProviderSkillIndexRecord struct {
 	// Missing/empty defaults to "instructions".
 	Insert SkillInsert `json:"insert"`
 
+	// Version is parsed from SKILL.md frontmatter field "version": a semantic version, or empty.
+	Version string `json:"version,omitempty"`
+
 	Arguments []SkillArgument `json:"arguments,omitempty"`
 
 	Tags []string `json:"tags,omitempty"`
 
+	// Requires is parsed from SKILL.md frontmatter field "requires": names of skills this skill depends on.
+	Requires []string `json:"requires,omitempty"`
+
+	// Conflicts is parsed from SKILL.md frontmatter field "conflicts": names of skills this skill must not be
+	// active with.
+	Conflicts []string `json:"conflicts,omitempty"`
+
 	Resources SkillResourceInfo `json:"resources"`
 
 	RawFrontmatter map[string]any `json:"rawFrontmatter,omitempty"`
@@ -140,3 +213,34 @@ type SkillProvider interface {
 		workDir string,
 	) (RunScriptOut, error)
 }
+
+// SkillDiscoveryOptions controls how a SkillDiscoverer enumerates candidate skill locations.
+type SkillDiscoveryOptions struct {
+	// MaxDepth is the maximum depth below the root that is searched. Direct children are depth 1.
+	// Values <= 0 default to 1.
+	MaxDepth int `json:"maxDepth,omitempty"`
+
+	// Ignore holds glob patterns (path.Match syntax) matched against both the slash-separated path
+	// relative to the root and the base name. Matching locations are skipped and not descended into.
+	Ignore []string `json:"ignore,omitempty"`
+
+	// IncludeHidden includes locations whose base name starts with ".". Default is false.
+	IncludeHidden bool `json:"includeHidden,omitempty"`
+}
+
+// SkillDiscoveryCandidate is a candidate skill location returned by a SkillDiscoverer.
+type SkillDiscoveryCandidate struct {
+	// Def is the host/lifecycle definition to register. Location is derived from the user-provided
+	// root location (not canonicalized).
+	Def SkillDef `json:"def"`
+
+	// SkipReason is non-empty when the location looks like a skill but must not be registered
+	// (e.g. hidden or ignored).
+	SkipReason string `json:"skipReason,omitempty"`
+}
+
+// SkillDiscoverer is an optional SkillProvider extension that enumerates candidate skills
+// below a provider-interpreted root location.
+type SkillDiscoverer interface {
+	Discover(ctx context.Context, rootLocation string, opts SkillDiscoveryOptions) ([]SkillDiscoveryCandidate, error)
+}
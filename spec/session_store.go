@@ -0,0 +1,82 @@
+package spec
+
+import (
+	"context"
+	"time"
+)
+
+// SessionState is the serializable state of a runtime session, as persisted by a SessionStateStore.
+//
+// Active skills are identified by provider skill keys, so runtimes sharing a store must register the same
+// skills. Stores treat a state as an opaque value apart from its ID and Version.
+type SessionState struct {
+	ID        SessionID `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	MaxActive int       `json:"maxActive,omitempty"`
+
+	// Version increases with every change of the session; SessionStateStore.Update compares it.
+	Version uint64              `json:"version"`
+	Active  []SessionStateSkill `json:"active,omitempty"`
+
+	Policy   *SessionSkillPolicy  `json:"policy,omitempty"`
+	Eviction *SkillEvictionPolicy `json:"eviction,omitempty"`
+	Versions map[string]string    `json:"versions,omitempty"`
+
+	MaxActiveBytes  int `json:"maxActiveBytes,omitempty"`
+	MaxActiveTokens int `json:"maxActiveTokens,omitempty"`
+}
+
+// SessionStateSkill is an active skill of a SessionState: its key together with the provider digest
+// recorded when it was activated.
+type SessionStateSkill struct {
+	Key    ProviderSkillKey `json:"key"`
+	Digest string           `json:"digest,omitempty"`
+	Pinned bool             `json:"pinned,omitempty"`
+
+	// Implicit is set for skills activated only because an active skill requires them.
+	Implicit bool `json:"implicit,omitempty"`
+
+	// LastUsed is when the skill was last activated or used by skills-readresource/skills-runscript. It is
+	// only tracked for sessions with an eviction policy.
+	LastUsed time.Time `json:"lastUsed,omitzero"`
+}
+
+// SessionStateInfo describes a stored session in SessionStateStore.List.
+type SessionStateInfo struct {
+	ID        SessionID `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	LastUsed  time.Time `json:"lastUsed"`
+}
+
+// SessionStateStore persists runtime sessions, so they survive restarts and can be shared by runtimes in
+// several processes (see agentskills.WithSessionStore).
+//
+// The runtime caches sessions in memory and keeps the store authoritative: it loads a session's state on
+// lookup (reloading it when its Version changed), writes every change through Update, and applies TTL and
+// MaxSessions eviction using the last-used times the store keeps per session. Implementations must be safe
+// for concurrent use, including by other processes if the store is shared.
+type SessionStateStore interface {
+	// Create stores the state of a new session with the given last-used time. It reports false (and no
+	// error) if a session with the same ID exists.
+	Create(ctx context.Context, state SessionState, lastUsed time.Time) (bool, error)
+
+	// Load returns the state of a session and its last-used time. It fails with an error matching
+	// ErrSessionNotFound if there is no such session.
+	Load(ctx context.Context, id SessionID) (SessionState, time.Time, error)
+
+	// Update atomically replaces the state of a session if its stored Version is prevVersion, keeping its
+	// last-used time. It reports false (and no error) if the stored version differs, and fails with an error
+	// matching ErrSessionNotFound if there is no such session.
+	Update(ctx context.Context, state SessionState, prevVersion uint64) (bool, error)
+
+	// Touch sets the last-used time of a session. Sessions that do not exist are ignored.
+	Touch(ctx context.Context, id SessionID, lastUsed time.Time) error
+
+	// Delete removes a session and reports whether it existed. With a non-zero unusedSince, the session is
+	// only removed if it was not used after unusedSince, so eviction never removes a session that another
+	// runtime used meanwhile.
+	Delete(ctx context.Context, id SessionID, unusedSince time.Time) (bool, error)
+
+	// List returns all stored sessions, in any order.
+	List(ctx context.Context) ([]SessionStateInfo, error)
+}
@@ -22,6 +22,10 @@ func TestSkillRulesPrompts(t *testing.T) {
 		!strings.Contains(SkillsRulesPromptAll, "skills-load") {
 		t.Fatalf("unexpected all prompt: %q", SkillsRulesPromptAll)
 	}
+	if !strings.Contains(SkillsRulesPromptSearch, "skills-search") ||
+		!strings.Contains(SkillsRulesPromptSearch, "skills-load") {
+		t.Fatalf("unexpected search prompt: %q", SkillsRulesPromptSearch)
+	}
 }
 
 func TestSkillToolConstructors(t *testing.T) {
@@ -55,6 +59,13 @@ func TestSkillToolConstructors(t *testing.T) {
 			wantID:   FuncIDSkillsRunScript,
 			wantTags: []string{toolTagSkills, "exec"},
 		},
+		{
+			name:     "search",
+			got:      SkillsSearchTool(),
+			wantSlug: "skills-search",
+			wantID:   FuncIDSkillsSearch,
+			wantTags: []string{toolTagSkills},
+		},
 		{
 			name:     "unload",
 			got:      SkillsUnloadTool(),
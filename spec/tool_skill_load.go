@@ -32,9 +32,9 @@ func SkillsLoadTool() llmtoolsgoSpec.Tool {
 	},
 	"mode":{
 		"type":"string",
-		"enum":["replace","add"],
+		"enum":["replace","add","replace-conflicting"],
 		"default":"replace",
-		"description":"replace: replace the active skill set; add: add to the active skill set."
+		"description":"replace: replace active skills, pinned stay; add: add; replace-conflicting: add, drop conflicts."
 	}
 },
 "required":["skills"],
@@ -0,0 +1,40 @@
+package spec
+
+import llmtoolsgoSpec "github.com/flexigpt/llmtools-go/spec"
+
+const FuncIDSkillsSearch llmtoolsgoSpec.FuncID = "github.com/flexigpt/agentskills-go/skills-search"
+
+func SkillsSearchTool() llmtoolsgoSpec.Tool {
+	return llmtoolsgoSpec.Tool{
+		SchemaVersion: llmtoolsgoSpec.SchemaVersion,
+		ID:            "019c4189-3a52-7c1e-8f6d-2b9e71d4a0c5",
+		Slug:          "skills-search",
+		Version:       toolVersionOne,
+		DisplayName:   "Skills Search",
+		Description:   "search available skills by name, tags and description; returns handles for skills-load",
+		Tags:          []string{toolTagSkills},
+		ArgSchema: llmtoolsgoSpec.JSONSchema(`{
+"$schema":"http://json-schema.org/draft-07/schema#",
+"type":"object",
+"properties":{
+	"query":{
+		"type":"string",
+		"minLength":1,
+		"description":"keywords describing the task or the skill to find"
+	},
+	"limit":{
+		"type":"integer",
+		"minimum":1,
+		"maximum":50,
+		"default":10,
+		"description":"maximum number of results"
+	}
+},
+"required":["query"],
+"additionalProperties":false
+}`),
+		GoImpl:     llmtoolsgoSpec.GoToolImpl{FuncID: FuncIDSkillsSearch},
+		CreatedAt:  llmtoolsgoSpec.SchemaStartTime,
+		ModifiedAt: llmtoolsgoSpec.SchemaStartTime,
+	}
+}
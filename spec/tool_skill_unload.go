@@ -32,7 +32,12 @@ func SkillsUnloadTool() llmtoolsgoSpec.Tool {
 	"all":{
 		"type":"boolean",
 		"default":false,
-		"description":"if true, unload all active skills"
+		"description":"if true, unload all active skills except pinned ones"
+	},
+	"cascade":{
+		"type":"boolean",
+		"default":false,
+		"description":"if true, also unload skills that were loaded only as dependencies and are no longer required"
 	}
 },
 "additionalProperties":false
@@ -1,5 +1,7 @@
 package spec
 
+import "time"
+
 // SessionID identifies a runtime session (UUIDv7 string).
 type SessionID string
 
@@ -24,6 +26,60 @@ const (
 	SkillActivityInactive SkillActivity = "inactive"
 )
 
+// TagMatch controls how a skill filter's Tags match a skill's tags.
+type TagMatch string
+
+const (
+	// TagMatchAny matches skills with at least one of the tags. It is the default.
+	TagMatchAny TagMatch = "any"
+
+	// TagMatchAll matches skills with all of the tags.
+	TagMatchAll TagMatch = "all"
+)
+
+// SkillSortKey selects the order of a paged skill listing. Ties are broken by name, location and type.
+type SkillSortKey string
+
+const (
+	// SkillSortByName sorts by skill definition name. It is the default.
+	SkillSortByName SkillSortKey = "name"
+
+	// SkillSortByLocation sorts by skill definition location.
+	SkillSortByLocation SkillSortKey = "location"
+
+	// SkillSortByType sorts by skill definition (provider) type.
+	SkillSortByType SkillSortKey = "type"
+
+	// SkillSortByAddedAt sorts by registration time, oldest first.
+	SkillSortByAddedAt SkillSortKey = "addedAt"
+)
+
+// SkillsPromptMode controls how SkillsPrompt lists available skills.
+type SkillsPromptMode string
+
+const (
+	// SkillsPromptModeList lists every available skill. It is the default.
+	SkillsPromptModeList SkillsPromptMode = "list"
+
+	// SkillsPromptModeSearch lists only the top N available skills and tells the model how many more the
+	// skills-search tool can find. Pair it with SkillsRulesPromptSearch for large catalogs.
+	SkillsPromptModeSearch SkillsPromptMode = "search"
+)
+
+// SkillsPromptFormat selects how SkillsPrompt renders its sections.
+type SkillsPromptFormat string
+
+const (
+	// SkillsPromptFormatText renders <<<AVAILABLE_SKILLS>>>-style delimited text. It is the default.
+	SkillsPromptFormatText SkillsPromptFormat = "text"
+
+	// SkillsPromptFormatJSON renders a JSON object with "available_skills" and/or "active_skills" members.
+	SkillsPromptFormatJSON SkillsPromptFormat = "json"
+
+	// SkillsPromptFormatXML renders <available_skills> and/or <active_skills> elements of <skill> entries.
+	SkillsPromptFormatXML SkillsPromptFormat = "xml"
+)
+
 // SkillHandle is the LLM-facing selector for a skill.
 //
 // IMPORTANT CONTRACT:
@@ -92,8 +148,11 @@ type SkillDocument struct {
 	DisplayName  string          `json:"displayName,omitempty"`
 	Description  string          `json:"description"`
 	Insert       SkillInsert     `json:"insert"`
+	Version      string          `json:"version,omitempty"`
 	Arguments    []SkillArgument `json:"arguments,omitempty"`
 	Tags         []string        `json:"tags,omitempty"`
+	Requires     []string        `json:"requires,omitempty"`
+	Conflicts    []string        `json:"conflicts,omitempty"`
 	MarkdownBody string          `json:"markdownBody"`
 
 	RawFrontmatter map[string]any `json:"rawFrontmatter,omitempty"`
@@ -160,10 +219,22 @@ type SkillRecord struct {
 	// Defaults to "instructions".
 	Insert SkillInsert `json:"insert"`
 
+	// Version is the semantic version parsed from SKILL.md "version" (empty if not declared). Several
+	// versions of a skill name may be registered; see Runtime.NewSession and WithSkillVersions.
+	Version string `json:"version,omitempty"`
+
 	Arguments []SkillArgument `json:"arguments,omitempty"`
 
 	Tags []string `json:"tags,omitempty"`
 
+	// Requires are the names of skills this skill depends on (SKILL.md "requires"). Activating the skill
+	// in a session activates them too.
+	Requires []string `json:"requires,omitempty"`
+
+	// Conflicts are the names of skills that must not be active together with this skill (SKILL.md
+	// "conflicts"). Conflicts are symmetric: either skill may declare them.
+	Conflicts []string `json:"conflicts,omitempty"`
+
 	Resources SkillResourceInfo `json:"resources"`
 
 	// RawFrontmatter preserves the parsed SKILL.md YAML frontmatter for callers that want
@@ -173,4 +244,224 @@ type SkillRecord struct {
 	Warnings []string `json:"warnings,omitempty"`
 
 	Digest string `json:"digest,omitempty"`
+
+	// AddedAt is when the skill was registered in the runtime catalog. Refreshes keep it.
+	AddedAt time.Time `json:"addedAt,omitzero"`
+}
+
+// SkillRefreshStatus describes the outcome of re-indexing a single catalog skill.
+type SkillRefreshStatus string
+
+const (
+	// SkillRefreshStatusUnchanged means the provider returned the same digest; the catalog entry was kept as is.
+	SkillRefreshStatusUnchanged SkillRefreshStatus = "unchanged"
+
+	// SkillRefreshStatusUpdated means the index record was swapped and the cached body invalidated.
+	SkillRefreshStatusUpdated SkillRefreshStatus = "updated"
+
+	// SkillRefreshStatusFailed means re-indexing failed; the previous catalog entry is kept.
+	SkillRefreshStatusFailed SkillRefreshStatus = "failed"
+
+	// SkillRefreshStatusRemoved means the provider reported the skill as missing and it was removed
+	// from the catalog (and pruned from all sessions).
+	SkillRefreshStatusRemoved SkillRefreshStatus = "removed"
+)
+
+// SkillRefreshResult is the per-skill report returned by Runtime.RefreshSkill and Runtime.RefreshAll.
+type SkillRefreshResult struct {
+	// Def is the exact host/lifecycle definition that was refreshed.
+	Def SkillDef `json:"def"`
+
+	Status SkillRefreshStatus `json:"status"`
+
+	// Record is the catalog record after the refresh. For failed/removed results it is the previous record.
+	Record SkillRecord `json:"record"`
+
+	// PreviousDigest is the digest held by the catalog before the refresh.
+	PreviousDigest string `json:"previousDigest,omitempty"`
+
+	// Error is set for failed results (and for removed results, with the provider error that caused removal).
+	Error string `json:"error,omitempty"`
+}
+
+// SessionSkillPolicy restricts which skills a session may activate and which skills SkillsPrompt
+// advertises for the session.
+//
+// Semantics:
+//   - Each empty Allow* list means "all"; a skill must match every non-empty Allow* list.
+//   - A skill matching any Deny* entry is rejected; deny wins over allow.
+//   - Tags match the skill's SKILL.md tags exactly (a skill matches a tag list if it has any of the tags).
+//   - Types match the provider type of the skill definition.
+type SessionSkillPolicy struct {
+	AllowSkills []SkillDef `json:"allowSkills,omitempty"`
+	DenySkills  []SkillDef `json:"denySkills,omitempty"`
+
+	AllowTags []string `json:"allowTags,omitempty"`
+	DenyTags  []string `json:"denyTags,omitempty"`
+
+	AllowTypes []string `json:"allowTypes,omitempty"`
+	DenyTypes  []string `json:"denyTypes,omitempty"`
+}
+
+// SkillEvictionMode selects which active skills a session unloads first to make room for skills being
+// activated.
+type SkillEvictionMode string
+
+const (
+	// SkillEvictionModeLRU evicts the least recently used skill first. Activation, skills-readresource and
+	// skills-runscript count as use.
+	SkillEvictionModeLRU SkillEvictionMode = "lru"
+
+	// SkillEvictionModeOldest evicts the earliest activated skill first.
+	SkillEvictionModeOldest SkillEvictionMode = "oldest"
+
+	// SkillEvictionModeLowestPriority evicts the skill with the lowest priority first; ties are broken by
+	// least recent use.
+	SkillEvictionModeLowestPriority SkillEvictionMode = "lowest-priority"
+)
+
+// SkillEvictionPolicy is a session's opt-in automatic eviction policy.
+//
+// Without a policy, an activation that would exceed the session's max active skills limit (or body budget)
+// fails. With a policy, other active skills are unloaded in eviction order until the activation fits;
+// pinned skills, the skills being activated, and skills a remaining skill requires are never evicted. An
+// evicted skill takes its implicitly activated dependencies with it.
+type SkillEvictionPolicy struct {
+	Mode SkillEvictionMode `json:"mode"`
+
+	// Priorities are used by SkillEvictionModeLowestPriority; skills not listed have priority 0.
+	Priorities []SkillPriority `json:"priorities,omitempty"`
+}
+
+// SkillPriority assigns an eviction priority to a skill definition (higher is kept longer).
+type SkillPriority struct {
+	Def      SkillDef `json:"def"`
+	Priority int      `json:"priority"`
+}
+
+// SessionSnapshot is a serializable copy of a session's state returned by Runtime.ExportSession
+// and accepted by Runtime.ImportSession.
+type SessionSnapshot struct {
+	// SessionID is the exported session's ID. ImportSession reuses it unless it is empty.
+	SessionID SessionID `json:"sessionID,omitempty"`
+
+	// MaxActivePerSession is the session's max active skills limit (<= 0 uses the runtime default).
+	MaxActivePerSession int `json:"maxActivePerSession,omitempty"`
+
+	// MaxActiveBytes and MaxActiveTokens are the session's active skills body budgets (<= 0 uses the
+	// runtime default).
+	MaxActiveBytes  int `json:"maxActiveBytes,omitempty"`
+	MaxActiveTokens int `json:"maxActiveTokens,omitempty"`
+
+	// ActiveSkills are the active skills in activation order.
+	ActiveSkills []SessionSkillSnapshot `json:"activeSkills,omitempty"`
+
+	// Policy is the session's skill policy, if any.
+	Policy *SessionSkillPolicy `json:"policy,omitempty"`
+
+	// Eviction is the session's automatic eviction policy, if any.
+	Eviction *SkillEvictionPolicy `json:"eviction,omitempty"`
+
+	// SkillVersions are the session's version pins (skill name -> semantic version), if any.
+	SkillVersions map[string]string `json:"skillVersions,omitempty"`
+}
+
+// SessionSkillSnapshot is one active skill of a SessionSnapshot.
+type SessionSkillSnapshot struct {
+	// Def is the exact host/lifecycle definition the skill was registered with.
+	Def SkillDef `json:"def"`
+
+	// Digest is the provider digest the skill had when it was activated in the session.
+	Digest string `json:"digest,omitempty"`
+
+	// Pinned is true for skills pinned by the host.
+	Pinned bool `json:"pinned,omitempty"`
+
+	// Implicit is true for skills activated only because another active skill requires them. On import
+	// they are restored through the "requires" of their dependents rather than activated directly.
+	Implicit bool `json:"implicit,omitempty"`
+}
+
+// SessionImportResult is returned by Runtime.ImportSession.
+type SessionImportResult struct {
+	SessionID SessionID `json:"sessionID"`
+
+	// ActiveSkills are the restored active skills in activation order.
+	ActiveSkills []SkillDef `json:"activeSkills,omitempty"`
+
+	// MissingSkills are snapshot skills that are no longer registered; they were not restored.
+	MissingSkills []SkillDef `json:"missingSkills,omitempty"`
+
+	// ChangedSkills are restored skills whose current digest differs from the snapshot digest.
+	ChangedSkills []SessionSkillChange `json:"changedSkills,omitempty"`
+}
+
+// SessionSkillChange reports a restored skill whose content changed since the snapshot was taken.
+type SessionSkillChange struct {
+	Def SkillDef `json:"def"`
+
+	SnapshotDigest string `json:"snapshotDigest,omitempty"`
+	CurrentDigest  string `json:"currentDigest,omitempty"`
+}
+
+// SessionInfo describes a live session; returned by Runtime.ListSessions and Runtime.GetSession.
+type SessionInfo struct {
+	SessionID SessionID `json:"sessionID"`
+
+	CreatedAt  time.Time `json:"createdAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+
+	// TTLRemaining is how long the session stays alive without being used, as of the call.
+	TTLRemaining time.Duration `json:"ttlRemaining"`
+
+	// MaxActivePerSession is the session's max active skills limit.
+	MaxActivePerSession int `json:"maxActivePerSession"`
+
+	// MaxActiveBytes and MaxActiveTokens are the session's active skills body budgets (0 means unlimited).
+	MaxActiveBytes  int `json:"maxActiveBytes,omitempty"`
+	MaxActiveTokens int `json:"maxActiveTokens,omitempty"`
+
+	// Policy is the session's skill policy, if any.
+	Policy *SessionSkillPolicy `json:"policy,omitempty"`
+
+	// Eviction is the session's automatic eviction policy, if any.
+	Eviction *SkillEvictionPolicy `json:"eviction,omitempty"`
+
+	// SkillVersions are the session's version pins (skill name -> semantic version), if any.
+	SkillVersions map[string]string `json:"skillVersions,omitempty"`
+
+	// ActiveSkills are the active skills in activation order.
+	ActiveSkills []SkillDef `json:"activeSkills,omitempty"`
+
+	// PinnedSkills are the pinned subset of ActiveSkills (activation order).
+	PinnedSkills []SkillDef `json:"pinnedSkills,omitempty"`
+}
+
+// SkillDiscoveryStatus describes the outcome for a single discovered location.
+type SkillDiscoveryStatus string
+
+const (
+	// SkillDiscoveryStatusAdded means the skill was indexed and registered.
+	SkillDiscoveryStatusAdded SkillDiscoveryStatus = "added"
+
+	// SkillDiscoveryStatusSkipped means the location was not registered (hidden, ignored, already registered).
+	SkillDiscoveryStatusSkipped SkillDiscoveryStatus = "skipped"
+
+	// SkillDiscoveryStatusFailed means indexing failed (e.g. SKILL.md parse error or name mismatch).
+	SkillDiscoveryStatusFailed SkillDiscoveryStatus = "failed"
+)
+
+// SkillDiscoveryResult is the per-location report returned by Runtime.DiscoverSkills.
+type SkillDiscoveryResult struct {
+	Def    SkillDef             `json:"def"`
+	Status SkillDiscoveryStatus `json:"status"`
+
+	// Record is set for added skills.
+	Record SkillRecord `json:"record"`
+
+	// Reason explains skipped results.
+	Reason string `json:"reason,omitempty"`
+
+	// Error is set for failed results.
+	Error string `json:"error,omitempty"`
 }